@@ -0,0 +1,355 @@
+package attachment
+
+import (
+	"bufio"
+	"fmt"
+	userApp "go-api/application/entities/user"
+	"go-api/config"
+	"go-api/database"
+	domain "go-api/domain/entities/attachment"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	repository "go-api/infrastructure/persistance/attachment"
+	classRepository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	"go-api/infrastructure/storage"
+	"go-api/oops"
+	"go-api/utils"
+	"io"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// backend is where the content of the attachments is stored
+	backend storage.Storage = storage.NewLocal("uploads")
+	// secret signs the download URLs
+	secret string
+	// maxSize is the largest file accepted, in bytes
+	maxSize int64 = defaultMaxSize
+)
+
+// Configure sets the storage backend and the upload limits. Without a
+// signing secret a random one is used, so URLs don't survive restarts
+func Configure(s storage.Storage, cfg config.StorageConfig) error {
+	backend, secret, maxSize = s, cfg.SigningSecret, defaultMaxSize
+
+	if cfg.MaxSize > 0 {
+		maxSize = cfg.MaxSize
+	}
+
+	if secret == "" {
+		token, err := utils.RandomToken(32)
+		if err != nil {
+			return err
+		}
+		log.Println("No signing secret for attachment URLs configured, using a random one")
+		secret = token
+	}
+
+	return nil
+}
+
+// MaxBodySize is the largest upload request accepted
+func MaxBodySize() int64 {
+	return maxSize + multipartOverhead
+}
+
+// TooLarge is the error of uploads above the size limit
+func TooLarge() error {
+	return oops.NewErr(fmt.Sprintf("Arquivo maior que o limite de %.1f MB", float64(maxSize)/(1<<20)))
+}
+
+// Upload do the business logic of attaching a file to a class or to one of its
+// schedules by its teacher, proven by their secret token. The file is stored
+// before the record, and removed if the insertion fails
+func Upload(classID uint, token string, in *INUpload) (id uint, err error) {
+	var (
+		repo         domain.IAttachment    = &repository.Repository{}
+		scheduleRepo classDomain.ISchedule = &classRepository.ScheduleRepository{}
+		class        *classDomain.Class
+	)
+
+	if in.File.Size > maxSize {
+		return id, TooLarge()
+	}
+
+	name := cleanName(in.File.Filename)
+	if name == "" || name == "." || name == "/" {
+		return id, oops.NewErr("Nome do arquivo inválido")
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if class, err = getClass(classID, tx); err != nil {
+		return id, err
+	}
+
+	if *class.TeacherID != *in.UploaderID {
+		return id, oops.NewErr("Apenas o professor da turma pode enviar materiais")
+	}
+
+	if err = userApp.Authenticate(*in.UploaderID, token, tx); err != nil {
+		return id, err
+	}
+
+	if in.ScheduleID != nil {
+		if err = scheduleRepo.Get(&classDomain.Schedule{ID: in.ScheduleID, ClassID: &classID}, tx); err != nil {
+			return id, oops.Wrap(err, "Error when fetching schedule.")
+		}
+	}
+
+	file, err := in.File.Open()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when reading uploaded file.")
+	}
+
+	defer file.Close()
+
+	content := bufio.NewReaderSize(file, sniffSize)
+
+	contentType, ok := detect(name, content)
+	if !ok {
+		return id, oops.NewErr("Tipo de arquivo não permitido, envie PDF, imagens PNG ou JPEG, texto ou documentos do Office")
+	}
+
+	suffix, err := utils.RandomToken(16)
+	if err != nil {
+		return id, oops.Wrap(err, "Error when generating attachment key.")
+	}
+
+	var (
+		key         = fmt.Sprintf("classes/%d/%s", classID, suffix)
+		size        = in.File.Size
+		backendName = backend.Name()
+	)
+
+	if err = backend.Save(key, io.LimitReader(content, maxSize)); err != nil {
+		return id, oops.Wrap(err, "Error when storing file.")
+	}
+
+	data := &domain.Attachment{
+		ClassID:     &classID,
+		ScheduleID:  in.ScheduleID,
+		UploaderID:  in.UploaderID,
+		Name:        &name,
+		ContentType: &contentType,
+		Size:        &size,
+		Backend:     &backendName,
+		Key:         &key,
+	}
+
+	defer func() {
+		if err != nil {
+			discard(key)
+		}
+	}()
+
+	if err = repo.Add(data, tx); err != nil {
+		return id, oops.Wrap(err, "Error when inserting attachment.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when commiting transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// GetAll lists the attachments of a class to its teacher or enrolled students
+func GetAll(classID uint, token string, in *INList) (out *OUTList, err error) {
+	var repo domain.IAttachment = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = checkAccess(classID, *in.UserID, token, db); err != nil {
+		return nil, err
+	}
+
+	data := []domain.Attachment{}
+
+	if err = repo.GetAll(&data, &domain.Filter{ClassID: &classID, ScheduleID: in.ScheduleID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing attachments.")
+	}
+
+	out = &OUTList{Data: make([]OUTAttachment, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting attachment.")
+		}
+	}
+
+	return out, nil
+}
+
+// GetURL issues a time-limited signed download URL of an attachment
+// to the teacher or an enrolled student of its class
+func GetURL(id uint, token string, in *INAccess) (out *OUTURL, err error) {
+	db := database.GetDBSession()
+
+	data, err := get(id, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkAccess(*data.ClassID, *in.UserID, token, db); err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().Add(urlTTL).Truncate(time.Second)
+
+	out = &OUTURL{
+		URL: fmt.Sprintf("/v1/attachments/%d/content?expires=%d&signature=%s",
+			id, expires.Unix(), sign(id, expires.Unix())),
+		ExpiresAt: expires,
+	}
+
+	return out, nil
+}
+
+// Open checks a signed download URL and opens the content of the attachment.
+// The caller must close the returned reader
+func Open(id uint, in *INContent) (out *OUTAttachment, content io.ReadCloser, err error) {
+	if !utils.SameToken(sign(id, *in.Expires), *in.Signature) {
+		return nil, nil, oops.NewErr("Link de download inválido")
+	}
+
+	if time.Now().Unix() > *in.Expires {
+		return nil, nil, oops.NewErr("Link de download expirado")
+	}
+
+	data, err := get(id, database.GetDBSession())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *data.Backend != backend.Name() {
+		return nil, nil, oops.Wrap(fmt.Errorf("attachment %d is stored on %s", id, *data.Backend), "Error when opening attachment.")
+	}
+
+	if content, err = backend.Open(*data.Key); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when opening attachment.")
+	}
+
+	out = &OUTAttachment{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		content.Close()
+		return nil, nil, oops.Wrap(err, "Error when converting attachment.")
+	}
+
+	return out, content, nil
+}
+
+// Delete removes an attachment of a class by request of its teacher
+func Delete(id uint, token string, in *INAccess) (err error) {
+	var repo domain.IAttachment = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data, err := get(id, tx)
+	if err != nil {
+		return err
+	}
+
+	class, err := getClass(*data.ClassID, tx)
+	if err != nil {
+		return err
+	}
+
+	if *class.TeacherID != *in.UserID {
+		return oops.NewErr("Apenas o professor da turma pode remover materiais")
+	}
+
+	if err = userApp.Authenticate(*in.UserID, token, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when deleting attachment.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when commiting transaction.")
+	}
+
+	discard(*data.Key)
+
+	return nil
+}
+
+func get(id uint, db *gorm.DB) (*domain.Attachment, error) {
+	var repo domain.IAttachment = &repository.Repository{}
+
+	data := &domain.Attachment{ID: &id}
+
+	if err := repo.Get(data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching attachment.")
+	}
+
+	return data, nil
+}
+
+func getClass(id uint, db *gorm.DB) (*classDomain.Class, error) {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	class := &classDomain.Class{ID: &id}
+
+	if err := classRepo.Get(class, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return class, nil
+}
+
+// checkAccess ensures the token belongs to the user
+// and that the user teaches the class or holds a seat in it
+func checkAccess(classID, userID uint, token string, db *gorm.DB) error {
+	var enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+
+	class, err := getClass(classID, db)
+	if err != nil {
+		return err
+	}
+
+	if err = userApp.Authenticate(userID, token, db); err != nil {
+		return err
+	}
+
+	if *class.TeacherID == userID {
+		return nil
+	}
+
+	enrollment := &enrollmentDomain.Enrollment{ClassID: &classID, StudentID: &userID}
+
+	if err = enrollmentRepo.GetActive(enrollment, db); err != nil && !oops.IsNotFound(err) {
+		return oops.Wrap(err, "Error when fetching enrollment.")
+	}
+
+	if err != nil || *enrollment.Status != enrollmentDomain.StatusEnrolled {
+		return oops.NewErr("Apenas o professor e os alunos inscritos podem acessar os materiais da turma")
+	}
+
+	return nil
+}
+
+// discard removes a stored file no record points to anymore. Failures
+// are only logged, leaving an orphan file rather than failing the request
+func discard(key string) {
+	if err := backend.Delete(key); err != nil {
+		log.Println("Error when removing stored file", key+":", err)
+	}
+}
@@ -0,0 +1,98 @@
+package attachment
+
+import (
+	"bufio"
+	"fmt"
+	"go-api/utils"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxSize is the largest file accepted when the configuration doesn't say
+	defaultMaxSize = 10 << 20
+	// multipartOverhead is the room left in the request body to the form around the file
+	multipartOverhead = 1 << 20
+	// sniffSize is how many bytes are read to detect the type of a file
+	sniffSize = 512
+	// urlTTL is how long a signed download URL stays valid
+	urlTTL = 15 * time.Minute
+	// maxNameLength is the longest file name kept
+	maxNameLength = 255
+)
+
+// mimeType describes a file type accepted on uploads. Sniffed lists the
+// types the content may be detected as, since office documents look like zip files
+type mimeType struct {
+	ContentType string
+	Sniffed     []string
+}
+
+// allowed maps the accepted file extensions to their types
+var allowed = map[string]mimeType{
+	".pdf":  {"application/pdf", []string{"application/pdf"}},
+	".png":  {"image/png", []string{"image/png"}},
+	".jpg":  {"image/jpeg", []string{"image/jpeg"}},
+	".jpeg": {"image/jpeg", []string{"image/jpeg"}},
+	".txt":  {"text/plain; charset=utf-8", []string{"text/plain"}},
+	".docx": {"application/vnd.openxmlformats-officedocument.wordprocessingml.document", []string{"application/zip"}},
+	".pptx": {"application/vnd.openxmlformats-officedocument.presentationml.presentation", []string{"application/zip"}},
+	".xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", []string{"application/zip"}},
+}
+
+// detect finds the type of a file from its extension, rejecting
+// the content that doesn't look like what the extension claims
+func detect(name string, content *bufio.Reader) (string, bool) {
+	kind, ok := allowed[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return "", false
+	}
+
+	head, err := content.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return "", false
+	}
+
+	sniffed := http.DetectContentType(head)
+	if i := strings.Index(sniffed, ";"); i >= 0 {
+		sniffed = sniffed[:i]
+	}
+
+	for _, s := range kind.Sniffed {
+		if s == sniffed {
+			return kind.ContentType, true
+		}
+	}
+
+	return "", false
+}
+
+// cleanName keeps only the base name of an uploaded file, bounded in length
+func cleanName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, "\\", "/")))
+
+	if len(name) <= maxNameLength {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	if len(ext) > maxNameLength {
+		ext = ""
+	}
+
+	name = name[:maxNameLength-len(ext)]
+	for !utf8.ValidString(name) {
+		name = name[:len(name)-1]
+	}
+
+	return strings.TrimRight(name, " ") + ext
+}
+
+// sign computes the signature of a download URL of an attachment
+func sign(id uint, expires int64) string {
+	return utils.SignPayload(secret, []byte(fmt.Sprintf("attachment:%d:%d", id, expires)))
+}
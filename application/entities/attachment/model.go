@@ -0,0 +1,54 @@
+package attachment
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+// INUpload models the multipart form uploading an attachment. The file
+// is shared with the whole class unless ScheduleID is given
+type INUpload struct {
+	File       *multipart.FileHeader `form:"file" binding:"required"`
+	UploaderID *uint                 `form:"uploader_id" binding:"required"`
+	ScheduleID *uint                 `form:"schedule_id"`
+}
+
+// INList models the query string listing the attachments of a class
+type INList struct {
+	UserID     *uint `form:"user_id" binding:"required"`
+	ScheduleID *uint `form:"schedule_id"`
+}
+
+// INAccess models the query string identifying who accesses an attachment
+type INAccess struct {
+	UserID *uint `form:"user_id" binding:"required"`
+}
+
+// INContent models the query string of a signed download URL
+type INContent struct {
+	Expires   *int64  `form:"expires" binding:"required"`
+	Signature *string `form:"signature" binding:"required"`
+}
+
+// OUTAttachment models an attachment for retrieval
+type OUTAttachment struct {
+	ID          *uint      `json:"id,omitempty" conversor:"id"`
+	ClassID     *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	ScheduleID  *uint      `json:"schedule_id,omitempty" conversor:"schedule_id"`
+	UploaderID  *uint      `json:"uploader_id,omitempty" conversor:"uploader_id"`
+	Name        *string    `json:"name,omitempty" conversor:"name"`
+	ContentType *string    `json:"content_type,omitempty" conversor:"content_type"`
+	Size        *int64     `json:"size,omitempty" conversor:"size"`
+	CreatedAt   *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTList models a list of attachments
+type OUTList struct {
+	Data []OUTAttachment
+}
+
+// OUTURL models a signed download URL, valid until ExpiresAt
+type OUTURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,435 @@
+package attendance
+
+import (
+	userApp "go-api/application/entities/user"
+	"go-api/database"
+	domain "go-api/domain/entities/attendance"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	userDomain "go-api/domain/entities/user"
+	repository "go-api/infrastructure/persistance/attendance"
+	classRepository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// codeSize is the number of digits of a check in code
+	codeSize = 6
+	// checkInOpensBefore is how long before the start of a
+	// schedule students are allowed to check in
+	checkInOpensBefore = 15 * time.Minute
+	// lateTolerance is how long after the start of a schedule
+	// a check in is still considered on time
+	lateTolerance = 10 * time.Minute
+	// maxCheckInAttempts is how many wrong codes a student may send to
+	// a schedule before needing the teacher to generate a new code
+	maxCheckInAttempts = 5
+)
+
+// Mark do the business logic of the teacher marking the attendance of
+// students in a schedule. Students already marked have their status replaced
+func Mark(classID, scheduleID uint, token string, in *INMarks) (out *OUTList, err error) {
+	var repo domain.IAttendance = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = checkTeacher(classID, token, tx); err != nil {
+		return nil, err
+	}
+
+	schedule, err := getSchedule(classID, scheduleID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	marked := map[uint]bool{}
+
+	for _, item := range in.Data {
+		if marked[*item.StudentID] {
+			return nil, oops.NewErr("Aluno informado mais de uma vez")
+		}
+		marked[*item.StudentID] = true
+
+		// students may be excused in advance, any other status needs the session to have started
+		if *item.Status != domain.StatusExcused && schedule.Start.After(time.Now()) {
+			return nil, oops.NewErr("Presença só pode ser registrada após o início da aula")
+		}
+
+		if err = checkEnrolled(classID, *item.StudentID, tx); err != nil {
+			return nil, err
+		}
+
+		data := &domain.Attendance{
+			ScheduleID: &scheduleID,
+			StudentID:  item.StudentID,
+			Status:     item.Status,
+			Note:       item.Note,
+		}
+
+		if err = repo.Save(data, tx); err != nil {
+			return nil, oops.Wrap(err, "Error when saving attendance.")
+		}
+	}
+
+	if out, err = list(scheduleID, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return out, nil
+}
+
+// GetBySchedule do the business logic of listing the attendances of a schedule to the teacher
+func GetBySchedule(classID, scheduleID uint, token string) (out *OUTList, err error) {
+	db := database.GetDBSession()
+
+	if err = checkTeacher(classID, token, db); err != nil {
+		return nil, err
+	}
+
+	if _, err = getSchedule(classID, scheduleID, db); err != nil {
+		return nil, err
+	}
+
+	return list(scheduleID, db)
+}
+
+// NewCheckInCode do the business logic of generating the code students
+// use to check in to a schedule, replacing the previous one. Only the teacher
+// sees the code, as it also resets the check in attempts of the schedule
+func NewCheckInCode(classID uint, token string, in *INCheckInCode) (out *OUTCheckInCode, err error) {
+	var repo domain.IAttendance = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = checkTeacher(classID, token, tx); err != nil {
+		return nil, err
+	}
+
+	scheduleID := *in.ScheduleID
+
+	schedule, err := getSchedule(classID, scheduleID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !schedule.End.After(time.Now()) {
+		return nil, oops.NewErr("Aula já foi encerrada")
+	}
+
+	code, err := utils.RandomDigits(codeSize)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when generating check in code.")
+	}
+
+	data := &domain.CheckInCode{ScheduleID: &scheduleID, Code: &code}
+
+	if err = repo.SaveCode(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when saving check in code.")
+	}
+
+	if err = repo.ResetAttempts(scheduleID, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when resetting check in attempts.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	out = &OUTCheckInCode{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	opens := schedule.Start.Add(-checkInOpensBefore)
+	out.OpensAt, out.ClosesAt = &opens, schedule.End
+
+	return out, nil
+}
+
+// CheckIn do the business logic of a student checking in to a schedule with
+// its code, proving who they are with their secret token. Check ins are accepted
+// from a little before the start until the end of the session, and after the
+// late tolerance the student is marked late
+func CheckIn(classID uint, token string, in *INCheckIn) (out *OUTAttendance, err error) {
+	var repo domain.IAttendance = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = userApp.Authenticate(*in.StudentID, token, tx); err != nil {
+		return nil, err
+	}
+
+	scheduleID := *in.ScheduleID
+
+	schedule, err := getSchedule(classID, scheduleID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if now.Before(schedule.Start.Add(-checkInOpensBefore)) || !now.Before(*schedule.End) {
+		return nil, oops.NewErr("Check-in fora do horário permitido")
+	}
+
+	if err = checkEnrolled(classID, *in.StudentID, tx); err != nil {
+		return nil, err
+	}
+
+	attempts := &domain.CheckInAttempt{ScheduleID: &scheduleID, StudentID: in.StudentID}
+
+	if err = repo.LockAttempts(attempts, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching check in attempts.")
+	}
+
+	if *attempts.Failures >= maxCheckInAttempts {
+		return nil, oops.NewErr("Limite de tentativas de check-in atingido, solicite um novo código ao professor")
+	}
+
+	code := &domain.CheckInCode{ScheduleID: &scheduleID}
+
+	if err = repo.GetCode(code, tx); err != nil && !oops.IsNotFound(err) {
+		return nil, oops.Wrap(err, "Error when fetching check in code.")
+	}
+
+	if err != nil || !utils.SameToken(*code.Code, *in.Code) {
+		// the failure is committed on its own, as the check in is refused
+		if err = repo.AddFailure(attempts, tx); err != nil {
+			return nil, oops.Wrap(err, "Error when saving check in attempt.")
+		}
+
+		if err = tx.Commit().Error; err != nil {
+			return nil, oops.Wrap(err, "Error when committing transaction.")
+		}
+
+		return nil, oops.NewErr("Código de check-in inválido")
+	}
+
+	current := &domain.Attendance{ScheduleID: &scheduleID, StudentID: in.StudentID}
+
+	if err = repo.Get(current, tx); err == nil {
+		if *current.Status == domain.StatusPresent || *current.Status == domain.StatusLate {
+			return nil, oops.NewConflict("Presença já registrada nesta aula", nil)
+		}
+	} else if !oops.IsNotFound(err) {
+		return nil, oops.Wrap(err, "Error when fetching attendance.")
+	}
+
+	status := domain.StatusPresent
+	if now.After(schedule.Start.Add(lateTolerance)) {
+		status = domain.StatusLate
+	}
+
+	data := &domain.Attendance{
+		ScheduleID:  &scheduleID,
+		StudentID:   in.StudentID,
+		Status:      &status,
+		Note:        current.Note,
+		CheckedInAt: &now,
+	}
+
+	if err = repo.Save(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when saving attendance.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	out = &OUTAttendance{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetClassReport do the business logic of computing the attendance
+// rate of each student of a class
+func GetClassReport(classID uint) (out *OUTReport, err error) {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = classRepo.Get(&classDomain.Class{ID: &classID}, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return report(&domain.Filter{ClassID: &classID, Until: time.Now()}, db)
+}
+
+// GetStudentReport do the business logic of computing the attendance
+// rate of a student in each class attended
+func GetStudentReport(studentID uint) (out *OUTReport, err error) {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &studentID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	return report(&domain.Filter{StudentID: &studentID, Until: time.Now()}, db)
+}
+
+func getSchedule(classID, scheduleID uint, db *gorm.DB) (*classDomain.Schedule, error) {
+	var scheduleRepo classDomain.ISchedule = &classRepository.ScheduleRepository{}
+
+	schedule := &classDomain.Schedule{ID: &scheduleID, ClassID: &classID}
+
+	if err := scheduleRepo.Get(schedule, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	return schedule, nil
+}
+
+// checkTeacher ensures the token is the secret one of the teacher of the class
+func checkTeacher(classID uint, token string, db *gorm.DB) error {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err := classRepo.Get(class, nil, db); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return userApp.Authenticate(*class.TeacherID, token, db)
+}
+
+// checkEnrolled ensures the student holds a seat in the class
+func checkEnrolled(classID, studentID uint, db *gorm.DB) error {
+	var enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+
+	enrollment := &enrollmentDomain.Enrollment{ClassID: &classID, StudentID: &studentID}
+
+	if err := enrollmentRepo.GetActive(enrollment, db); err != nil {
+		if oops.IsNotFound(err) {
+			return oops.NewErr("Aluno não está inscrito nesta turma")
+		}
+		return oops.Wrap(err, "Error when fetching enrollment.")
+	}
+
+	if *enrollment.Status != enrollmentDomain.StatusEnrolled {
+		return oops.NewErr("Aluno ainda está na lista de espera desta turma")
+	}
+
+	return nil
+}
+
+func list(scheduleID uint, db *gorm.DB) (out *OUTList, err error) {
+	var repo domain.IAttendance = &repository.Repository{}
+
+	data := []domain.Attendance{}
+
+	if err = repo.GetAll(&data, scheduleID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing attendances.")
+	}
+
+	out = &OUTList{Data: make([]OUTAttendance, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return out, nil
+}
+
+// report computes the attendance rates of the students currently enrolled
+// and of the ones who left their classes but have attendances recorded
+func report(filter *domain.Filter, db *gorm.DB) (out *OUTReport, err error) {
+	var repo domain.IAttendance = &repository.Repository{}
+
+	sessions := []domain.Sessions{}
+
+	if err = repo.CountSessions(&sessions, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when counting sessions.")
+	}
+
+	summaries := []domain.Summary{}
+
+	if err = repo.Summarize(&summaries, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when summarizing attendances.")
+	}
+
+	type key struct{ class, student uint }
+
+	rates, keys := map[key]*OUTRate{}, []key{}
+
+	get := func(k key) *OUTRate {
+		if _, ok := rates[k]; !ok {
+			rates[k] = &OUTRate{ClassID: k.class, StudentID: k.student}
+			keys = append(keys, k)
+		}
+		return rates[k]
+	}
+
+	for _, s := range sessions {
+		get(key{s.ClassID, s.StudentID}).Sessions = s.Total
+	}
+
+	for _, s := range summaries {
+		rate := get(key{s.ClassID, s.StudentID})
+
+		switch s.Status {
+		case domain.StatusPresent:
+			rate.Present = s.Total
+		case domain.StatusLate:
+			rate.Late = s.Total
+		case domain.StatusAbsent:
+			rate.Absent = s.Total
+		case domain.StatusExcused:
+			rate.Excused = s.Total
+		}
+	}
+
+	out = &OUTReport{Data: make([]OUTRate, len(keys))}
+
+	for i, k := range keys {
+		rate := rates[k]
+
+		// sessions may have been marked before the current enrollment
+		if marked := rate.Present + rate.Late + rate.Absent + rate.Excused; marked > rate.Sessions {
+			rate.Sessions = marked
+		}
+
+		rate.Absent = rate.Sessions - rate.Present - rate.Late - rate.Excused
+
+		if due := rate.Sessions - rate.Excused; due > 0 {
+			rate.Rate = float64(rate.Present+rate.Late) / float64(due)
+		}
+
+		out.Data[i] = *rate
+	}
+
+	return out, nil
+}
@@ -0,0 +1,74 @@
+package attendance
+
+import (
+	"time"
+)
+
+// INMark models the attendance of a student marked by the teacher
+type INMark struct {
+	StudentID *uint   `json:"student_id" binding:"required" conversor:"student_id"`
+	Status    *string `json:"status" binding:"required,oneof=present absent late excused" conversor:"status"`
+	Note      *string `json:"note" conversor:"note"`
+}
+
+// INMarks models the attendances of a schedule marked at once
+type INMarks struct {
+	Data []INMark `json:"data" binding:"required,min=1,dive"`
+}
+
+// INCheckInCode models the request of a new check in code for a schedule
+type INCheckInCode struct {
+	ScheduleID *uint `json:"schedule_id" binding:"required"`
+}
+
+// INCheckIn models a student checking in to a schedule with its code
+type INCheckIn struct {
+	ScheduleID *uint   `json:"schedule_id" binding:"required"`
+	StudentID  *uint   `json:"student_id" binding:"required"`
+	Code       *string `json:"code" binding:"required"`
+}
+
+// OUTAttendance models an attendance for retrieval
+type OUTAttendance struct {
+	ID          *uint      `json:"id,omitempty" conversor:"id"`
+	ScheduleID  *uint      `json:"schedule_id,omitempty" conversor:"schedule_id"`
+	StudentID   *uint      `json:"student_id,omitempty" conversor:"student_id"`
+	Status      *string    `json:"status,omitempty" conversor:"status"`
+	Note        *string    `json:"note,omitempty" conversor:"note"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty" conversor:"checked_in_at"`
+	CreatedAt   *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
+}
+
+// OUTList models a list of attendances
+type OUTList struct {
+	Data []OUTAttendance
+}
+
+// OUTCheckInCode models the code students use to check in to a schedule
+// and the period in which it is accepted
+type OUTCheckInCode struct {
+	ScheduleID *uint      `json:"schedule_id,omitempty" conversor:"schedule_id"`
+	Code       *string    `json:"code,omitempty" conversor:"code"`
+	OpensAt    *time.Time `json:"opens_at,omitempty"`
+	ClosesAt   *time.Time `json:"closes_at,omitempty"`
+}
+
+// OUTRate models the attendance of a student in a class. Sessions are the
+// schedules already started, the ones not marked are counted as absences
+// and the excused ones are left out of the rate
+type OUTRate struct {
+	ClassID   uint    `json:"class_id"`
+	StudentID uint    `json:"student_id"`
+	Sessions  int64   `json:"sessions"`
+	Present   int64   `json:"present"`
+	Late      int64   `json:"late"`
+	Absent    int64   `json:"absent"`
+	Excused   int64   `json:"excused"`
+	Rate      float64 `json:"rate"`
+}
+
+// OUTReport models the attendance rates of a class or of a student
+type OUTReport struct {
+	Data []OUTRate
+}
@@ -0,0 +1,142 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+	domain "go-api/domain/entities/booking"
+	"go-api/utils"
+	"sort"
+	"time"
+)
+
+const (
+	// slotStep is the interval between the starts of the free slots
+	// listed inside each availability window
+	slotStep = 15 * time.Minute
+	// defaultSlotsPeriod is how far ahead free slots are listed when the client doesn't say
+	defaultSlotsPeriod = 7 * 24 * time.Hour
+	// maxSlotsPeriod limits the period free slots are listed for
+	maxSlotsPeriod = 31 * 24 * time.Hour
+)
+
+// period is an interval of time, closed at the start and open at the end
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+// parseMinute converts an hh:mm time into minutes since midnight,
+// accepting 24:00 as the end of the day
+func parseMinute(value string) (int64, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, errors.New("invalid time " + value)
+	}
+
+	return int64(t.Hour()*60 + t.Minute()), nil
+}
+
+func formatMinute(minute int64) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// occurrences returns the periods a weekly window covers between from and to.
+// Days are walked on UTC so daylight saving transitions don't skip any and
+// wall clock times skipped by a transition are read as RFC 5545 does
+func occurrences(w *domain.Window, from, to time.Time) ([]period, error) {
+	loc, err := utils.LoadTimeZone(*w.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []period
+
+	first, last := from.In(loc), to.In(loc)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)
+
+	for ; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if int64(day.Weekday()) != *w.Weekday {
+			continue
+		}
+
+		p := period{
+			start: utils.ShiftWallClock(day.Add(time.Duration(*w.StartMinute)*time.Minute), loc),
+			end:   utils.ShiftWallClock(day.Add(time.Duration(*w.EndMinute)*time.Minute), loc),
+		}
+
+		if p.end.After(from) && p.start.Before(to) {
+			out = append(out, p)
+		}
+	}
+
+	return out, nil
+}
+
+// fits reports whether a period lies inside a single occurrence of any window
+func fits(windows []domain.Window, p period) (bool, error) {
+	for i := range windows {
+		found, err := occurrences(&windows[i], p.start, p.end)
+		if err != nil {
+			return false, err
+		}
+
+		for _, o := range found {
+			if !p.start.Before(o.start) && !p.end.After(o.end) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// collides reports whether a period, widened by the buffer, overlaps any busy period
+func collides(p period, busy []period, buffer time.Duration) bool {
+	for _, b := range busy {
+		if p.start.Add(-buffer).Before(b.end) && b.start.Before(p.end.Add(buffer)) {
+			return true
+		}
+	}
+	return false
+}
+
+// freeSlots lists the periods of the given duration starting every slotStep
+// from the start of each window occurrence between from and to, keeping the
+// buffer from the busy periods, the earliest first
+func freeSlots(windows []domain.Window, busy []period, duration, buffer time.Duration, from, to time.Time) ([]period, error) {
+	var (
+		out  []period
+		seen = map[int64]bool{}
+	)
+
+	for i := range windows {
+		found, err := occurrences(&windows[i], from, to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range found {
+			for start := o.start; !start.Add(duration).After(o.end); start = start.Add(slotStep) {
+				slot := period{start: start, end: start.Add(duration)}
+
+				if start.Before(from) || slot.end.After(to) || seen[start.Unix()] || collides(slot, busy, buffer) {
+					continue
+				}
+
+				seen[start.Unix()] = true
+				out = append(out, slot)
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].start.Before(out[j].start)
+	})
+
+	return out, nil
+}
@@ -0,0 +1,578 @@
+package booking
+
+import (
+	"errors"
+	classApp "go-api/application/entities/class"
+	notificationApp "go-api/application/entities/notification"
+	userApp "go-api/application/entities/user"
+	"go-api/database"
+	domain "go-api/domain/entities/booking"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	notificationDomain "go-api/domain/entities/notification"
+	userDomain "go-api/domain/entities/user"
+	repository "go-api/infrastructure/persistance/booking"
+	classRepository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// exclusionViolation is the SQLSTATE raised when two schedules
+// of the same teacher overlap
+const exclusionViolation = "23P01"
+
+// AddWindow do the business logic of a teacher publishing a weekly
+// availability window, on their preferred time zone when none is given.
+// The teacher proves who they are with their secret token
+func AddWindow(teacherID uint, token string, in *INWindow) (id uint, err error) {
+	var repo domain.IBooking = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	teacher, err := getTeacher(teacherID, tx)
+	if err != nil {
+		return id, err
+	}
+
+	if err = userApp.Authenticate(teacherID, token, tx); err != nil {
+		return id, err
+	}
+
+	start, err := parseMinute(*in.Start)
+	if err != nil {
+		return id, oops.NewErr("Campo start não contém um horário válido")
+	}
+
+	end, err := parseMinute(*in.End)
+	if err != nil {
+		return id, oops.NewErr("Campo end não contém um horário válido")
+	}
+
+	if end <= start {
+		return id, oops.NewErr("Horário final deve ser posterior ao inicial")
+	}
+
+	zone := timeZone(teacher)
+	if in.TimeZone != nil && *in.TimeZone != "" {
+		zone = *in.TimeZone
+	}
+
+	data := &domain.Window{
+		TeacherID:   &teacherID,
+		Weekday:     in.Weekday,
+		StartMinute: &start,
+		EndMinute:   &end,
+		TimeZone:    &zone,
+	}
+
+	if err = repo.AddWindow(data, tx); err != nil {
+		return id, oops.Wrap(err, "Error when adding new availability window.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// DeleteWindow do the business logic of removing an availability window.
+// Lessons already booked on it are kept
+func DeleteWindow(teacherID, id uint, token string) (err error) {
+	var repo domain.IBooking = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = userApp.Authenticate(teacherID, token, tx); err != nil {
+		return err
+	}
+
+	if err = repo.GetWindow(&domain.Window{ID: &id, TeacherID: &teacherID}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching availability window.")
+	}
+
+	if err = repo.DeleteWindow(id, tx); err != nil {
+		return oops.Wrap(err, "Error when deleting availability window.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// GetWindows do the business logic of listing the availability windows of a teacher
+func GetWindows(teacherID uint) (out *OUTWindowList, err error) {
+	var repo domain.IBooking = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	if _, err = getTeacher(teacherID, db); err != nil {
+		return nil, err
+	}
+
+	data := []domain.Window{}
+
+	if err = repo.GetWindows(&data, teacherID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing availability windows.")
+	}
+
+	out = &OUTWindowList{Data: make([]OUTWindow, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		out.Data[i].Start = formatMinute(*data[i].StartMinute)
+		out.Data[i].End = formatMinute(*data[i].EndMinute)
+	}
+
+	return out, nil
+}
+
+// GetSettings do the business logic of fetching the booking settings of
+// a teacher, formatting the price on the given locale
+func GetSettings(teacherID uint, locale string) (out *OUTSettings, err error) {
+	db := database.GetDBSession()
+
+	if _, err = getTeacher(teacherID, db); err != nil {
+		return nil, err
+	}
+
+	data, err := getSettings(teacherID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OUTSettings{Buffer: data.Buffer, Price: classApp.ToOUTMoney(price(data), locale)}, nil
+}
+
+// UpdateSettings do the business logic of the teacher replacing their booking settings
+func UpdateSettings(teacherID uint, token string, in *INSettings) (err error) {
+	var repo domain.IBooking = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if _, err = getTeacher(teacherID, tx); err != nil {
+		return err
+	}
+
+	if err = userApp.Authenticate(teacherID, token, tx); err != nil {
+		return err
+	}
+
+	m, err := classApp.ToMoney(in.Price)
+	if err != nil {
+		return oops.Wrap(err, "Error when converting struct.")
+	}
+
+	data := &domain.Settings{TeacherID: &teacherID, Buffer: in.Buffer, Price: &m.Amount, Currency: &m.Currency}
+
+	if err = repo.SaveSettings(data, tx); err != nil {
+		return oops.Wrap(err, "Error when saving booking settings.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// GetSlots do the business logic of listing the free slots of the given
+// duration a teacher has in their availability windows, keeping the buffer
+// from the other schedules of the teacher. Dates without timezone are read,
+// and the slots shown, on the given time zone or on the teacher's one
+func GetSlots(teacherID uint, in *INSlots) (out *OUTSlotList, err error) {
+	var (
+		repo         domain.IBooking       = &repository.Repository{}
+		scheduleRepo classDomain.ISchedule = &classRepository.ScheduleRepository{}
+	)
+
+	db := database.GetDBSession()
+
+	teacher, err := getTeacher(teacherID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	zone := timeZone(teacher)
+	if in.TimeZone != nil && *in.TimeZone != "" {
+		zone = *in.TimeZone
+	}
+
+	loc, err := utils.LoadTimeZone(zone)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	now := time.Now()
+	from, to := now, now.Add(defaultSlotsPeriod)
+
+	if in.From != nil {
+		t, err := parseDate("from", *in.From, loc)
+		if err != nil {
+			return nil, err
+		}
+		from, to = *t, t.Add(defaultSlotsPeriod)
+	}
+
+	if in.To != nil {
+		t, err := parseDate("to", *in.To, loc)
+		if err != nil {
+			return nil, err
+		}
+		to = *t
+	}
+
+	if !to.After(from) {
+		return nil, oops.NewErr("Data final deve ser posterior à inicial")
+	}
+
+	if to.Sub(from) > maxSlotsPeriod {
+		return nil, oops.NewErr("Período não pode ser maior que 31 dias")
+	}
+
+	// slots that already started can't be booked
+	if from.Before(now) {
+		from = now
+	}
+
+	out = &OUTSlotList{Data: []OUTSlot{}}
+
+	if !to.After(from) {
+		return out, nil
+	}
+
+	windows := []domain.Window{}
+
+	if err = repo.GetWindows(&windows, teacherID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing availability windows.")
+	}
+
+	settings, err := getSettings(teacherID, db)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := time.Duration(*settings.Buffer) * time.Minute
+	endsAfter := from.Add(-buffer)
+	schedules := []classDomain.Schedule{}
+
+	if err = scheduleRepo.Find(&schedules, &classDomain.ScheduleFilter{TeacherID: &teacherID, EndsAfter: &endsAfter}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing schedules.")
+	}
+
+	busy := make([]period, len(schedules))
+	for i, s := range schedules {
+		busy[i] = period{start: *s.Start, end: *s.End}
+	}
+
+	slots, err := freeSlots(windows, busy, time.Duration(*in.Duration)*time.Minute, buffer, from, to)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when computing free slots.")
+	}
+
+	for _, s := range slots {
+		out.Data = append(out.Data, OUTSlot{Start: s.start.In(loc), End: s.end.In(loc)})
+	}
+
+	return out, nil
+}
+
+// Book do the business logic of a student booking a private lesson with a
+// teacher. The lesson becomes a class of a single seat, taken by the student,
+// with one schedule. Bookings of the same teacher are serialized by locking
+// the teacher, so the buffer between lessons is kept under concurrent requests,
+// while the schedules exclusion constraint guards the overlaps with any class
+func Book(teacherID uint, in *INBooking) (out *OUTBooking, err error) {
+	var (
+		userRepo       userDomain.IUser             = &userRepository.Repository{}
+		classRepo      classDomain.IClass           = &classRepository.Repository{}
+		scheduleRepo   classDomain.ISchedule        = &classRepository.ScheduleRepository{}
+		enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+		repo           domain.IBooking              = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	teacher := &userDomain.User{ID: &teacherID}
+
+	if err = userRepo.Lock(teacher, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching teacher.")
+	}
+
+	if *in.StudentID == teacherID {
+		return nil, oops.NewErr("Professor não pode agendar aula consigo mesmo")
+	}
+
+	student := &userDomain.User{ID: in.StudentID}
+
+	if err = userRepo.Get(student, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching student.")
+	}
+
+	zone := timeZone(teacher)
+
+	loc, err := utils.LoadTimeZone(zone)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	start, err := parseDate("start", *in.Start, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !start.After(time.Now()) {
+		return nil, oops.NewErr("Horário não pode estar no passado")
+	}
+
+	end := start.Add(time.Duration(*in.Duration) * time.Minute)
+
+	windows := []domain.Window{}
+
+	if err = repo.GetWindows(&windows, teacherID, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when listing availability windows.")
+	}
+
+	ok, err := fits(windows, period{start: *start, end: end})
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when checking availability.")
+	}
+
+	if !ok {
+		return nil, oops.NewErr("Horário fora da disponibilidade do professor")
+	}
+
+	settings, err := getSettings(teacherID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := time.Duration(*settings.Buffer) * time.Minute
+	from, to := start.Add(-buffer), end.Add(buffer)
+	conflicts := []classDomain.Schedule{}
+
+	if err = scheduleRepo.Conflicts(&classDomain.Schedule{TeacherID: &teacherID, Start: &from, End: &to}, &conflicts, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when checking schedule conflicts.")
+	}
+
+	if len(conflicts) > 0 {
+		return nil, unavailableError()
+	}
+
+	// the single seat is taken right away, so the lesson is born full
+	name := "Aula particular com " + *teacher.Name
+	capacity, classStatus := int64(1), classDomain.StatusFull
+
+	class := &classDomain.Class{
+		Name:      &name,
+		Price:     settings.Price,
+		Currency:  settings.Currency,
+		Capacity:  &capacity,
+		TimeZone:  &zone,
+		Status:    &classStatus,
+		TeacherID: &teacherID,
+	}
+
+	if err = classRepo.Add(class, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when adding new class.")
+	}
+
+	// classes start as drafts, the lesson skips straight to full on the booking
+	draft := classDomain.StatusDraft
+	event := &classDomain.StatusEvent{ClassID: class.ID, From: &draft, To: &classStatus, ActorID: in.StudentID}
+
+	if err = classRepo.AddStatusEvent(event, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when adding status event.")
+	}
+
+	now := time.Now()
+	status := enrollmentDomain.StatusEnrolled
+
+	enrollment := &enrollmentDomain.Enrollment{
+		ClassID:    class.ID,
+		StudentID:  in.StudentID,
+		Status:     &status,
+		EnrolledAt: &now,
+	}
+
+	if err = enrollmentRepo.Add(enrollment, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when adding new enrollment.")
+	}
+
+	schedule := &classDomain.Schedule{Start: start, End: &end, ClassID: class.ID, TeacherID: &teacherID}
+
+	if err = scheduleRepo.Add(schedule, tx); err != nil {
+		if oops.IsPgCode(err, exclusionViolation) {
+			return nil, unavailableError()
+		}
+		return nil, oops.Wrap(err, "Error when adding new schedule.")
+	}
+
+	data := &domain.Booking{
+		TeacherID:  &teacherID,
+		StudentID:  in.StudentID,
+		ClassID:    class.ID,
+		ScheduleID: schedule.ID,
+		Start:      start,
+		End:        &end,
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when adding new booking.")
+	}
+
+	kind := notificationDomain.KindBooked
+	title := "Nova aula particular"
+	body := *student.Name + " agendou uma aula particular para " + start.Format("02/01/2006 às 15:04")
+
+	if err = notificationApp.Send(&notificationDomain.Notification{
+		UserID:  &teacherID,
+		Kind:    &kind,
+		Title:   &title,
+		Body:    &body,
+		ClassID: class.ID,
+	}, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	out = &OUTBooking{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetBookings do the business logic of listing the private lessons of an
+// user, the ones taught by them or, for the student role, booked by them
+func GetBookings(userID uint, in *INBookings) (out *OUTList, err error) {
+	var (
+		userRepo userDomain.IUser = &userRepository.Repository{}
+		repo     domain.IBooking  = &repository.Repository{}
+	)
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &userID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	filter := &domain.Filter{TeacherID: &userID}
+	if in.Role == "student" {
+		filter = &domain.Filter{StudentID: &userID}
+	}
+
+	data := []domain.Booking{}
+
+	if err = repo.GetAll(&data, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing bookings.")
+	}
+
+	out = &OUTList{Data: make([]OUTBooking, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return out, nil
+}
+
+func getTeacher(teacherID uint, db *gorm.DB) (*userDomain.User, error) {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	teacher := &userDomain.User{ID: &teacherID}
+
+	if err := userRepo.Get(teacher, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching teacher.")
+	}
+
+	return teacher, nil
+}
+
+// getSettings fetches the booking settings of a teacher, no buffer and
+// free lessons when never set
+func getSettings(teacherID uint, db *gorm.DB) (*domain.Settings, error) {
+	var repo domain.IBooking = &repository.Repository{}
+
+	data := &domain.Settings{TeacherID: &teacherID}
+
+	if err := repo.GetSettings(data, db); err != nil {
+		if !oops.IsNotFound(err) {
+			return nil, oops.Wrap(err, "Error when fetching booking settings.")
+		}
+
+		var zero int64
+		currency := utils.DefaultCurrency
+		data.Buffer, data.Price, data.Currency = &zero, &zero, &currency
+	}
+
+	return data, nil
+}
+
+func price(data *domain.Settings) utils.Money {
+	return utils.Money{Amount: *data.Price, Currency: *data.Currency}
+}
+
+// timeZone returns the time zone preferred by the teacher or the default one
+func timeZone(teacher *userDomain.User) string {
+	if teacher.TimeZone != nil && *teacher.TimeZone != "" {
+		return *teacher.TimeZone
+	}
+	return utils.DefaultTimeZone
+}
+
+// parseDate converts an input date of the given field, reading it on the
+// location when it has no timezone
+func parseDate(field, value string, loc *time.Location) (*time.Time, error) {
+	t, err := utils.ParseDateTimeIn(value, loc)
+	if err != nil {
+		var nonexistent *utils.NonexistentTimeError
+		if errors.As(err, &nonexistent) {
+			return nil, oops.Err(err)
+		}
+		return nil, oops.NewErr("Campo " + field + " não contém uma data válida")
+	}
+
+	return t, nil
+}
+
+func unavailableError() error {
+	return oops.NewConflict("Horário indisponível para agendamento", nil)
+}
@@ -0,0 +1,93 @@
+package booking
+
+import (
+	classApp "go-api/application/entities/class"
+	"time"
+)
+
+// INWindow models an availability window for insertion. Weekday counts from
+// Sunday, Start and End are hh:mm times and TimeZone defaults to the one
+// preferred by the teacher
+type INWindow struct {
+	Weekday  *int64  `json:"weekday" binding:"required,min=0,max=6" conversor:"weekday"`
+	Start    *string `json:"start" binding:"required"`
+	End      *string `json:"end" binding:"required"`
+	TimeZone *string `json:"time_zone" binding:"omitempty,timezone" conversor:"time_zone"`
+}
+
+// OUTWindow models an availability window for retrieval
+type OUTWindow struct {
+	ID       *uint   `json:"id,omitempty" conversor:"id"`
+	Weekday  *int64  `json:"weekday" conversor:"weekday"`
+	Start    string  `json:"start"`
+	End      string  `json:"end"`
+	TimeZone *string `json:"time_zone,omitempty" conversor:"time_zone"`
+}
+
+// OUTWindowList models a list of availability windows
+type OUTWindowList struct {
+	Data []OUTWindow
+}
+
+// INSettings models the booking settings of a teacher. Buffer is in minutes
+type INSettings struct {
+	Buffer *int64            `json:"buffer" binding:"required,gte=0,max=240" conversor:"buffer"`
+	Price  *classApp.INMoney `json:"price" binding:"required"`
+}
+
+// OUTSettings models the booking settings of a teacher for retrieval
+type OUTSettings struct {
+	Buffer *int64             `json:"buffer" conversor:"buffer"`
+	Price  *classApp.OUTMoney `json:"price"`
+}
+
+// INSlots models the query string listing the free slots of a teacher.
+// Duration is in minutes and the period defaults to the next week
+type INSlots struct {
+	Duration *int64  `form:"duration" binding:"required,min=15,max=480"`
+	From     *string `form:"from"`
+	To       *string `form:"to"`
+	TimeZone *string `form:"time_zone" binding:"omitempty,timezone"`
+}
+
+// OUTSlot models a free period of a teacher
+type OUTSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// OUTSlotList models a list of free periods
+type OUTSlotList struct {
+	Data []OUTSlot
+}
+
+// INBooking models a student booking a private lesson. Start is accepted in the
+// same formats of the schedules and Duration is in minutes
+type INBooking struct {
+	StudentID *uint   `json:"student_id" binding:"required"`
+	Start     *string `json:"start" binding:"required"`
+	Duration  *int64  `json:"duration" binding:"required,min=15,max=480"`
+}
+
+// OUTBooking models a booking for retrieval
+type OUTBooking struct {
+	ID         *uint      `json:"id,omitempty" conversor:"id"`
+	TeacherID  *uint      `json:"teacher_id,omitempty" conversor:"teacher_id"`
+	StudentID  *uint      `json:"student_id,omitempty" conversor:"student_id"`
+	ClassID    *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	ScheduleID *uint      `json:"schedule_id,omitempty" conversor:"schedule_id"`
+	Start      *time.Time `json:"start,omitempty" conversor:"start"`
+	End        *time.Time `json:"end,omitempty" conversor:"end"`
+	CreatedAt  *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTList models a list of bookings
+type OUTList struct {
+	Data []OUTBooking
+}
+
+// INBookings models the query string listing the bookings of an user,
+// either the lessons taught by them, the default, or the ones booked by them
+type INBookings struct {
+	Role string `form:"role" binding:"omitempty,oneof=teacher student"`
+}
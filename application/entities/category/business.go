@@ -0,0 +1,350 @@
+package category
+
+import (
+	classApp "go-api/application/entities/class"
+	"go-api/database"
+	domain "go-api/domain/entities/category"
+	repository "go-api/infrastructure/persistance/category"
+	"go-api/oops"
+	"go-api/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTagsLimit is the number of tags listed when the client doesn't say
+	defaultTagsLimit = 50
+)
+
+// accents maps the accented letters to their plain ones on the slugs
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// Add do the business logic of inserting a category into the tree
+func Add(in *INCategory) (id uint, err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data, err := toDomainCategory(in)
+	if err != nil {
+		return id, err
+	}
+
+	if err = checkParent(data.ParentID, tx); err != nil {
+		return id, err
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return id, slugError(err, "Error when adding new category.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// Update do the business logic of renaming or moving a category. A category
+// can't be moved under itself or under one of its descendants
+func Update(id uint, in *INCategory) (err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Get(&domain.Category{ID: &id}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching category.")
+	}
+
+	data, err := toDomainCategory(in)
+	if err != nil {
+		return err
+	}
+
+	data.ID = &id
+
+	if err = checkParent(data.ParentID, tx); err != nil {
+		return err
+	}
+
+	if data.ParentID != nil {
+		if err = repo.LockTree(tx); err != nil {
+			return oops.Wrap(err, "Error when locking category tree.")
+		}
+
+		cycle, err := repo.InSubtree(*data.ParentID, id, tx)
+		if err != nil {
+			return oops.Wrap(err, "Error when checking category tree.")
+		}
+		if cycle {
+			return oops.NewErr("Categoria não pode ficar dentro de si mesma ou de suas subcategorias")
+		}
+	}
+
+	if err = repo.Update(data, tx); err != nil {
+		return slugError(err, "Error when updating category.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Delete do the business logic of removing a category without
+// subcategories nor classes filed under it
+func Delete(id uint) (err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Get(&domain.Category{ID: &id}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching category.")
+	}
+
+	children, err := repo.CountChildren(id, tx)
+	if err != nil {
+		return oops.Wrap(err, "Error when counting subcategories.")
+	}
+
+	if children > 0 {
+		return oops.NewErr("Categoria possui subcategorias")
+	}
+
+	classes, err := repo.CountClasses(id, tx)
+	if err != nil {
+		return oops.Wrap(err, "Error when counting classes.")
+	}
+
+	if classes > 0 {
+		return oops.NewErr("Categoria possui turmas")
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when removing category.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Get do the business logic of fetching a category by its ID, along
+// with how many classes open to students it has
+func Get(id uint) (out *OUTCategory, err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	data := &domain.Category{ID: &id}
+
+	if err = repo.Get(data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching category.")
+	}
+
+	counts := []domain.Count{}
+
+	if err = repo.Counts(&counts, classApp.VisibleStatuses, db); err != nil {
+		return nil, oops.Wrap(err, "Error when counting classes.")
+	}
+
+	out = &OUTCategory{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	for _, count := range counts {
+		if count.CategoryID == id {
+			out.Classes, out.Total = count.Classes, count.Total
+		}
+	}
+
+	return out, nil
+}
+
+// GetTree do the business logic of listing the categories as a tree for the
+// navigation menus, along with how many classes open to students each one has
+func GetTree() (out *OUTList, err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	data := []domain.Category{}
+
+	if err = repo.GetAll(&data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing categories.")
+	}
+
+	counts := []domain.Count{}
+
+	if err = repo.Counts(&counts, classApp.VisibleStatuses, db); err != nil {
+		return nil, oops.Wrap(err, "Error when counting classes.")
+	}
+
+	byID := map[uint]domain.Count{}
+	for _, count := range counts {
+		byID[count.CategoryID] = count
+	}
+
+	// categories come ordered by name, so every level keeps that order
+	children := map[uint][]*OUTCategory{}
+	nodes := make([]OUTCategory, len(data))
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &nodes[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		count := byID[*data[i].ID]
+		nodes[i].Classes, nodes[i].Total = count.Classes, count.Total
+
+		var parent uint
+		if data[i].ParentID != nil {
+			parent = *data[i].ParentID
+		}
+
+		children[parent] = append(children[parent], &nodes[i])
+	}
+
+	var build func(parent uint) []OUTCategory
+	build = func(parent uint) []OUTCategory {
+		var level []OUTCategory
+		for _, node := range children[parent] {
+			node.Children = build(*node.ID)
+			level = append(level, *node)
+		}
+		return level
+	}
+
+	out = &OUTList{Data: build(0)}
+	if out.Data == nil {
+		out.Data = []OUTCategory{}
+	}
+
+	return out, nil
+}
+
+// GetTags do the business logic of listing the most used tags of
+// the classes open to students, for the navigation menus
+func GetTags(in *INTags) (out *OUTTagList, err error) {
+	var repo domain.ICategory = &repository.Repository{}
+
+	limit := defaultTagsLimit
+	if in.Limit != nil {
+		limit = *in.Limit
+	}
+
+	data := []domain.TagCount{}
+
+	if err = repo.Tags(&data, classApp.VisibleStatuses, limit, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when counting tags.")
+	}
+
+	out = &OUTTagList{Data: make([]OUTTag, len(data))}
+
+	for i := range data {
+		out.Data[i] = OUTTag{Name: data[i].Name, Count: data[i].Count}
+	}
+
+	return out, nil
+}
+
+func toDomainCategory(in *INCategory) (*domain.Category, error) {
+	data := &domain.Category{}
+
+	if err := utils.ConvertStruct(in, data); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	name := strings.Join(strings.Fields(*in.Name), " ")
+	if name == "" {
+		return nil, oops.NewErr("Nome da categoria não pode ser vazio")
+	}
+
+	source := name
+	if in.Slug != nil && *in.Slug != "" {
+		source = *in.Slug
+	}
+
+	slug := slugify(source)
+	if slug == "" {
+		return nil, oops.NewErr("Endereço da categoria precisa conter letras ou números")
+	}
+
+	data.Name, data.Slug = &name, &slug
+
+	return data, nil
+}
+
+// checkParent ensures the parent of a category exists
+func checkParent(id *uint, tx *gorm.DB) error {
+	var repo domain.ICategory = &repository.Repository{}
+
+	if id == nil {
+		return nil
+	}
+
+	if err := repo.Get(&domain.Category{ID: id}, tx); err != nil {
+		if oops.IsNotFound(err) {
+			return oops.NewErr("Categoria pai não encontrada")
+		}
+		return oops.Wrap(err, "Error when fetching parent category.")
+	}
+
+	return nil
+}
+
+// slugify turns a text into the lower case letters and digits
+// of an URL, separating the words by dashes
+func slugify(text string) string {
+	var b strings.Builder
+
+	dash := false
+	for _, r := range accents.Replace(strings.ToLower(text)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func slugError(err error, message string) error {
+	if oops.IsPgCode(err, "23505") {
+		return oops.NewConflict("Já existe uma categoria com este endereço", nil)
+	}
+	return oops.Wrap(err, message)
+}
@@ -0,0 +1,46 @@
+package category
+
+import "time"
+
+// INCategory models a category for insertion and update. Slug is generated
+// from Name when empty and a category without ParentID is a root one
+type INCategory struct {
+	Name     *string `json:"name" binding:"required,max=60" conversor:"name"`
+	Slug     *string `json:"slug" binding:"omitempty,max=60"`
+	ParentID *uint   `json:"parent_id" conversor:"parent_id"`
+}
+
+// INTags models the query string listing the most used tags
+type INTags struct {
+	Limit *int `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// OUTCategory models a category for retrieval. Classes counts the classes
+// open to students filed directly under it and Total the ones of its whole subtree
+type OUTCategory struct {
+	ID        *uint         `json:"id,omitempty" conversor:"id"`
+	ParentID  *uint         `json:"parent_id,omitempty" conversor:"parent_id"`
+	Name      *string       `json:"name,omitempty" conversor:"name"`
+	Slug      *string       `json:"slug,omitempty" conversor:"slug"`
+	Classes   int64         `json:"classes"`
+	Total     int64         `json:"total"`
+	CreatedAt *time.Time    `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt *time.Time    `json:"updated_at,omitempty" conversor:"updated_at"`
+	Children  []OUTCategory `json:"children,omitempty"`
+}
+
+// OUTList models the tree of categories, starting by the root ones
+type OUTList struct {
+	Data []OUTCategory
+}
+
+// OUTTag models a tag along with how many classes open to students carry it
+type OUTTag struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// OUTTagList models a list of tags
+type OUTTagList struct {
+	Data []OUTTag
+}
@@ -0,0 +1,252 @@
+package class
+
+import (
+	enrollmentApp "go-api/application/entities/enrollment"
+	"go-api/database"
+	categoryDomain "go-api/domain/entities/category"
+	domain "go-api/domain/entities/class"
+	categoryRepository "go-api/infrastructure/persistance/category"
+	repository "go-api/infrastructure/persistance/class"
+	"go-api/oops"
+	"go-api/utils"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultRadiusKm is the radius of the proximity searches when the client doesn't say
+	defaultRadiusKm = 10
+)
+
+// Add do the business logic of inserting a class into the database.
+// Classes start as drafts, hidden from students until published
+func Add(in *INClass) (id uint, err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data, err := toDomainClass(in)
+	if err != nil {
+		return id, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	status := domain.StatusDraft
+	data.Status = &status
+
+	if err = checkCategory(data.CategoryID, tx); err != nil {
+		return id, err
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return id, oops.Wrap(err, "Error when adding new class.")
+	}
+
+	if err = repo.SetTags(*data.ID, NormalizeTags(in.Tags), tx); err != nil {
+		return id, oops.Wrap(err, "Error when setting class tags.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// Update do the business logic of updating an existing class
+func Update(id uint, in *INClass) (err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	current := &domain.Class{ID: &id}
+
+	if err = repo.Lock(current, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	data, err := toDomainClass(in)
+	if err != nil {
+		return oops.Wrap(err, "Error when converting struct.")
+	}
+
+	data.ID = &id
+
+	if err = checkCategory(data.CategoryID, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Update(data, tx); err != nil {
+		return oops.Wrap(err, "Error when updating class.")
+	}
+
+	if in.ClearLocation {
+		if err = repo.ClearLocation(id, tx); err != nil {
+			return oops.Wrap(err, "Error when clearing class location.")
+		}
+	}
+
+	if in.Tags != nil {
+		if err = repo.SetTags(id, NormalizeTags(in.Tags), tx); err != nil {
+			return oops.Wrap(err, "Error when setting class tags.")
+		}
+	}
+
+	if data.Capacity != nil {
+		current.Capacity = data.Capacity
+	}
+
+	// a raised capacity frees seats for the waitlist
+	if err = enrollmentApp.PromoteWaitlist(current, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Delete do the business logic of removing a class
+func Delete(id uint) (err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Get(&domain.Class{ID: &id}, nil, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when removing class.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Get do the business logic of fetching a class by its ID, formatting
+// its amounts on the given locale and its dates on the given time zone,
+// or on the time zone of the class when empty. Classes not visible to
+// students are only fetched with the token of their teacher
+func Get(id uint, include []string, locale, timeZone, token string) (out *OUTClass, err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	loc, err := parseTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &domain.Class{ID: &id}
+
+	if err = repo.Get(data, &domain.Filter{Include: include}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = checkVisible(data, token, db); err != nil {
+		return nil, err
+	}
+
+	if out, err = toOUTClass(data, locale, loc); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetAll do the business logic of listing classes, optionally restricted
+// to the ones taught by the given teacher. Only the classes visible to
+// students are listed, unless owner tells the teacher is the one asking,
+// who sees every status. Dates are shown on the given time zone, or on the
+// one of each class when empty
+func GetAll(teacherID *uint, owner bool, in *INFilter, include []string, locale, timeZone string) (out *OUTList, err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	loc, err := parseTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []domain.Class{}
+	filter := &domain.Filter{TeacherID: teacherID, Include: include}
+	if teacherID == nil || !owner {
+		filter.Status = VisibleStatuses
+	}
+
+	if in != nil {
+		filter.CategoryID, filter.Tags = in.CategoryID, ParseTags(in.Tags)
+
+		if in.Near != nil {
+			if filter.Near, err = parseNear(*in.Near); err != nil {
+				return nil, err
+			}
+
+			filter.RadiusKm = defaultRadiusKm
+			if in.RadiusKm != nil {
+				filter.RadiusKm = *in.RadiusKm
+			}
+		}
+	}
+
+	if err = repo.GetAll(&data, filter, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when listing classes.")
+	}
+
+	out = &OUTList{Data: make([]OUTClass, len(data))}
+
+	for i := range data {
+		item, err := toOUTClass(&data[i], locale, loc)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		if filter.Near != nil && data[i].Latitude != nil && data[i].Longitude != nil {
+			distance := utils.Distance(filter.Near.Latitude, filter.Near.Longitude, *data[i].Latitude, *data[i].Longitude)
+			distance = math.Round(distance*1000) / 1000
+			item.Distance = &distance
+		}
+
+		out.Data[i] = *item
+	}
+
+	return out, nil
+}
+
+// checkCategory ensures the category a class is filed under exists
+func checkCategory(id *uint, tx *gorm.DB) error {
+	var categoryRepo categoryDomain.ICategory = &categoryRepository.Repository{}
+
+	if id == nil {
+		return nil
+	}
+
+	if err := categoryRepo.Get(&categoryDomain.Category{ID: id}, tx); err != nil {
+		if oops.IsNotFound(err) {
+			return oops.NewErr("Categoria não encontrada")
+		}
+		return oops.Wrap(err, "Error when fetching category.")
+	}
+
+	return nil
+}
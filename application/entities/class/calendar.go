@@ -0,0 +1,92 @@
+package class
+
+import (
+	"fmt"
+	"go-api/database"
+	domain "go-api/domain/entities/class"
+	repository "go-api/infrastructure/persistance/class"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+)
+
+// calendarHistory is how long past schedules remain in the calendar feeds
+const calendarHistory = 90 * 24 * time.Hour
+
+// Calendar do the business logic of rendering the schedules of a class as an
+// iCalendar feed. Classes not visible to students need the token of their teacher
+func Calendar(classID uint, token string) ([]byte, error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	db := database.GetDBSession()
+	class := &domain.Class{ID: &classID}
+
+	if err := repo.Get(class, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err := checkVisible(class, token, db); err != nil {
+		return nil, err
+	}
+
+	return renderCalendar(*class.Name, &domain.ScheduleFilter{ClassID: &classID})
+}
+
+// UserCalendar do the business logic of rendering the schedules of every
+// class taught by an user or holding a seat of the user as an iCalendar feed
+func UserCalendar(userID uint, name string) ([]byte, error) {
+	return renderCalendar(name, &domain.ScheduleFilter{AttendeeID: &userID})
+}
+
+// renderCalendar lists the recent and upcoming schedules, including the
+// canceled ones so calendar applications remove them on refresh
+func renderCalendar(name string, filter *domain.ScheduleFilter) ([]byte, error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	since := time.Now().Add(-calendarHistory)
+	filter.EndsAfter, filter.WithDeleted = &since, true
+
+	data := []domain.Schedule{}
+
+	if err := repo.Find(&data, filter, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when listing schedules.")
+	}
+
+	events := make([]utils.ICalEvent, len(data))
+
+	for i, schedule := range data {
+		events[i] = scheduleEvent(&schedule)
+	}
+
+	return utils.ICalendar(name, events), nil
+}
+
+// scheduleEvent converts a schedule into a calendar event. The UID depends
+// only on the schedule ID so updates replace the previous event, and the
+// sequence is the number of seconds between its creation and last change
+func scheduleEvent(schedule *domain.Schedule) utils.ICalEvent {
+	event := utils.ICalEvent{
+		UID:      fmt.Sprintf("schedule-%d@go-api", *schedule.ID),
+		Start:    *schedule.Start,
+		End:      *schedule.End,
+		Canceled: schedule.DeletedAt != nil && schedule.DeletedAt.Valid,
+	}
+
+	if schedule.Class != nil && schedule.Class.Name != nil {
+		event.Summary = *schedule.Class.Name
+	}
+
+	if schedule.UpdatedAt != nil {
+		event.LastModified = *schedule.UpdatedAt
+	}
+
+	if event.Canceled {
+		event.LastModified = schedule.DeletedAt.Time
+	}
+
+	if schedule.CreatedAt != nil && event.LastModified.After(*schedule.CreatedAt) {
+		event.Sequence = int64(event.LastModified.Sub(*schedule.CreatedAt) / time.Second)
+	}
+
+	return event
+}
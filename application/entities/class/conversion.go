@@ -0,0 +1,253 @@
+package class
+
+import (
+	domain "go-api/domain/entities/class"
+	"go-api/oops"
+	"go-api/utils"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// includes maps the names accepted on the include query parameter
+// to the associations of the class domain model
+var includes = map[string]string{
+	"teacher":   domain.IncludeTeacher,
+	"schedules": domain.IncludeSchedules,
+	"category":  domain.IncludeCategory,
+	"tags":      domain.IncludeTags,
+}
+
+// ParseInclude converts a comma separated list of associations
+// into the names expected by the repository
+func ParseInclude(raw string) (out []string, err error) {
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		assoc, ok := includes[name]
+		if !ok {
+			return nil, oops.NewErr("Associação '" + name + "' não pode ser incluída")
+		}
+
+		out = append(out, assoc)
+	}
+	return out, nil
+}
+
+// NormalizeTags lower cases the tags and collapses their spaces,
+// dropping the empty and repeated ones
+func NormalizeTags(tags []string) []string {
+	out, seen := []string{}, map[string]bool{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+
+	return out
+}
+
+// ParseTags converts a comma separated list of tags received on a query string
+func ParseTags(raw *string) []string {
+	if raw == nil {
+		return nil
+	}
+	return NormalizeTags(strings.Split(*raw, ","))
+}
+
+// parseNear converts the latitude,longitude pair of a proximity search
+func parseNear(raw string) (*domain.Point, error) {
+	invalid := oops.NewErr("Parâmetro near deve estar no formato latitude,longitude")
+
+	parts := strings.Split(raw, ",")
+	if len(parts) != 2 {
+		return nil, invalid
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return nil, invalid
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return nil, invalid
+	}
+
+	return &domain.Point{Latitude: lat, Longitude: lng}, nil
+}
+
+// ToMoney converts an amount received from the client
+func ToMoney(in *INMoney) (utils.Money, error) {
+	return utils.NewMoney(*in.Amount, *in.Currency)
+}
+
+// ToOUTMoney converts an amount for retrieval on the given locale
+func ToOUTMoney(m utils.Money, locale string) *OUTMoney {
+	return &OUTMoney{Amount: m.Amount, Currency: m.Currency, Display: m.Format(locale)}
+}
+
+// Price returns the price of a class as a money value
+func Price(data *domain.Class) utils.Money {
+	m := utils.Money{Currency: utils.DefaultCurrency}
+	if data.Price != nil {
+		m.Amount = *data.Price
+	}
+	if data.Currency != nil {
+		m.Currency = *data.Currency
+	}
+	return m
+}
+
+// RefundPolicy returns the cancellation policy of a class,
+// falling back to the default one for the fields not set
+func RefundPolicy(data *domain.Class) (fullHours, partialPercent int64) {
+	fullHours, partialPercent = domain.DefaultRefundFullHours, domain.DefaultRefundPartialPercent
+	if data.RefundFullHours != nil {
+		fullHours = *data.RefundFullHours
+	}
+	if data.RefundPartialPercent != nil {
+		partialPercent = *data.RefundPartialPercent
+	}
+	return fullHours, partialPercent
+}
+
+// Location returns the time zone the schedules of a class are written in
+func Location(data *domain.Class) (*time.Location, error) {
+	name := ""
+	if data.TimeZone != nil {
+		name = *data.TimeZone
+	}
+	return utils.LoadTimeZone(name)
+}
+
+// parseTimeZone loads the time zone asked by the client to display dates,
+// returning nil when none was asked so each class uses its own
+func parseTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	loc, err := utils.LoadTimeZone(name)
+	if err != nil {
+		return nil, oops.NewErr("Fuso horário '" + name + "' não é um fuso IANA válido")
+	}
+
+	return loc, nil
+}
+
+// inZone returns the same instant as seen in the given location
+func inZone(t *time.Time, loc *time.Location) *time.Time {
+	if t == nil {
+		return nil
+	}
+	local := t.In(loc)
+	return &local
+}
+
+// toOUTSchedule converts a schedule for retrieval with its dates in the given location
+func toOUTSchedule(data *domain.Schedule, loc *time.Location) (out *OUTSchedule, err error) {
+	out = &OUTSchedule{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	out.Start, out.End = inZone(out.Start, loc), inZone(out.End, loc)
+	out.OccurrenceStart = inZone(out.OccurrenceStart, loc)
+
+	return out, nil
+}
+
+// toDomainClass converts a class received from the client
+func toDomainClass(in *INClass) (data *domain.Class, err error) {
+	data = &domain.Class{}
+
+	if err = utils.ConvertStruct(in, data); err != nil {
+		return nil, err
+	}
+
+	m, err := ToMoney(in.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Price, data.Currency = &m.Amount, &m.Currency
+
+	if in.Location != nil {
+		address := strings.TrimSpace(*in.Location.Address)
+		data.Address, data.Latitude, data.Longitude = &address, in.Location.Latitude, in.Location.Longitude
+	}
+
+	return data, nil
+}
+
+// toOUTClass converts a class and its loaded associations for retrieval,
+// formatting amounts on the given locale and dates on the given location,
+// or on the time zone of the class when none is given
+func toOUTClass(data *domain.Class, locale string, loc *time.Location) (out *OUTClass, err error) {
+	if loc == nil {
+		if loc, err = Location(data); err != nil {
+			return nil, err
+		}
+	}
+
+	out = &OUTClass{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	if data.Price != nil {
+		out.Price = ToOUTMoney(Price(data), locale)
+	}
+
+	fullHours, partialPercent := RefundPolicy(data)
+	out.RefundFullHours, out.RefundPartialPercent = &fullHours, &partialPercent
+
+	if data.Teacher != nil {
+		out.Teacher = &OUTTeacher{}
+		if err = utils.ConvertStruct(data.Teacher, out.Teacher); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Latitude != nil && data.Longitude != nil {
+		out.Location = &OUTLocation{Address: data.Address, Latitude: data.Latitude, Longitude: data.Longitude}
+	}
+
+	if data.Category != nil {
+		out.Category = &OUTCategory{}
+		if err = utils.ConvertStruct(data.Category, out.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Tags != nil {
+		out.Tags = make([]string, len(data.Tags))
+		for i := range data.Tags {
+			out.Tags[i] = *data.Tags[i].Name
+		}
+	}
+
+	if data.Schedules != nil {
+		out.Schedules = make([]OUTSchedule, len(data.Schedules))
+		for i := range data.Schedules {
+			schedule, err := toOUTSchedule(&data.Schedules[i], loc)
+			if err != nil {
+				return nil, err
+			}
+			out.Schedules[i] = *schedule
+		}
+	}
+
+	return out, nil
+}
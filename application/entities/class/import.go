@@ -0,0 +1,224 @@
+package class
+
+import (
+	"bytes"
+	"go-api/database"
+	domain "go-api/domain/entities/class"
+	repository "go-api/infrastructure/persistance/class"
+	"go-api/oops"
+	"go-api/utils"
+	"io"
+	"io/ioutil"
+	"sort"
+	"time"
+)
+
+// maxImportSize limits the size of the iCalendar files accepted for import
+const maxImportSize = 1 << 20
+
+// ImportSchedules do the business logic of importing the events of an
+// iCalendar file as schedules of a class. Recurring events are expanded
+// inside the materialization window and past events are ignored, as are
+// the events without a valid period, which are reported back. With
+// preview set nothing is stored, otherwise every schedule is created in a
+// single transaction, or none of them when any conflict is found
+func ImportSchedules(classID uint, file io.Reader, preview bool) (out *OUTImport, err error) {
+	var (
+		classRepo domain.IClass    = &repository.Repository{}
+		repo      domain.ISchedule = &repository.ScheduleRepository{}
+	)
+
+	raw, err := ioutil.ReadAll(io.LimitReader(file, maxImportSize+1))
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when reading file.")
+	}
+
+	if len(raw) > maxImportSize {
+		return nil, oops.NewErr("Arquivo excede o tamanho máximo de 1MB")
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class := &domain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	// floating times of the file are read on the time zone of the class
+	loc, err := Location(class)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	events, err := utils.ParseICalendar(bytes.NewReader(raw), loc)
+	if err != nil {
+		return nil, oops.NewErr("Arquivo iCalendar inválido: " + err.Error())
+	}
+
+	out = &OUTImport{}
+
+	if out.Data, out.Skipped, err = expandEvents(events, time.Now(), time.Now().Add(materializationWindow)); err != nil {
+		return nil, err
+	}
+
+	schedules := make([]domain.Schedule, len(out.Data))
+	conflicting := false
+
+	for i := range out.Data {
+		item := &out.Data[i]
+		schedules[i] = domain.Schedule{Start: item.Start, End: item.End, ClassID: &classID, TeacherID: class.TeacherID}
+
+		found := []domain.Schedule{}
+
+		if err = repo.Conflicts(&schedules[i], &found, tx); err != nil {
+			return nil, oops.Wrap(err, "Error when checking schedule conflicts.")
+		}
+
+		item.Conflicts = make([]OUTSchedule, len(found))
+		for j := range found {
+			if err = utils.ConvertStruct(&found[j], &item.Conflicts[j]); err != nil {
+				return nil, oops.Wrap(err, "Error when converting struct.")
+			}
+		}
+
+		// the imported events may also overlap each other
+		for j := 0; j < i; j++ {
+			if out.Data[j].Start.Before(*item.End) && item.Start.Before(*out.Data[j].End) {
+				item.Conflicts = append(item.Conflicts, OUTSchedule{Start: out.Data[j].Start, End: out.Data[j].End})
+			}
+		}
+
+		conflicting = conflicting || len(item.Conflicts) > 0
+	}
+
+	if preview {
+		return out, nil
+	}
+
+	if conflicting {
+		return nil, oops.NewConflict("Horários importados conflitam com a agenda do professor", out)
+	}
+
+	for i := range schedules {
+		if err = repo.Add(&schedules[i], tx); err != nil {
+			if oops.IsPgCode(err, exclusionViolation) {
+				return nil, conflictError(&schedules[i])
+			}
+			return nil, oops.Wrap(err, "Error when adding new schedule.")
+		}
+		out.Data[i].ID = schedules[i].ID
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return out, nil
+}
+
+// expandEvents converts the events into the schedules ending after from and
+// starting before to. Recurring events are expanded through their RRULE,
+// skipping the EXDATEs and applying the overridden occurrences. Events
+// that don't end after they start, like the timed ones without DTEND nor
+// DURATION, are left out and returned apart
+func expandEvents(events []utils.ICalVEvent, from, to time.Time) (out []OUTImportedSchedule, skipped []OUTSkippedEvent, err error) {
+	var (
+		overrides = map[string]map[int64]utils.ICalVEvent{}
+		masters   = map[string]bool{}
+	)
+
+	for _, event := range events {
+		if event.RecurrenceID == nil {
+			masters[event.UID] = true
+			continue
+		}
+		if overrides[event.UID] == nil {
+			overrides[event.UID] = map[int64]utils.ICalVEvent{}
+		}
+		overrides[event.UID][event.RecurrenceID.Unix()] = event
+	}
+
+	skip := func(uid string, start time.Time) {
+		skipped = append(skipped, OUTSkippedEvent{
+			UID:    uid,
+			Start:  &start,
+			Reason: "Evento sem duração ou com término anterior ao início",
+		})
+	}
+
+	add := func(uid string, start, end time.Time) {
+		if !end.After(start) {
+			if start.After(from) && start.Before(to) {
+				skip(uid, start)
+			}
+			return
+		}
+		if end.After(from) && start.Before(to) {
+			out = append(out, OUTImportedSchedule{UID: uid, Start: &start, End: &end})
+		}
+	}
+
+	for _, event := range events {
+		if event.Canceled {
+			continue
+		}
+
+		// overrides of recurring events present in the file are applied
+		// while expanding them, the orphan ones are single events
+		if event.RecurrenceID != nil && masters[event.UID] {
+			continue
+		}
+
+		if event.RRule == "" || event.RecurrenceID != nil {
+			add(event.UID, event.Start, event.End)
+			continue
+		}
+
+		// a series without duration is reported once instead of per occurrence
+		if !event.End.After(event.Start) {
+			skip(event.UID, event.Start)
+			continue
+		}
+
+		rule, err := parseRRule(event.RRule, event.Start.Location())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		excluded := map[int64]bool{}
+		for _, exdate := range event.ExDates {
+			excluded[exdate.Unix()] = true
+		}
+
+		duration := event.End.Sub(event.Start)
+
+		for _, occurrence := range rule.Between(event.Start, from.Add(-duration), to) {
+			if excluded[occurrence.Unix()] {
+				continue
+			}
+
+			start, end := occurrence, occurrence.Add(duration)
+
+			if override, ok := overrides[event.UID][occurrence.Unix()]; ok {
+				if override.Canceled {
+					continue
+				}
+				start, end = override.Start, override.End
+			}
+
+			add(event.UID, start, end)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.Before(*out[j].Start)
+	})
+
+	return out, skipped, nil
+}
@@ -0,0 +1,294 @@
+package class
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// INClass models a class for insertion and update. TimeZone is the IANA
+// zone local dates of its schedules are read in, America/Fortaleza if empty.
+// Tags replace the current ones when given, an empty list removing them all.
+// Location is given to in-person classes, already geocoded by the client,
+// and a null location turns the class back into an online one on updates
+type INClass struct {
+	Name        *string     `json:"name" binding:"required" conversor:"name"`
+	Description *string     `json:"description" conversor:"description"`
+	Price       *INMoney    `json:"price" binding:"required"`
+	Capacity    *int64      `json:"capacity" binding:"omitempty,gt=0" conversor:"capacity"`
+	TimeZone    *string     `json:"time_zone" binding:"omitempty,timezone" conversor:"time_zone"`
+	TeacherID   *uint       `json:"teacher_id" binding:"required" conversor:"teacher_id"`
+	CategoryID  *uint       `json:"category_id" conversor:"category_id"`
+	Tags        []string    `json:"tags" binding:"omitempty,max=10,dive,max=30"`
+	Location    *INLocation `json:"location"`
+
+	RefundFullHours      *int64 `json:"refund_full_hours" binding:"omitempty,gte=0" conversor:"refund_full_hours"`
+	RefundPartialPercent *int64 `json:"refund_partial_percent" binding:"omitempty,gte=0,max=100" conversor:"refund_partial_percent"`
+
+	// ClearLocation is set when the location is given as null
+	ClearLocation bool `json:"-"`
+}
+
+// UnmarshalJSON decodes a class telling a null location apart from a missing one
+func (in *INClass) UnmarshalJSON(data []byte) error {
+	type plain INClass
+
+	var raw struct {
+		Location json.RawMessage `json:"location"`
+	}
+
+	if err := json.Unmarshal(data, (*plain)(in)); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	in.ClearLocation = string(raw.Location) == "null"
+
+	return nil
+}
+
+// INLocation models the address an in-person class happens at,
+// with its latitude and longitude in degrees
+type INLocation struct {
+	Address   *string  `json:"address" binding:"required,max=300"`
+	Latitude  *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+}
+
+// INMoney models an amount in the minor unit of an ISO 4217
+// currency, e.g. {"amount": 1500, "currency": "BRL"} for R$ 15,00
+type INMoney struct {
+	Amount   *int64  `json:"amount" binding:"required,gte=0"`
+	Currency *string `json:"currency" binding:"required,iso4217"`
+}
+
+// OUTMoney models an amount for retrieval, along with
+// its display string on the locale asked by the client
+type OUTMoney struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Display  string `json:"display"`
+}
+
+// OUTClass models a class for retrieval. Distance is how far
+// the class is, in kilometers, on proximity searches
+type OUTClass struct {
+	ID          *uint         `json:"id,omitempty" conversor:"id"`
+	Name        *string       `json:"name,omitempty" conversor:"name"`
+	Description *string       `json:"description,omitempty" conversor:"description"`
+	Price       *OUTMoney     `json:"price,omitempty"`
+	Capacity    *int64        `json:"capacity,omitempty" conversor:"capacity"`
+	TimeZone    *string       `json:"time_zone,omitempty" conversor:"time_zone"`
+	Status      *string       `json:"status,omitempty" conversor:"status"`
+	TeacherID   *uint         `json:"teacher_id,omitempty" conversor:"teacher_id"`
+	CategoryID  *uint         `json:"category_id,omitempty" conversor:"category_id"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty" conversor:"updated_at"`
+	Teacher     *OUTTeacher   `json:"teacher,omitempty"`
+	Schedules   []OUTSchedule `json:"schedules,omitempty"`
+	Category    *OUTCategory  `json:"category,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Location    *OUTLocation  `json:"location,omitempty"`
+	Distance    *float64      `json:"distance_km,omitempty"`
+
+	RefundFullHours      *int64 `json:"refund_full_hours" conversor:"refund_full_hours"`
+	RefundPartialPercent *int64 `json:"refund_partial_percent" conversor:"refund_partial_percent"`
+
+	RatingAverage *float64 `json:"rating_average" conversor:"rating_average"`
+	RatingCount   *int64   `json:"rating_count" conversor:"rating_count"`
+}
+
+// OUTTeacher models the teacher of a class for retrieval
+type OUTTeacher struct {
+	ID        *uint   `json:"id,omitempty" conversor:"id"`
+	Name      *string `json:"name,omitempty" conversor:"name"`
+	Email     *string `json:"email,omitempty" conversor:"email"`
+	AvatarURL *string `json:"avatar_url,omitempty" conversor:"avatar_url"`
+	Bio       *string `json:"bio,omitempty" conversor:"bio"`
+
+	RatingAverage *float64 `json:"rating_average" conversor:"rating_average"`
+	RatingCount   *int64   `json:"rating_count" conversor:"rating_count"`
+}
+
+// OUTLocation models the address of an in-person class for retrieval
+type OUTLocation struct {
+	Address   *string  `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// OUTCategory models the category of a class for retrieval
+type OUTCategory struct {
+	ID       *uint   `json:"id,omitempty" conversor:"id"`
+	ParentID *uint   `json:"parent_id,omitempty" conversor:"parent_id"`
+	Name     *string `json:"name,omitempty" conversor:"name"`
+	Slug     *string `json:"slug,omitempty" conversor:"slug"`
+}
+
+// INFilter models the query string filtering the class listings. CategoryID
+// also matches its subcategories and Tags is a comma separated list of tags
+// the classes must all carry. Near is a latitude,longitude pair restricting
+// the classes to the ones within RadiusKm of it, 10 km by default
+type INFilter struct {
+	CategoryID *uint    `form:"category_id"`
+	Tags       *string  `form:"tags"`
+	Near       *string  `form:"near"`
+	RadiusKm   *float64 `form:"radius_km" binding:"omitempty,gt=0,max=500"`
+}
+
+// INStatus models an user moving a class to another status
+type INStatus struct {
+	Status  *string `json:"status" binding:"required,oneof=draft published archived"`
+	ActorID *uint   `json:"actor_id" binding:"required"`
+}
+
+// OUTStatusEvent models a status change of a class for retrieval
+type OUTStatusEvent struct {
+	ID        *uint      `json:"id,omitempty" conversor:"id"`
+	From      *string    `json:"from,omitempty" conversor:"from"`
+	To        *string    `json:"to,omitempty" conversor:"to"`
+	ActorID   *uint      `json:"actor_id,omitempty" conversor:"actor_id"`
+	CreatedAt *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTStatusEventList models a list of class status changes
+type OUTStatusEventList struct {
+	Data []OUTStatusEvent
+}
+
+// INSchedule models a class schedule for insertion and update.
+// Dates are accepted both in ISO 8601 and dd/mm/yyyy hh:mm:ss formats
+type INSchedule struct {
+	Start *string `json:"start" binding:"required"`
+	End   *string `json:"end" binding:"required"`
+}
+
+// OUTSchedule models a class schedule for retrieval
+type OUTSchedule struct {
+	ID        *uint      `json:"id,omitempty" conversor:"id"`
+	ClassID   *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	TeacherID *uint      `json:"teacher_id,omitempty" conversor:"teacher_id"`
+	Start     *time.Time `json:"start,omitempty" conversor:"start"`
+	End       *time.Time `json:"end,omitempty" conversor:"end"`
+	Status    *string    `json:"status,omitempty" conversor:"status"`
+	CreatedAt *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
+
+	RecurrenceID    *uint      `json:"recurrence_id,omitempty" conversor:"recurrence_id"`
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty" conversor:"occurrence_start"`
+}
+
+// OUTScheduleList models a list of class schedules
+type OUTScheduleList struct {
+	Data []OUTSchedule
+}
+
+// INCancelSchedule models the cancellation of a schedule by the teacher.
+// Compensation is one of refund, the default, or none
+type INCancelSchedule struct {
+	Reason       *string `json:"reason" binding:"required,max=500"`
+	Compensation string  `json:"compensation" binding:"omitempty,oneof=refund none"`
+}
+
+// INReschedule models a schedule moved to another period by the teacher,
+// with dates accepted in the same formats of INSchedule
+type INReschedule struct {
+	Start  *string `json:"start" binding:"required"`
+	End    *string `json:"end" binding:"required"`
+	Reason *string `json:"reason" binding:"required,max=500"`
+}
+
+// OUTScheduleEvent models a status change of a schedule for retrieval
+type OUTScheduleEvent struct {
+	ID            *uint      `json:"id,omitempty" conversor:"id"`
+	Status        *string    `json:"status,omitempty" conversor:"status"`
+	Reason        *string    `json:"reason,omitempty" conversor:"reason"`
+	PreviousStart *time.Time `json:"previous_start,omitempty" conversor:"previous_start"`
+	PreviousEnd   *time.Time `json:"previous_end,omitempty" conversor:"previous_end"`
+	Start         *time.Time `json:"start,omitempty" conversor:"start"`
+	End           *time.Time `json:"end,omitempty" conversor:"end"`
+	Compensation  *string    `json:"compensation,omitempty" conversor:"compensation"`
+	CreatedAt     *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTScheduleHistory models a schedule along with its status changes
+type OUTScheduleHistory struct {
+	Schedule *OUTSchedule `json:"schedule"`
+	Data     []OUTScheduleEvent
+}
+
+// OUTList models a list of classes
+type OUTList struct {
+	Data []OUTClass
+}
+
+// INRecurrence models a recurring schedule for insertion. RRule follows the
+// iCalendar syntax, e.g. FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20261231, Start is
+// the first occurrence and Duration the length of each one in minutes
+type INRecurrence struct {
+	RRule    *string `json:"rrule" binding:"required"`
+	Start    *string `json:"start" binding:"required"`
+	Duration *int64  `json:"duration" binding:"required,gt=0"`
+}
+
+// INOccurrence models the change of a single occurrence, or of an
+// occurrence and the following ones when RRule is optionally replaced.
+// Reason is told to the students along with the new period
+type INOccurrence struct {
+	Start  *string `json:"start" binding:"required"`
+	End    *string `json:"end" binding:"required"`
+	RRule  *string `json:"rrule"`
+	Reason *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// OUTRecurrence models a recurring schedule for retrieval
+type OUTRecurrence struct {
+	ID                *uint                    `json:"id,omitempty" conversor:"id"`
+	ClassID           *uint                    `json:"class_id,omitempty" conversor:"class_id"`
+	RRule             *string                  `json:"rrule,omitempty" conversor:"rrule"`
+	Start             *time.Time               `json:"start,omitempty" conversor:"start"`
+	Duration          *int64                   `json:"duration,omitempty" conversor:"duration"`
+	MaterializedUntil *time.Time               `json:"materialized_until,omitempty" conversor:"materialized_until"`
+	CreatedAt         *time.Time               `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt         *time.Time               `json:"updated_at,omitempty" conversor:"updated_at"`
+	Exceptions        []OUTRecurrenceException `json:"exceptions,omitempty"`
+}
+
+// OUTRecurrenceException models a canceled or moved occurrence for retrieval
+type OUTRecurrenceException struct {
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty" conversor:"occurrence_start"`
+	Canceled        *bool      `json:"canceled,omitempty" conversor:"canceled"`
+	Start           *time.Time `json:"start,omitempty" conversor:"start"`
+	End             *time.Time `json:"end,omitempty" conversor:"end"`
+}
+
+// OUTRecurrenceList models a list of recurring schedules
+type OUTRecurrenceList struct {
+	Data []OUTRecurrence
+}
+
+// OUTImport models the schedules read from an iCalendar file,
+// along with the events that couldn't be imported
+type OUTImport struct {
+	Data    []OUTImportedSchedule
+	Skipped []OUTSkippedEvent
+}
+
+// OUTImportedSchedule models a schedule read from an iCalendar
+// file along with the schedules it collides with
+type OUTImportedSchedule struct {
+	ID        *uint         `json:"id,omitempty"`
+	UID       string        `json:"uid"`
+	Start     *time.Time    `json:"start"`
+	End       *time.Time    `json:"end"`
+	Conflicts []OUTSchedule `json:"conflicts,omitempty"`
+}
+
+// OUTSkippedEvent models an event of an iCalendar file left out of the import
+type OUTSkippedEvent struct {
+	UID    string     `json:"uid"`
+	Start  *time.Time `json:"start"`
+	Reason string     `json:"reason"`
+}
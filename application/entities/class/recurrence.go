@@ -0,0 +1,732 @@
+package class
+
+import (
+	"go-api/database"
+	domain "go-api/domain/entities/class"
+	repository "go-api/infrastructure/persistance/class"
+	"go-api/oops"
+	"go-api/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// materializationWindow is how far ahead the occurrences
+	// of recurring schedules are materialized as schedules
+	materializationWindow = 90 * 24 * time.Hour
+
+	// ScopeThis applies a change only to the selected occurrence
+	ScopeThis = "this"
+	// ScopeFollowing applies a change to the selected occurrence and the following ones
+	ScopeFollowing = "following"
+)
+
+// AddRecurrence do the business logic of inserting a recurring schedule into
+// a class, materializing its occurrences inside the rolling window
+func AddRecurrence(classID uint, in *INRecurrence) (id uint, err error) {
+	var (
+		classRepo domain.IClass      = &repository.Repository{}
+		repo      domain.IRecurrence = &repository.RecurrenceRepository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class := &domain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return id, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	loc, err := Location(class)
+	if err != nil {
+		return id, oops.Wrap(err, "Error when loading location.")
+	}
+
+	// occurrences keep the wall clock time of the first one in the class
+	// time zone, moving their UTC instant across daylight saving transitions
+	start, err := parseDate("start", *in.Start, loc)
+	if err != nil {
+		return id, err
+	}
+
+	rule, err := parseRRule(*in.RRule, loc)
+	if err != nil {
+		return id, err
+	}
+
+	rrule := rule.String()
+	data := &domain.Recurrence{RRule: &rrule, Start: start, Duration: in.Duration, ClassID: &classID}
+
+	if err = repo.Add(data, tx); err != nil {
+		return id, oops.Wrap(err, "Error when adding new recurrence.")
+	}
+
+	if err = materialize(data, rule, *class.TeacherID, true, tx); err != nil {
+		return id, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// DeleteRecurrence do the business logic of removing a recurring
+// schedule along with its upcoming occurrences
+func DeleteRecurrence(classID, id uint) (err error) {
+	var (
+		repo         domain.IRecurrence = &repository.RecurrenceRepository{}
+		scheduleRepo domain.ISchedule   = &repository.ScheduleRepository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Lock(&domain.Recurrence{ID: &id, ClassID: &classID}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching recurrence.")
+	}
+
+	now := time.Now()
+
+	canceled, err := occurrencesFrom(id, now, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = scheduleRepo.DeleteOccurrencesFrom(id, now, tx); err != nil {
+		return oops.Wrap(err, "Error when removing occurrences.")
+	}
+
+	if err = scheduleCanceled(&ScheduleCancel{ClassID: classID, Schedules: canceled, Compensation: CompensationRefund}, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when removing recurrence.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// GetRecurrence do the business logic of fetching a recurring schedule
+func GetRecurrence(classID, id uint, token string) (out *OUTRecurrence, err error) {
+	var repo domain.IRecurrence = &repository.RecurrenceRepository{}
+
+	db := database.GetDBSession()
+
+	loc, err := classLocation(classID, "", token, db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &domain.Recurrence{ID: &id, ClassID: &classID}
+
+	if err = repo.Get(data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching recurrence.")
+	}
+
+	if out, err = toOUTRecurrence(data, loc); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetRecurrences do the business logic of listing the recurring schedules of a class
+func GetRecurrences(classID uint, token string) (out *OUTRecurrenceList, err error) {
+	var repo domain.IRecurrence = &repository.RecurrenceRepository{}
+
+	db := database.GetDBSession()
+
+	loc, err := classLocation(classID, "", token, db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []domain.Recurrence{}
+
+	if err = repo.GetAll(&data, classID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing recurrences.")
+	}
+
+	out = &OUTRecurrenceList{Data: make([]OUTRecurrence, len(data))}
+
+	for i := range data {
+		item, err := toOUTRecurrence(&data[i], loc)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+		out.Data[i] = *item
+	}
+
+	return out, nil
+}
+
+// UpdateOccurrence do the business logic of moving an occurrence of a
+// recurring schedule. With ScopeThis only the given occurrence is moved and
+// an exception is recorded, with ScopeFollowing the recurrence is split and
+// a new one, starting at the new period, replaces the following occurrences.
+// Either way the moves are kept in the history and the students are told
+func UpdateOccurrence(classID, recurrenceID, scheduleID uint, scope string, in *INOccurrence) (err error) {
+	var repo domain.IRecurrence = &repository.RecurrenceRepository{}
+
+	if err = validateScope(scope); err != nil {
+		return err
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	rec, occurrence, err := lockOccurrence(classID, recurrenceID, scheduleID, tx)
+	if err != nil {
+		return err
+	}
+
+	data, err := parseSchedule(&INSchedule{Start: in.Start, End: in.End}, rec.Start.Location())
+	if err != nil {
+		return err
+	}
+
+	reason := defaultRescheduleReason
+	if in.Reason != nil {
+		reason = *in.Reason
+	}
+
+	switch scope {
+	case ScopeThis:
+		if err = moveSchedule(classID, scheduleID, *data.Start, *data.End, reason, tx); err != nil {
+			return err
+		}
+
+	case ScopeFollowing:
+		rule, err := parseRRule(*rec.RRule, rec.Start.Location())
+		if err != nil {
+			return err
+		}
+
+		next := *rule
+
+		if in.RRule != nil {
+			parsed, err := parseRRule(*in.RRule, data.Start.Location())
+			if err != nil {
+				return err
+			}
+			next = *parsed
+		} else if rule.Count > 0 {
+			next.Count = rule.Count - rule.CountBefore(*rec.Start, *occurrence.OccurrenceStart)
+		}
+
+		duration := int64(data.End.Sub(*data.Start) / time.Minute)
+		if duration < 1 {
+			return oops.NewErr("Horário deve durar pelo menos um minuto")
+		}
+
+		previous, err := occurrencesFrom(recurrenceID, *occurrence.OccurrenceStart, tx)
+		if err != nil {
+			return err
+		}
+
+		if err = truncate(rec, rule, *occurrence.OccurrenceStart, tx); err != nil {
+			return err
+		}
+
+		rrule := next.String()
+		split := &domain.Recurrence{RRule: &rrule, Start: data.Start, Duration: &duration, ClassID: &classID}
+
+		if err = repo.Add(split, tx); err != nil {
+			return oops.Wrap(err, "Error when adding new recurrence.")
+		}
+
+		if err = materialize(split, &next, *occurrence.TeacherID, true, tx); err != nil {
+			return err
+		}
+
+		current, err := occurrencesFrom(*split.ID, *split.Start, tx)
+		if err != nil {
+			return err
+		}
+
+		if err = seriesMoved(classID, previous, current, *split.MaterializedUntil, reason, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// CancelOccurrence do the business logic of canceling an occurrence of a
+// recurring schedule. With ScopeFollowing the recurrence ends right before it
+func CancelOccurrence(classID, recurrenceID, scheduleID uint, scope string) (err error) {
+	var (
+		repo         domain.IRecurrence = &repository.RecurrenceRepository{}
+		scheduleRepo domain.ISchedule   = &repository.ScheduleRepository{}
+	)
+
+	if err = validateScope(scope); err != nil {
+		return err
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	rec, occurrence, err := lockOccurrence(classID, recurrenceID, scheduleID, tx)
+	if err != nil {
+		return err
+	}
+
+	switch scope {
+	case ScopeThis:
+		canceled := true
+		exception := &domain.RecurrenceException{
+			RecurrenceID:    &recurrenceID,
+			OccurrenceStart: occurrence.OccurrenceStart,
+			Canceled:        &canceled,
+		}
+
+		if err = repo.SaveException(exception, tx); err != nil {
+			return oops.Wrap(err, "Error when saving recurrence exception.")
+		}
+
+		if err = scheduleRepo.Delete(scheduleID, tx); err != nil {
+			return oops.Wrap(err, "Error when removing schedule.")
+		}
+
+		if err = scheduleCanceled(&ScheduleCancel{
+			ClassID:      classID,
+			Schedules:    []domain.Schedule{*occurrence},
+			Compensation: CompensationRefund,
+		}, tx); err != nil {
+			return err
+		}
+
+	case ScopeFollowing:
+		rule, err := parseRRule(*rec.RRule, rec.Start.Location())
+		if err != nil {
+			return err
+		}
+
+		canceled, err := occurrencesFrom(recurrenceID, *occurrence.OccurrenceStart, tx)
+		if err != nil {
+			return err
+		}
+
+		if err = truncate(rec, rule, *occurrence.OccurrenceStart, tx); err != nil {
+			return err
+		}
+
+		if err = scheduleCanceled(&ScheduleCancel{ClassID: classID, Schedules: canceled, Compensation: CompensationRefund}, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// ExtendRecurrences moves the rolling window of every recurrence forward,
+// materializing the new occurrences. Occurrences conflicting with other
+// schedules of the teacher are skipped
+func ExtendRecurrences() (err error) {
+	var repo domain.IRecurrence = &repository.RecurrenceRepository{}
+
+	pending := []domain.Recurrence{}
+
+	if err = repo.Pending(&pending, time.Now().Add(materializationWindow), database.GetDBSession()); err != nil {
+		return oops.Wrap(err, "Error when listing pending recurrences.")
+	}
+
+	for _, rec := range pending {
+		if err := extendRecurrence(*rec.ID); err != nil {
+			log.Printf("Failed materializing recurrence %d: %v\n", *rec.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunMaterializer periodically extends the recurrences, keeping the
+// materialized occurrences one window ahead of the current time
+func RunMaterializer(interval time.Duration) {
+	for {
+		if err := ExtendRecurrences(); err != nil {
+			log.Println(err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+func extendRecurrence(id uint) (err error) {
+	var (
+		classRepo domain.IClass      = &repository.Repository{}
+		repo      domain.IRecurrence = &repository.RecurrenceRepository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	rec := &domain.Recurrence{ID: &id}
+
+	if err = repo.Lock(rec, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching recurrence.")
+	}
+
+	class := &domain.Class{ID: rec.ClassID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = localize(rec, class); err != nil {
+		return err
+	}
+
+	rule, err := parseRRule(*rec.RRule, rec.Start.Location())
+	if err != nil {
+		return err
+	}
+
+	if err = materialize(rec, rule, *class.TeacherID, false, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// materialize creates the schedules for the occurrences of a recurrence
+// between the end of the last materialized window and the end of the current
+// one. When strict is set any conflict aborts the operation, otherwise the
+// conflicting occurrences are skipped
+func materialize(rec *domain.Recurrence, rule *utils.RRule, teacherID uint, strict bool, tx *gorm.DB) error {
+	var (
+		repo         domain.IRecurrence = &repository.RecurrenceRepository{}
+		scheduleRepo domain.ISchedule   = &repository.ScheduleRepository{}
+	)
+
+	now := time.Now()
+	to := now.Add(materializationWindow)
+	from := *rec.Start
+
+	if rec.MaterializedUntil != nil && rec.MaterializedUntil.After(from) {
+		from = *rec.MaterializedUntil
+	}
+
+	if now.After(from) {
+		from = now
+	}
+
+	existing := []domain.Schedule{}
+
+	if err := scheduleRepo.Occurrences(*rec.ID, from, to, &existing, tx); err != nil {
+		return oops.Wrap(err, "Error when listing occurrences.")
+	}
+
+	materialized := map[int64]bool{}
+	for _, schedule := range existing {
+		materialized[schedule.OccurrenceStart.Unix()] = true
+	}
+
+	exceptions := map[int64]domain.RecurrenceException{}
+	for _, exception := range rec.Exceptions {
+		exceptions[exception.OccurrenceStart.Unix()] = exception
+	}
+
+	var (
+		duration  = time.Duration(*rec.Duration) * time.Minute
+		conflicts = []domain.Schedule{}
+		seen      = map[uint]bool{}
+	)
+
+	for _, occurrence := range rule.Between(*rec.Start, from, to) {
+		occurrence := occurrence
+
+		if materialized[occurrence.Unix()] {
+			continue
+		}
+
+		start, end := occurrence, occurrence.Add(duration)
+
+		if exception, ok := exceptions[occurrence.Unix()]; ok {
+			if exception.Canceled != nil && *exception.Canceled {
+				continue
+			}
+			if exception.Start != nil && exception.End != nil {
+				start, end = *exception.Start, *exception.End
+			}
+		}
+
+		data := &domain.Schedule{
+			Start:           &start,
+			End:             &end,
+			ClassID:         rec.ClassID,
+			TeacherID:       &teacherID,
+			RecurrenceID:    rec.ID,
+			OccurrenceStart: &occurrence,
+		}
+
+		found := []domain.Schedule{}
+
+		if err := scheduleRepo.Conflicts(data, &found, tx); err != nil {
+			return oops.Wrap(err, "Error when checking schedule conflicts.")
+		}
+
+		if len(found) > 0 {
+			if !strict {
+				log.Printf("Skipping occurrence %v of recurrence %d: conflicts with %d schedule(s)\n", occurrence, *rec.ID, len(found))
+				continue
+			}
+
+			for _, schedule := range found {
+				if !seen[*schedule.ID] {
+					seen[*schedule.ID] = true
+					conflicts = append(conflicts, schedule)
+				}
+			}
+			continue
+		}
+
+		// once a conflict was found the operation is going to be
+		// aborted, so the remaining occurrences are only checked
+		if len(conflicts) > 0 {
+			continue
+		}
+
+		if err := scheduleRepo.Add(data, tx); err != nil {
+			if strict && oops.IsPgCode(err, exclusionViolation) {
+				return conflictError(data)
+			}
+			return oops.Wrap(err, "Error when materializing occurrence.")
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return newConflictError(conflicts)
+	}
+
+	rec.MaterializedUntil = &to
+
+	if err := repo.Update(&domain.Recurrence{ID: rec.ID, MaterializedUntil: &to}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating recurrence.")
+	}
+
+	return nil
+}
+
+// seriesMoved records the occurrences of a recurrence replaced by a split
+// as rescheduled to the new occurrences, pairing them in order. The previous
+// ones left without a counterpart until the end of the materialized window
+// are canceled and refunded, and the students are told of the move once
+func seriesMoved(classID uint, previous, current []domain.Schedule, until time.Time, reason string, tx *gorm.DB) error {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	canceled := []domain.Schedule{}
+
+	for i, schedule := range previous {
+		status := domain.ScheduleRescheduled
+		event := &domain.ScheduleEvent{ScheduleID: schedule.ID, Status: &status, Reason: &reason, PreviousStart: schedule.Start, PreviousEnd: schedule.End}
+
+		if i < len(current) {
+			event.Start, event.End = current[i].Start, current[i].End
+		} else if schedule.Start.Before(until) {
+			status, compensation := domain.ScheduleCanceled, CompensationRefund
+			event = &domain.ScheduleEvent{ScheduleID: schedule.ID, Status: &status, Reason: &reason, Start: schedule.Start, End: schedule.End, Compensation: &compensation}
+			canceled = append(canceled, schedule)
+		} else {
+			// it is replaced once the window of the split reaches it
+			continue
+		}
+
+		if err := repo.AddEvent(event, tx); err != nil {
+			return oops.Wrap(err, "Error when adding schedule event.")
+		}
+	}
+
+	if err := scheduleCanceled(&ScheduleCancel{ClassID: classID, Schedules: canceled, Reason: reason, Compensation: CompensationRefund}, tx); err != nil {
+		return err
+	}
+
+	if len(previous) == 0 || len(current) == 0 {
+		return nil
+	}
+
+	moved := len(previous)
+	if len(current) < moved {
+		moved = len(current)
+	}
+
+	return scheduleMoved(&ScheduleChange{ClassID: classID, Previous: previous[0], Current: current[0], Reason: reason, Following: moved - 1}, tx)
+}
+
+// truncate ends a recurrence right before the given occurrence,
+// removing the occurrences already materialized from it on
+func truncate(rec *domain.Recurrence, rule *utils.RRule, at time.Time, tx *gorm.DB) error {
+	var (
+		repo         domain.IRecurrence = &repository.RecurrenceRepository{}
+		scheduleRepo domain.ISchedule   = &repository.ScheduleRepository{}
+	)
+
+	until := at.Add(-time.Second)
+	rule.Count, rule.Until = 0, &until
+	rrule := rule.String()
+
+	if err := repo.Update(&domain.Recurrence{ID: rec.ID, RRule: &rrule}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating recurrence.")
+	}
+
+	if err := scheduleRepo.DeleteOccurrencesFrom(*rec.ID, at, tx); err != nil {
+		return oops.Wrap(err, "Error when removing occurrences.")
+	}
+
+	return nil
+}
+
+// occurrencesFrom lists the schedules of a recurrence that weren't
+// deleted and whose occurrence starts at or after the given instant
+func occurrencesFrom(recurrenceID uint, from time.Time, tx *gorm.DB) (out []domain.Schedule, err error) {
+	var scheduleRepo domain.ISchedule = &repository.ScheduleRepository{}
+
+	all := []domain.Schedule{}
+
+	// the occurrences are materialized at most one window ahead of now
+	to := time.Now().Add(2 * materializationWindow)
+	if from.After(to) {
+		return nil, nil
+	}
+
+	if err = scheduleRepo.Occurrences(recurrenceID, from, to, &all, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when listing occurrences.")
+	}
+
+	for _, s := range all {
+		if s.DeletedAt == nil || !s.DeletedAt.Valid {
+			out = append(out, s)
+		}
+	}
+
+	return out, nil
+}
+
+// lockOccurrence fetches a recurrence, locking it, and one of its occurrences.
+// The start of the recurrence is given on the time zone of the class
+func lockOccurrence(classID, recurrenceID, scheduleID uint, tx *gorm.DB) (*domain.Recurrence, *domain.Schedule, error) {
+	var (
+		classRepo    domain.IClass      = &repository.Repository{}
+		repo         domain.IRecurrence = &repository.RecurrenceRepository{}
+		scheduleRepo domain.ISchedule   = &repository.ScheduleRepository{}
+	)
+
+	rec := &domain.Recurrence{ID: &recurrenceID, ClassID: &classID}
+
+	if err := repo.Lock(rec, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching recurrence.")
+	}
+
+	class := &domain.Class{ID: &classID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err := localize(rec, class); err != nil {
+		return nil, nil, err
+	}
+
+	occurrence := &domain.Schedule{ID: &scheduleID, ClassID: &classID}
+
+	if err := scheduleRepo.Get(occurrence, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	if occurrence.RecurrenceID == nil || *occurrence.RecurrenceID != recurrenceID {
+		return nil, nil, oops.NewErr("Horário não pertence à recorrência informada")
+	}
+
+	return rec, occurrence, nil
+}
+
+// localize moves the start of a recurrence to the time zone of its class,
+// the one whose wall clock time the occurrences are expanded on
+func localize(rec *domain.Recurrence, class *domain.Class) error {
+	loc, err := Location(class)
+	if err != nil {
+		return oops.Wrap(err, "Error when loading location.")
+	}
+
+	rec.Start = inZone(rec.Start, loc)
+
+	return nil
+}
+
+func parseRRule(value string, loc *time.Location) (*utils.RRule, error) {
+	rule, err := utils.ParseRRule(value, loc)
+	if err != nil {
+		return nil, oops.NewErr("Regra de recorrência inválida: " + err.Error())
+	}
+	return rule, nil
+}
+
+func validateScope(scope string) error {
+	if scope != ScopeThis && scope != ScopeFollowing {
+		return oops.NewErr("Escopo deve ser '" + ScopeThis + "' ou '" + ScopeFollowing + "'")
+	}
+	return nil
+}
+
+func toOUTRecurrence(data *domain.Recurrence, loc *time.Location) (out *OUTRecurrence, err error) {
+	out = &OUTRecurrence{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	out.Start, out.MaterializedUntil = inZone(out.Start, loc), inZone(out.MaterializedUntil, loc)
+
+	if data.Exceptions != nil {
+		out.Exceptions = make([]OUTRecurrenceException, len(data.Exceptions))
+		for i := range data.Exceptions {
+			item := &out.Exceptions[i]
+			if err = utils.ConvertStruct(&data.Exceptions[i], item); err != nil {
+				return nil, err
+			}
+			item.OccurrenceStart = inZone(item.OccurrenceStart, loc)
+			item.Start, item.End = inZone(item.Start, loc), inZone(item.End, loc)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,651 @@
+package class
+
+import (
+	"errors"
+	"go-api/database"
+	domain "go-api/domain/entities/class"
+	repository "go-api/infrastructure/persistance/class"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// exclusionViolation is the SQLSTATE raised when two schedules
+// of the same teacher overlap
+const exclusionViolation = "23P01"
+
+// defaultRescheduleReason is recorded when the teacher
+// changes the period of a schedule without giving a reason
+const defaultRescheduleReason = "Horário alterado pelo professor"
+
+const (
+	// CompensationRefund gives the paid students their share back through the payment provider
+	CompensationRefund = "refund"
+	// CompensationNone leaves the paid students without compensation
+	CompensationNone = "none"
+)
+
+// ScheduleCancel describes schedules of a class canceled together, along
+// with the reason given and how the paid students are compensated
+type ScheduleCancel struct {
+	ClassID      uint
+	Schedules    []domain.Schedule
+	Reason       string
+	Compensation string
+}
+
+// ScheduleChange describes a schedule moved to another period. Following
+// counts the later occurrences of a recurrence moved along with it
+type ScheduleChange struct {
+	ClassID   uint
+	Previous  domain.Schedule
+	Current   domain.Schedule
+	Reason    string
+	Following int
+}
+
+// ScheduleCancelHook is called inside the transaction removing
+// schedules of a class, receiving the removed schedules
+type ScheduleCancelHook func(cancel *ScheduleCancel, tx *gorm.DB) error
+
+// RescheduleHook is called inside the transaction rescheduling a schedule
+type RescheduleHook func(change *ScheduleChange, tx *gorm.DB) error
+
+var (
+	// scheduleCancelHooks are the functions called when schedules are removed
+	scheduleCancelHooks []ScheduleCancelHook
+	// rescheduleHooks are the functions called when a schedule is rescheduled
+	rescheduleHooks []RescheduleHook
+)
+
+// OnScheduleCancel registers a function to be called when schedules of a class
+// are removed, letting other modules react to it. An error aborts the removal
+func OnScheduleCancel(hook ScheduleCancelHook) {
+	scheduleCancelHooks = append(scheduleCancelHooks, hook)
+}
+
+// OnReschedule registers a function to be called when a schedule is moved to
+// another period, letting other modules react to it. An error aborts the change
+func OnReschedule(hook RescheduleHook) {
+	rescheduleHooks = append(rescheduleHooks, hook)
+}
+
+// AddSchedule do the business logic of inserting a schedule into a class
+func AddSchedule(classID uint, in *INSchedule) (id uint, err error) {
+	var (
+		classRepo domain.IClass    = &repository.Repository{}
+		repo      domain.ISchedule = &repository.ScheduleRepository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class := &domain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return id, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	loc, err := Location(class)
+	if err != nil {
+		return id, oops.Wrap(err, "Error when loading location.")
+	}
+
+	data, err := parseSchedule(in, loc)
+	if err != nil {
+		return id, err
+	}
+
+	data.ClassID, data.TeacherID = &classID, class.TeacherID
+
+	if err = checkConflicts(data, tx); err != nil {
+		return id, err
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		if oops.IsPgCode(err, exclusionViolation) {
+			return id, conflictError(data)
+		}
+		return id, oops.Wrap(err, "Error when adding new schedule.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// UpdateSchedule do the business logic of updating the period of a class
+// schedule. The change is recorded as a rescheduling, so it is kept in the
+// history and the students are told, just like through Reschedule
+func UpdateSchedule(classID, id uint, in *INSchedule) (err error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	current := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err = repo.Get(current, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	loc, err := scheduleLocation(classID, tx)
+	if err != nil {
+		return err
+	}
+
+	data, err := parseSchedule(in, loc)
+	if err != nil {
+		return err
+	}
+
+	if data.Start.Equal(*current.Start) && data.End.Equal(*current.End) {
+		return nil
+	}
+
+	if err = moveSchedule(classID, id, *data.Start, *data.End, defaultRescheduleReason, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// DeleteSchedule do the business logic of removing a class schedule. The
+// removal is recorded as a cancellation, so the schedule is kept in the
+// history and the paid students of upcoming schedules are refunded
+func DeleteSchedule(classID, id uint) (err error) {
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = cancelSchedule(classID, id, "Aula removida pelo professor", CompensationRefund, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// CancelSchedule do the business logic of the teacher canceling a schedule
+// that hasn't ended. The schedule is kept in the history along with the
+// reason, and the paid students are compensated as asked, refunded by default
+func CancelSchedule(classID, id uint, in *INCancelSchedule) (err error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err = repo.Get(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	if !data.End.After(time.Now()) {
+		return oops.NewErr("Aula já foi encerrada")
+	}
+
+	compensation := in.Compensation
+	if compensation == "" {
+		compensation = CompensationRefund
+	}
+
+	if err = cancelSchedule(classID, id, *in.Reason, compensation, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Reschedule do the business logic of the teacher moving a schedule that
+// hasn't ended to another period, keeping the previous one in the history
+func Reschedule(classID, id uint, in *INReschedule) (err error) {
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	loc, err := scheduleLocation(classID, tx)
+	if err != nil {
+		return err
+	}
+
+	data, err := parseSchedule(&INSchedule{Start: in.Start, End: in.End}, loc)
+	if err != nil {
+		return err
+	}
+
+	if err = moveSchedule(classID, id, *data.Start, *data.End, *in.Reason, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// GetScheduleHistory do the business logic of listing the status changes of
+// a schedule, canceled ones included, with their dates on the given time zone
+// or on the time zone of the class when empty
+func GetScheduleHistory(classID, id uint, timeZone, token string) (out *OUTScheduleHistory, err error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	db := database.GetDBSession()
+
+	loc, err := classLocation(classID, timeZone, token, db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err = repo.GetWithDeleted(data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	events := []domain.ScheduleEvent{}
+
+	if err = repo.GetEvents(&events, id, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing schedule events.")
+	}
+
+	out = &OUTScheduleHistory{Data: make([]OUTScheduleEvent, len(events))}
+
+	if out.Schedule, err = toOUTSchedule(data, loc); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	for i := range events {
+		if err = utils.ConvertStruct(&events[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		e := &out.Data[i]
+		e.PreviousStart, e.PreviousEnd = inZone(e.PreviousStart, loc), inZone(e.PreviousEnd, loc)
+		e.Start, e.End = inZone(e.Start, loc), inZone(e.End, loc)
+	}
+
+	return out, nil
+}
+
+// GetSchedule do the business logic of fetching a class schedule, with its
+// dates on the given time zone or on the time zone of the class when empty
+func GetSchedule(classID, id uint, timeZone, token string) (out *OUTSchedule, err error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	db := database.GetDBSession()
+
+	loc, err := classLocation(classID, timeZone, token, db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err = repo.Get(data, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	if out, err = toOUTSchedule(data, loc); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetSchedules do the business logic of listing the schedules of a class, with
+// their dates on the given time zone or on the time zone of the class when empty
+func GetSchedules(classID uint, timeZone, token string) (out *OUTScheduleList, err error) {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	db := database.GetDBSession()
+
+	loc, err := classLocation(classID, timeZone, token, db)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []domain.Schedule{}
+
+	if err = repo.GetAll(&data, classID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing schedules.")
+	}
+
+	out = &OUTScheduleList{Data: make([]OUTSchedule, len(data))}
+
+	for i := range data {
+		item, err := toOUTSchedule(&data[i], loc)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+		out.Data[i] = *item
+	}
+
+	return out, nil
+}
+
+// classLocation fetches a class visible to students, or to the teacher owning
+// the token, and returns the time zone its dates are shown on, the given one
+// or the time zone of the class when empty
+func classLocation(classID uint, timeZone, token string, db *gorm.DB) (*time.Location, error) {
+	var classRepo domain.IClass = &repository.Repository{}
+
+	loc, err := parseTimeZone(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	class := &domain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = checkVisible(class, token, db); err != nil {
+		return nil, err
+	}
+
+	if loc != nil {
+		return loc, nil
+	}
+
+	if loc, err = Location(class); err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	return loc, nil
+}
+
+// scheduleLocation returns the time zone the schedules of a class are written on
+func scheduleLocation(classID uint, tx *gorm.DB) (*time.Location, error) {
+	var classRepo domain.IClass = &repository.Repository{}
+
+	class := &domain.Class{ID: &classID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	loc, err := Location(class)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	return loc, nil
+}
+
+// moveSchedule moves a schedule that hasn't ended to another period, recording
+// the change in the history, and calls the reschedule hooks to notify the
+// students. Every change of the period of a single schedule goes through it
+func moveSchedule(classID, id uint, start, end time.Time, reason string, tx *gorm.DB) error {
+	var (
+		repo           domain.ISchedule   = &repository.ScheduleRepository{}
+		recurrenceRepo domain.IRecurrence = &repository.RecurrenceRepository{}
+	)
+
+	current := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err := repo.Get(current, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	if !current.End.After(time.Now()) {
+		return oops.NewErr("Aula já foi encerrada")
+	}
+
+	if start.Equal(*current.Start) && end.Equal(*current.End) {
+		return oops.NewErr("Novo horário deve ser diferente do atual")
+	}
+
+	status := domain.ScheduleRescheduled
+	data := &domain.Schedule{ID: &id, ClassID: &classID, TeacherID: current.TeacherID, Start: &start, End: &end, Status: &status}
+
+	if err := checkConflicts(data, tx); err != nil {
+		return err
+	}
+
+	if err := repo.Update(data, tx); err != nil {
+		if oops.IsPgCode(err, exclusionViolation) {
+			return conflictError(data)
+		}
+		return oops.Wrap(err, "Error when updating schedule.")
+	}
+
+	// the materializer must keep a moved occurrence in its new period
+	if current.RecurrenceID != nil {
+		canceled := false
+		exception := &domain.RecurrenceException{
+			RecurrenceID:    current.RecurrenceID,
+			OccurrenceStart: current.OccurrenceStart,
+			Canceled:        &canceled,
+			Start:           data.Start,
+			End:             data.End,
+		}
+
+		if err := recurrenceRepo.SaveException(exception, tx); err != nil {
+			return oops.Wrap(err, "Error when saving recurrence exception.")
+		}
+	}
+
+	event := &domain.ScheduleEvent{
+		ScheduleID:    &id,
+		Status:        &status,
+		Reason:        &reason,
+		PreviousStart: current.Start,
+		PreviousEnd:   current.End,
+		Start:         data.Start,
+		End:           data.End,
+	}
+
+	if err := repo.AddEvent(event, tx); err != nil {
+		return oops.Wrap(err, "Error when adding schedule event.")
+	}
+
+	data.RecurrenceID, data.OccurrenceStart = current.RecurrenceID, current.OccurrenceStart
+
+	return scheduleMoved(&ScheduleChange{ClassID: classID, Previous: *current, Current: *data, Reason: reason}, tx)
+}
+
+// scheduleMoved calls the hooks registered for rescheduled schedules
+func scheduleMoved(change *ScheduleChange, tx *gorm.DB) error {
+	for _, hook := range rescheduleHooks {
+		if err := hook(change, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cancelSchedule removes a schedule recording its cancellation in the
+// history, and calls the cancel hooks to compensate and notify the students
+func cancelSchedule(classID, id uint, reason, compensation string, tx *gorm.DB) error {
+	var (
+		repo           domain.ISchedule   = &repository.ScheduleRepository{}
+		recurrenceRepo domain.IRecurrence = &repository.RecurrenceRepository{}
+	)
+
+	data := &domain.Schedule{ID: &id, ClassID: &classID}
+
+	if err := repo.Get(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching schedule.")
+	}
+
+	// the materializer must not bring back a canceled occurrence
+	if data.RecurrenceID != nil {
+		canceled := true
+		exception := &domain.RecurrenceException{
+			RecurrenceID:    data.RecurrenceID,
+			OccurrenceStart: data.OccurrenceStart,
+			Canceled:        &canceled,
+		}
+
+		if err := recurrenceRepo.SaveException(exception, tx); err != nil {
+			return oops.Wrap(err, "Error when saving recurrence exception.")
+		}
+	}
+
+	if err := repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when canceling schedule.")
+	}
+
+	status := domain.ScheduleCanceled
+	event := &domain.ScheduleEvent{
+		ScheduleID:   &id,
+		Status:       &status,
+		Reason:       &reason,
+		Start:        data.Start,
+		End:          data.End,
+		Compensation: &compensation,
+	}
+
+	if err := repo.AddEvent(event, tx); err != nil {
+		return oops.Wrap(err, "Error when adding schedule event.")
+	}
+
+	if err := scheduleCanceled(&ScheduleCancel{
+		ClassID:      classID,
+		Schedules:    []domain.Schedule{*data},
+		Reason:       reason,
+		Compensation: compensation,
+	}, tx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// scheduleCanceled calls the hooks registered for canceled schedules
+func scheduleCanceled(cancel *ScheduleCancel, tx *gorm.DB) error {
+	if len(cancel.Schedules) == 0 {
+		return nil
+	}
+
+	for _, hook := range scheduleCancelHooks {
+		if err := hook(cancel, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkConflicts looks for schedules of the same teacher overlapping the
+// given one. The exclusion constraint on the schedules table remains the
+// final guard against concurrent requests racing past this check
+func checkConflicts(data *domain.Schedule, db *gorm.DB) error {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	conflicts := []domain.Schedule{}
+
+	if err := repo.Conflicts(data, &conflicts, db); err != nil {
+		return oops.Wrap(err, "Error when checking schedule conflicts.")
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return newConflictError(conflicts)
+}
+
+// conflictError builds the conflict error after the exclusion constraint
+// rejected a schedule. The transaction is aborted at this point, so the
+// colliding schedules are fetched through a new session
+func conflictError(data *domain.Schedule) error {
+	var repo domain.ISchedule = &repository.ScheduleRepository{}
+
+	conflicts := []domain.Schedule{}
+
+	if err := repo.Conflicts(data, &conflicts, database.GetDBSession()); err != nil {
+		return oops.Wrap(err, "Error when checking schedule conflicts.")
+	}
+
+	return newConflictError(conflicts)
+}
+
+func newConflictError(conflicts []domain.Schedule) error {
+	out := make([]OUTSchedule, len(conflicts))
+
+	for i := range conflicts {
+		if err := utils.ConvertStruct(&conflicts[i], &out[i]); err != nil {
+			return oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return oops.NewConflict("Professor já possui aula agendada neste horário", out)
+}
+
+// parseSchedule converts the input dates, reading the ones without timezone
+// on the time zone of the class, and checks that they describe a valid
+// period that hasn't ended yet
+func parseSchedule(in *INSchedule, loc *time.Location) (*domain.Schedule, error) {
+	start, err := parseDate("start", *in.Start, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := parseDate("end", *in.End, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !end.After(*start) {
+		return nil, oops.NewErr("Campo end deve ser posterior ao campo start")
+	}
+
+	if start.Before(time.Now()) {
+		return nil, oops.NewErr("Horário não pode estar no passado")
+	}
+
+	return &domain.Schedule{Start: start, End: end}, nil
+}
+
+// parseDate converts an input date of the given field, reading it on the
+// location when it has no timezone. The result is shown on the location
+func parseDate(field, value string, loc *time.Location) (*time.Time, error) {
+	t, err := utils.ParseDateTimeIn(value, loc)
+	if err != nil {
+		var nonexistent *utils.NonexistentTimeError
+		if errors.As(err, &nonexistent) {
+			return nil, oops.Err(err)
+		}
+		return nil, oops.NewErr("Campo " + field + " não contém uma data válida")
+	}
+
+	return inZone(t, loc), nil
+}
@@ -0,0 +1,250 @@
+package class
+
+import (
+	"go-api/database"
+	bookingDomain "go-api/domain/entities/booking"
+	domain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	userDomain "go-api/domain/entities/user"
+	bookingRepository "go-api/infrastructure/persistance/booking"
+	repository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// transitions lists the statuses a class may move to from each status
+var transitions = map[string][]string{
+	domain.StatusDraft:     {domain.StatusPublished, domain.StatusArchived},
+	domain.StatusPublished: {domain.StatusDraft, domain.StatusFull, domain.StatusArchived},
+	domain.StatusFull:      {domain.StatusPublished, domain.StatusArchived},
+}
+
+// VisibleStatuses are the statuses of the classes listed to students.
+// Full classes stay visible so students may join their waitlist
+var VisibleStatuses = []string{domain.StatusPublished, domain.StatusFull}
+
+// checkVisible ensures a class is visible to students, unless the token is
+// the secret one of its teacher, as the teacher sees every status.
+// Hidden classes are reported as not found
+func checkVisible(data *domain.Class, token string, db *gorm.DB) error {
+	for _, status := range VisibleStatuses {
+		if *data.Status == status {
+			return nil
+		}
+	}
+
+	if token != "" {
+		teacher, err := isTeacher(data, token, db)
+		if err != nil {
+			return err
+		}
+
+		if teacher {
+			return nil
+		}
+	}
+
+	return oops.Err(&oops.ErrDataNotFound)
+}
+
+// isTeacher tells whether the token is the secret one of the teacher of the class.
+// The user module depends on this one, so the token is checked here directly
+func isTeacher(data *domain.Class, token string, db *gorm.DB) (bool, error) {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	teacher := &userDomain.User{ID: data.TeacherID}
+
+	if err := userRepo.Get(teacher, db); err != nil {
+		return false, oops.Wrap(err, "Error when fetching teacher.")
+	}
+
+	return teacher.APIToken != nil && token != "" && utils.SameToken(*teacher.APIToken, token), nil
+}
+
+// UpdateStatus do the business logic of the teacher moving a class to another
+// status, proven by their secret token. Publishing requires a price and an
+// upcoming schedule and a class only goes back to draft while nobody is
+// enrolled. Full is only reached and left through enrollments, so a full
+// class can't be published by hand
+func UpdateStatus(id uint, token string, in *INStatus) (err error) {
+	var (
+		repo           domain.IClass                = &repository.Repository{}
+		scheduleRepo   domain.ISchedule             = &repository.ScheduleRepository{}
+		enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data := &domain.Class{ID: &id}
+
+	if err = repo.Lock(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	// only the teacher moves the class, the status history records them as the actor
+	if *data.TeacherID != *in.ActorID {
+		return oops.NewErr("Apenas o professor da turma pode alterar seu status")
+	}
+
+	teacher, err := isTeacher(data, token, tx)
+	if err != nil {
+		return err
+	}
+
+	if !teacher {
+		return oops.Err(&oops.ErrInvalidToken)
+	}
+
+	status := *in.Status
+
+	if *data.Status == status {
+		return nil
+	}
+
+	if *data.Status == domain.StatusFull && status == domain.StatusPublished {
+		return oops.NewErr("Turma lotada volta a ser publicada quando uma vaga é liberada")
+	}
+
+	switch status {
+	case domain.StatusPublished:
+		if data.Price == nil || *data.Price <= 0 {
+			return oops.NewErr("Turma precisa de um preço para ser publicada")
+		}
+
+		schedules := []domain.Schedule{}
+
+		if err = scheduleRepo.GetAll(&schedules, id, tx); err != nil {
+			return oops.Wrap(err, "Error when listing schedules.")
+		}
+
+		upcoming, now := false, time.Now()
+		for _, s := range schedules {
+			upcoming = upcoming || s.Start.After(now)
+		}
+
+		if !upcoming {
+			return oops.NewErr("Turma precisa de uma aula futura para ser publicada")
+		}
+
+	case domain.StatusDraft:
+		enrollments := []enrollmentDomain.Enrollment{}
+		filter := &enrollmentDomain.Filter{
+			ClassID: &id,
+			Status:  []string{enrollmentDomain.StatusEnrolled, enrollmentDomain.StatusWaitlisted},
+		}
+
+		if err = enrollmentRepo.GetAll(&enrollments, filter, tx); err != nil {
+			return oops.Wrap(err, "Error when listing enrollments.")
+		}
+
+		if len(enrollments) > 0 {
+			return oops.NewErr("Turma com inscrições não pode voltar a ser rascunho")
+		}
+	}
+
+	if err = transition(data, status, in.ActorID, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// SyncSeats moves a published class to full once its seats are taken and a
+// full class back to published when a seat is freed. Booked private lessons
+// are archived instead, as they are never open to other students. The class
+// must have been locked by the transaction. Meant to be registered as a seats hook
+func SyncSeats(data *domain.Class, enrolled int64, tx *gorm.DB) error {
+	var bookingRepo bookingDomain.IBooking = &bookingRepository.Repository{}
+
+	full := data.Capacity != nil && enrolled >= *data.Capacity
+
+	switch {
+	case *data.Status == domain.StatusPublished && full:
+		return transition(data, domain.StatusFull, nil, tx)
+	case *data.Status == domain.StatusFull && !full:
+		booked, err := bookingRepo.IsBooked(*data.ID, tx)
+		if err != nil {
+			return oops.Wrap(err, "Error when checking booking.")
+		}
+
+		if booked {
+			return transition(data, domain.StatusArchived, nil, tx)
+		}
+
+		return transition(data, domain.StatusPublished, nil, tx)
+	}
+
+	return nil
+}
+
+// GetStatusHistory do the business logic of listing the status changes of a class
+func GetStatusHistory(id uint) (out *OUTStatusEventList, err error) {
+	var repo domain.IClass = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = repo.Get(&domain.Class{ID: &id}, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	data := []domain.StatusEvent{}
+
+	if err = repo.GetStatusEvents(&data, id, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing status events.")
+	}
+
+	out = &OUTStatusEventList{Data: make([]OUTStatusEvent, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return out, nil
+}
+
+// transition moves a class to a new status when allowed, recording the
+// change along with the user who made it, none for the system
+func transition(data *domain.Class, status string, actorID *uint, tx *gorm.DB) error {
+	var repo domain.IClass = &repository.Repository{}
+
+	from := *data.Status
+
+	allowed := false
+	for _, next := range transitions[from] {
+		allowed = allowed || next == status
+	}
+
+	if !allowed {
+		return oops.NewErr("Turma não pode passar de " + from + " para " + status)
+	}
+
+	if err := repo.Update(&domain.Class{ID: data.ID, Status: &status}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating class.")
+	}
+
+	event := &domain.StatusEvent{ClassID: data.ID, From: &from, To: &status, ActorID: actorID}
+
+	if err := repo.AddStatusEvent(event, tx); err != nil {
+		return oops.Wrap(err, "Error when adding status event.")
+	}
+
+	data.Status = &status
+
+	return nil
+}
@@ -0,0 +1,422 @@
+package coupon
+
+import (
+	classApp "go-api/application/entities/class"
+	"go-api/database"
+	classDomain "go-api/domain/entities/class"
+	domain "go-api/domain/entities/coupon"
+	classRepository "go-api/infrastructure/persistance/class"
+	repository "go-api/infrastructure/persistance/coupon"
+	"go-api/oops"
+	"go-api/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Add do the business logic of inserting a coupon into the database
+func Add(in *INCoupon) (id uint, err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	data, err := parseCoupon(in)
+	if err != nil {
+		return id, err
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.GetByCode(&domain.Coupon{Code: data.Code}, tx); err == nil {
+		return id, oops.NewConflict("Já existe um cupom com este código", nil)
+	} else if !oops.IsNotFound(err) {
+		return id, oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return id, oops.Wrap(err, "Error when adding new coupon.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// Update do the business logic of replacing an existing coupon
+func Update(id uint, in *INCoupon) (err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	data, err := parseCoupon(in)
+	if err != nil {
+		return err
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Lock(&domain.Coupon{ID: &id}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	other := &domain.Coupon{Code: data.Code}
+
+	if err = repo.GetByCode(other, tx); err == nil && *other.ID != id {
+		return oops.NewConflict("Já existe um cupom com este código", nil)
+	} else if err != nil && !oops.IsNotFound(err) {
+		return oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	data.ID = &id
+
+	if err = repo.Update(data, tx); err != nil {
+		return oops.Wrap(err, "Error when updating coupon.")
+	}
+
+	if err = repo.SetRestrictions(id, data.Restrictions, tx); err != nil {
+		return oops.Wrap(err, "Error when updating coupon restrictions.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Delete do the business logic of removing a coupon
+func Delete(id uint) (err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = repo.Get(&domain.Coupon{ID: &id}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when removing coupon.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Get do the business logic of fetching a coupon by its ID
+func Get(id uint, locale string) (out *OUTCoupon, err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	data := &domain.Coupon{ID: &id}
+
+	if err = repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	if out, err = toOUTCoupon(data, locale); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetAll do the business logic of listing coupons
+func GetAll(locale string) (out *OUTList, err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	data := []domain.Coupon{}
+
+	if err = repo.GetAll(&data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when listing coupons.")
+	}
+
+	out = &OUTList{Data: make([]OUTCoupon, len(data))}
+
+	for i := range data {
+		item, err := toOUTCoupon(&data[i], locale)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+		out.Data[i] = *item
+	}
+
+	return out, nil
+}
+
+// Quote do the business logic of pricing a class with a coupon. The per
+// user limit can't be checked without knowing the user, so it is only
+// reported and enforced on checkout
+func Quote(classID uint, code string, locale string) (out *OUTQuote, err error) {
+	var (
+		classRepo classDomain.IClass = &classRepository.Repository{}
+		repo      domain.ICoupon     = &repository.Repository{}
+	)
+
+	db := database.GetDBSession()
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	data, err := findCoupon(code, repo, db)
+	if err != nil {
+		return nil, err
+	}
+
+	price := classApp.Price(class)
+
+	discount, err := apply(data, class, nil, repo, db)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := price.Sub(discount)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when applying discount.")
+	}
+
+	return &OUTQuote{
+		ClassID:        classID,
+		Code:           *data.Code,
+		Price:          classApp.ToOUTMoney(price, locale),
+		Discount:       classApp.ToOUTMoney(discount, locale),
+		Total:          classApp.ToOUTMoney(total, locale),
+		MaxUsesPerUser: data.MaxUsesPerUser,
+	}, nil
+}
+
+// Redeem records the use of a coupon by an user on a class and returns the
+// discount given along with the ID of the use, which releases it later.
+// The coupon row is locked so concurrent redemptions cannot exceed its limits
+func Redeem(code string, class *classDomain.Class, userID uint, tx *gorm.DB) (discount utils.Money, redemptionID uint, err error) {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	data, err := findCoupon(code, repo, tx)
+	if err != nil {
+		return discount, redemptionID, err
+	}
+
+	if err = repo.Lock(data, tx); err != nil {
+		return discount, redemptionID, oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	if discount, err = apply(data, class, &userID, repo, tx); err != nil {
+		return discount, redemptionID, err
+	}
+
+	redemption := &domain.Redemption{
+		CouponID: data.ID,
+		UserID:   &userID,
+		ClassID:  class.ID,
+		Discount: &discount.Amount,
+		Currency: &discount.Currency,
+	}
+
+	if err = repo.AddRedemption(redemption, tx); err != nil {
+		return discount, redemptionID, oops.Wrap(err, "Error when adding coupon redemption.")
+	}
+
+	return discount, *redemption.ID, nil
+}
+
+// Release frees the use of a coupon whose order failed or was
+// refunded, so it no longer counts to the coupon limits
+func Release(redemptionID uint, tx *gorm.DB) error {
+	var repo domain.ICoupon = &repository.Repository{}
+
+	if err := repo.ReleaseRedemption(redemptionID, tx); err != nil {
+		return oops.Wrap(err, "Error when releasing coupon redemption.")
+	}
+
+	return nil
+}
+
+// findCoupon fetches a coupon by its code, ignoring its case
+func findCoupon(code string, repo domain.ICoupon, db *gorm.DB) (*domain.Coupon, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	data := &domain.Coupon{Code: &code}
+
+	if err := repo.GetByCode(data, db); err != nil {
+		if oops.IsNotFound(err) {
+			return nil, oops.NewErr("Cupom inválido")
+		}
+		return nil, oops.Wrap(err, "Error when fetching coupon.")
+	}
+
+	return data, nil
+}
+
+// apply checks whether a coupon can be used on a class
+// and computes the discount it gives on the class price
+func apply(data *domain.Coupon, class *classDomain.Class, userID *uint, repo domain.ICoupon, db *gorm.DB) (discount utils.Money, err error) {
+	now := time.Now()
+
+	if data.StartsAt != nil && now.Before(*data.StartsAt) {
+		return discount, oops.NewErr("Cupom ainda não está disponível")
+	}
+
+	if data.EndsAt != nil && !now.Before(*data.EndsAt) {
+		return discount, oops.NewErr("Cupom expirado")
+	}
+
+	if !applies(data, class) {
+		return discount, oops.NewErr("Cupom não se aplica a esta turma")
+	}
+
+	if data.MaxUses != nil {
+		uses, err := repo.CountRedemptions(*data.ID, nil, db)
+		if err != nil {
+			return discount, oops.Wrap(err, "Error when counting coupon redemptions.")
+		}
+		if uses >= *data.MaxUses {
+			return discount, oops.NewErr("Cupom esgotado")
+		}
+	}
+
+	if data.MaxUsesPerUser != nil && userID != nil {
+		uses, err := repo.CountRedemptions(*data.ID, userID, db)
+		if err != nil {
+			return discount, oops.Wrap(err, "Error when counting coupon redemptions.")
+		}
+		if uses >= *data.MaxUsesPerUser {
+			return discount, oops.NewErr("Limite de uso do cupom atingido para este usuário")
+		}
+	}
+
+	price := classApp.Price(class)
+
+	if *data.Kind == domain.KindPercentage {
+		return price.Percent(*data.Percentage), nil
+	}
+
+	if *data.Currency != price.Currency {
+		return discount, oops.NewErr("Cupom não se aplica à moeda da turma")
+	}
+
+	// a fixed discount never makes the price negative
+	return price.Min(utils.Money{Amount: *data.Amount, Currency: *data.Currency})
+}
+
+// applies reports whether the restrictions of a coupon allow the class
+func applies(data *domain.Coupon, class *classDomain.Class) bool {
+	if len(data.Restrictions) == 0 {
+		return true
+	}
+
+	for _, r := range data.Restrictions {
+		if r.ClassID != nil && *r.ClassID == *class.ID {
+			return true
+		}
+		if r.TeacherID != nil && *r.TeacherID == *class.TeacherID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseCoupon validates a coupon received from the client
+func parseCoupon(in *INCoupon) (data *domain.Coupon, err error) {
+	data = &domain.Coupon{}
+
+	if err = utils.ConvertStruct(in, data); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	code := strings.ToUpper(*in.Code)
+	data.Code = &code
+
+	switch *in.Kind {
+	case domain.KindPercentage:
+		if in.Percentage == nil {
+			return nil, oops.NewErr("Campo percentage é obrigatório para cupons percentuais")
+		}
+		if in.Amount != nil {
+			return nil, oops.NewErr("Campo amount não é permitido para cupons percentuais")
+		}
+	case domain.KindFixed:
+		if in.Amount == nil {
+			return nil, oops.NewErr("Campo amount é obrigatório para cupons de valor fixo")
+		}
+		if in.Percentage != nil {
+			return nil, oops.NewErr("Campo percentage não é permitido para cupons de valor fixo")
+		}
+
+		amount, err := classApp.ToMoney(in.Amount)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+		if amount.IsZero() {
+			return nil, oops.NewErr("Campo amount deve ser maior que 0")
+		}
+
+		data.Amount, data.Currency = &amount.Amount, &amount.Currency
+	}
+
+	if in.StartsAt != nil {
+		if data.StartsAt, err = utils.ParseDateTime(*in.StartsAt); err != nil {
+			return nil, oops.NewErr("Campo starts_at não contém uma data válida")
+		}
+	}
+
+	if in.EndsAt != nil {
+		if data.EndsAt, err = utils.ParseDateTime(*in.EndsAt); err != nil {
+			return nil, oops.NewErr("Campo ends_at não contém uma data válida")
+		}
+	}
+
+	if data.StartsAt != nil && data.EndsAt != nil && !data.EndsAt.After(*data.StartsAt) {
+		return nil, oops.NewErr("Data de término do cupom deve ser posterior à data de início")
+	}
+
+	for i := range in.ClassIDs {
+		data.Restrictions = append(data.Restrictions, domain.Restriction{ClassID: &in.ClassIDs[i]})
+	}
+
+	for i := range in.TeacherIDs {
+		data.Restrictions = append(data.Restrictions, domain.Restriction{TeacherID: &in.TeacherIDs[i]})
+	}
+
+	return data, nil
+}
+
+func toOUTCoupon(data *domain.Coupon, locale string) (out *OUTCoupon, err error) {
+	out = &OUTCoupon{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	if data.Amount != nil && data.Currency != nil {
+		out.Amount = classApp.ToOUTMoney(utils.Money{Amount: *data.Amount, Currency: *data.Currency}, locale)
+	}
+
+	for _, r := range data.Restrictions {
+		if r.ClassID != nil {
+			out.ClassIDs = append(out.ClassIDs, *r.ClassID)
+		}
+		if r.TeacherID != nil {
+			out.TeacherIDs = append(out.TeacherIDs, *r.TeacherID)
+		}
+	}
+
+	return out, nil
+}
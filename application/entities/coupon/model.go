@@ -0,0 +1,55 @@
+package coupon
+
+import (
+	classApp "go-api/application/entities/class"
+	"time"
+)
+
+// INCoupon models a coupon for insertion and update. Percentage coupons
+// take a percentage from 1 to 100 and fixed ones take an amount.
+// Dates are accepted both in ISO 8601 and dd/mm/yyyy hh:mm:ss formats
+type INCoupon struct {
+	Code           *string           `json:"code" binding:"required,min=3,max=32,alphanum" conversor:"code"`
+	Kind           *string           `json:"kind" binding:"required,oneof=percentage fixed" conversor:"kind"`
+	Percentage     *int64            `json:"percentage" binding:"omitempty,gt=0,max=100" conversor:"percentage"`
+	Amount         *classApp.INMoney `json:"amount"`
+	StartsAt       *string           `json:"starts_at"`
+	EndsAt         *string           `json:"ends_at"`
+	MaxUses        *int64            `json:"max_uses" binding:"omitempty,gt=0" conversor:"max_uses"`
+	MaxUsesPerUser *int64            `json:"max_uses_per_user" binding:"omitempty,gt=0" conversor:"max_uses_per_user"`
+	ClassIDs       []uint            `json:"class_ids"`
+	TeacherIDs     []uint            `json:"teacher_ids"`
+}
+
+// OUTCoupon models a coupon for retrieval
+type OUTCoupon struct {
+	ID             *uint              `json:"id,omitempty" conversor:"id"`
+	Code           *string            `json:"code,omitempty" conversor:"code"`
+	Kind           *string            `json:"kind,omitempty" conversor:"kind"`
+	Percentage     *int64             `json:"percentage,omitempty" conversor:"percentage"`
+	Amount         *classApp.OUTMoney `json:"amount,omitempty"`
+	StartsAt       *time.Time         `json:"starts_at,omitempty" conversor:"starts_at"`
+	EndsAt         *time.Time         `json:"ends_at,omitempty" conversor:"ends_at"`
+	MaxUses        *int64             `json:"max_uses,omitempty" conversor:"max_uses"`
+	MaxUsesPerUser *int64             `json:"max_uses_per_user,omitempty" conversor:"max_uses_per_user"`
+	ClassIDs       []uint             `json:"class_ids,omitempty"`
+	TeacherIDs     []uint             `json:"teacher_ids,omitempty"`
+	CreatedAt      *time.Time         `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt      *time.Time         `json:"updated_at,omitempty" conversor:"updated_at"`
+}
+
+// OUTList models a list of coupons
+type OUTList struct {
+	Data []OUTCoupon
+}
+
+// OUTQuote models the price of a class after applying a coupon.
+// MaxUsesPerUser isn't checked by quotes, only on checkout
+type OUTQuote struct {
+	ClassID        uint               `json:"class_id"`
+	Code           string             `json:"code"`
+	Price          *classApp.OUTMoney `json:"price"`
+	Discount       *classApp.OUTMoney `json:"discount"`
+	Total          *classApp.OUTMoney `json:"total"`
+	MaxUsesPerUser *int64             `json:"max_uses_per_user,omitempty"`
+}
@@ -0,0 +1,336 @@
+package enrollment
+
+import (
+	"go-api/database"
+	bookingDomain "go-api/domain/entities/booking"
+	classDomain "go-api/domain/entities/class"
+	domain "go-api/domain/entities/enrollment"
+	userDomain "go-api/domain/entities/user"
+	bookingRepository "go-api/infrastructure/persistance/booking"
+	classRepository "go-api/infrastructure/persistance/class"
+	repository "go-api/infrastructure/persistance/enrollment"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CancelHook is called inside the transaction canceling an enrollment,
+// receiving the enrollment with the status it had before
+type CancelHook func(*domain.Enrollment, *gorm.DB) error
+
+// cancelHooks are the functions called when an enrollment is canceled
+var cancelHooks []CancelHook
+
+// OnCancel registers a function to be called when an enrollment is canceled,
+// letting other modules react to it. An error aborts the cancellation
+func OnCancel(hook CancelHook) {
+	cancelHooks = append(cancelHooks, hook)
+}
+
+// PromoteHook is called inside the transaction promoting an enrollment
+// of the waitlist, receiving the enrollment with its new status
+type PromoteHook func(*domain.Enrollment, *gorm.DB) error
+
+// promoteHooks are the functions called when an enrollment is promoted
+var promoteHooks []PromoteHook
+
+// OnPromote registers a function to be called when a student of the waitlist
+// takes a seat, letting other modules react to it. An error aborts the promotion
+func OnPromote(hook PromoteHook) {
+	promoteHooks = append(promoteHooks, hook)
+}
+
+// SeatsHook is called inside the transaction changing the seats taken in a
+// class, receiving the locked class and how many students are now enrolled
+type SeatsHook func(*classDomain.Class, int64, *gorm.DB) error
+
+// seatsHooks are the functions called when the seats taken in a class change
+var seatsHooks []SeatsHook
+
+// OnSeatsChange registers a function to be called when students take or free
+// seats of a class, letting other modules react to it. An error aborts the change
+func OnSeatsChange(hook SeatsHook) {
+	seatsHooks = append(seatsHooks, hook)
+}
+
+// Enroll do the business logic of enrolling a student in a class. When the
+// class is full the student joins the waitlist instead. The class row is
+// locked so concurrent enrollments cannot overbook it
+func Enroll(classID uint, in *INEnrollment) (out *OUTEnrollment, err error) {
+	var (
+		classRepo   classDomain.IClass     = &classRepository.Repository{}
+		userRepo    userDomain.IUser       = &userRepository.Repository{}
+		bookingRepo bookingDomain.IBooking = &bookingRepository.Repository{}
+		repo        domain.IEnrollment     = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err = classRepo.Lock(class, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = userRepo.Get(&userDomain.User{ID: in.StudentID}, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching student.")
+	}
+
+	if *class.TeacherID == *in.StudentID {
+		return nil, oops.NewErr("Professor não pode se inscrever na própria turma")
+	}
+
+	if *class.Status != classDomain.StatusPublished && *class.Status != classDomain.StatusFull {
+		return nil, oops.NewErr("Turma não está aberta para inscrições")
+	}
+
+	// the seat of a private lesson belongs to the student who booked it
+	booked, err := bookingRepo.IsBooked(classID, tx)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when checking booking.")
+	}
+
+	if booked {
+		return nil, oops.NewErr("Aula particular não aceita inscrições")
+	}
+
+	current := &domain.Enrollment{ClassID: &classID, StudentID: in.StudentID}
+
+	if err = repo.GetActive(current, tx); err == nil {
+		return nil, oops.NewConflict("Aluno já está inscrito nesta turma", nil)
+	} else if !oops.IsNotFound(err) {
+		return nil, oops.Wrap(err, "Error when fetching enrollment.")
+	}
+
+	enrolled, err := repo.Count(classID, domain.StatusEnrolled, tx)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when counting enrollments.")
+	}
+
+	now := time.Now()
+	status := domain.StatusEnrolled
+	data := &domain.Enrollment{ClassID: &classID, StudentID: in.StudentID, Status: &status}
+
+	if class.Capacity != nil && enrolled >= *class.Capacity {
+		status = domain.StatusWaitlisted
+	} else {
+		data.EnrolledAt = &now
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when adding new enrollment.")
+	}
+
+	if out, err = toOUTEnrollment(data); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	if status == domain.StatusWaitlisted {
+		waitlisted, err := repo.Count(classID, domain.StatusWaitlisted, tx)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when counting enrollments.")
+		}
+		out.Position = &waitlisted
+	} else if err = seatsChanged(class, enrolled+1, tx); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return out, nil
+}
+
+// Unenroll do the business logic of removing a student from a class or from
+// its waitlist. A freed seat is given to the next student on the waitlist
+func Unenroll(classID, studentID uint) (err error) {
+	var (
+		classRepo classDomain.IClass = &classRepository.Repository{}
+		repo      domain.IEnrollment = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err = classRepo.Lock(class, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	data := &domain.Enrollment{ClassID: &classID, StudentID: &studentID}
+
+	if err = repo.GetActive(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching enrollment.")
+	}
+
+	now := time.Now()
+	status := domain.StatusCanceled
+
+	if err = repo.Update(&domain.Enrollment{ID: data.ID, Status: &status, CanceledAt: &now}, tx); err != nil {
+		return oops.Wrap(err, "Error when canceling enrollment.")
+	}
+
+	for _, hook := range cancelHooks {
+		if err = hook(data, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = PromoteWaitlist(class, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// PromoteWaitlist gives the free seats of a class to the waitlisted students
+// in arrival order, then reports the seats taken. The class must have been
+// locked by the transaction
+func PromoteWaitlist(class *classDomain.Class, tx *gorm.DB) error {
+	var repo domain.IEnrollment = &repository.Repository{}
+
+	enrolled, err := repo.Count(*class.ID, domain.StatusEnrolled, tx)
+	if err != nil {
+		return oops.Wrap(err, "Error when counting enrollments.")
+	}
+
+	for class.Capacity == nil || enrolled < *class.Capacity {
+		next := &domain.Enrollment{}
+
+		if err = repo.NextWaitlisted(next, *class.ID, tx); err != nil {
+			return oops.Wrap(err, "Error when fetching waitlist.")
+		}
+
+		if next.ID == nil {
+			break
+		}
+
+		now := time.Now()
+		status := domain.StatusEnrolled
+
+		if err = repo.Update(&domain.Enrollment{ID: next.ID, Status: &status, EnrolledAt: &now}, tx); err != nil {
+			return oops.Wrap(err, "Error when promoting enrollment.")
+		}
+
+		next.Status, next.EnrolledAt = &status, &now
+
+		for _, hook := range promoteHooks {
+			if err = hook(next, tx); err != nil {
+				return err
+			}
+		}
+
+		enrolled++
+	}
+
+	return seatsChanged(class, enrolled, tx)
+}
+
+// seatsChanged calls the hooks registered for changes of the seats taken
+func seatsChanged(class *classDomain.Class, enrolled int64, tx *gorm.DB) error {
+	for _, hook := range seatsHooks {
+		if err := hook(class, enrolled, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByClass do the business logic of listing the active enrollments of a
+// class, the seated students first and then the waitlist in order
+func GetByClass(classID uint) (out *OUTList, err error) {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = classRepo.Get(&classDomain.Class{ID: &classID}, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return list(&domain.Filter{
+		ClassID: &classID,
+		Status:  []string{domain.StatusEnrolled, domain.StatusWaitlisted},
+	}, db)
+}
+
+// GetByStudent do the business logic of listing every enrollment of a student
+func GetByStudent(studentID uint) (out *OUTList, err error) {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &studentID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	return list(&domain.Filter{StudentID: &studentID}, db)
+}
+
+func list(filter *domain.Filter, db *gorm.DB) (out *OUTList, err error) {
+	var repo domain.IEnrollment = &repository.Repository{}
+
+	data := []domain.Enrollment{}
+
+	if err = repo.GetAll(&data, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing enrollments.")
+	}
+
+	out = &OUTList{Data: []OUTEnrollment{}}
+
+	// waitlisted enrollments come in arrival order, so the
+	// position is given by counting them per class
+	positions := map[uint]int64{}
+	waitlist := []OUTEnrollment{}
+
+	for i := range data {
+		item, err := toOUTEnrollment(&data[i])
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		if *data[i].Status == domain.StatusWaitlisted {
+			if filter.StudentID == nil {
+				positions[*data[i].ClassID]++
+				position := positions[*data[i].ClassID]
+				item.Position = &position
+			}
+			waitlist = append(waitlist, *item)
+			continue
+		}
+
+		out.Data = append(out.Data, *item)
+	}
+
+	out.Data = append(out.Data, waitlist...)
+
+	return out, nil
+}
+
+func toOUTEnrollment(data *domain.Enrollment) (out *OUTEnrollment, err error) {
+	out = &OUTEnrollment{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
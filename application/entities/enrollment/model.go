@@ -0,0 +1,29 @@
+package enrollment
+
+import (
+	"time"
+)
+
+// INEnrollment models the enrollment of a student in a class
+type INEnrollment struct {
+	StudentID *uint `json:"student_id" binding:"required" conversor:"student_id"`
+}
+
+// OUTEnrollment models an enrollment for retrieval. Position is
+// the place of the student in the waitlist, starting at 1
+type OUTEnrollment struct {
+	ID         *uint      `json:"id,omitempty" conversor:"id"`
+	ClassID    *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	StudentID  *uint      `json:"student_id,omitempty" conversor:"student_id"`
+	Status     *string    `json:"status,omitempty" conversor:"status"`
+	Position   *int64     `json:"position,omitempty"`
+	EnrolledAt *time.Time `json:"enrolled_at,omitempty" conversor:"enrolled_at"`
+	CanceledAt *time.Time `json:"canceled_at,omitempty" conversor:"canceled_at"`
+	CreatedAt  *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
+}
+
+// OUTList models a list of enrollments
+type OUTList struct {
+	Data []OUTEnrollment
+}
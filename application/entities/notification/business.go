@@ -0,0 +1,229 @@
+package notification
+
+import (
+	"go-api/database"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	domain "go-api/domain/entities/notification"
+	userDomain "go-api/domain/entities/user"
+	classRepository "go-api/infrastructure/persistance/class"
+	repository "go-api/infrastructure/persistance/notification"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultLimit is the number of notifications listed when the client doesn't say
+	defaultLimit = 20
+	// streamBatch is the maximum number of notifications fetched at once by a stream
+	streamBatch = 100
+	// rescanWindow is how long unread notifications below the newest
+	// one pushed are fetched again by the streams
+	rescanWindow = time.Minute
+)
+
+// Send stores a notification inside the given transaction. Streams
+// connected to any instance receive it once the transaction commits
+func Send(data *domain.Notification, tx *gorm.DB) error {
+	var repo domain.INotification = &repository.Repository{}
+
+	if err := repo.Add(data, tx); err != nil {
+		return oops.Wrap(err, "Error when adding new notification.")
+	}
+
+	return nil
+}
+
+// SeatOpened notifies a student of the waitlist who took a seat of a class
+func SeatOpened(enrollment *enrollmentDomain.Enrollment, tx *gorm.DB) error {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	class := &classDomain.Class{ID: enrollment.ClassID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	kind := domain.KindSeatOpened
+	title := "Vaga liberada"
+	body := "Uma vaga foi liberada e sua inscrição na turma " + *class.Name + " está confirmada"
+
+	return Send(&domain.Notification{
+		UserID:  enrollment.StudentID,
+		Kind:    &kind,
+		Title:   &title,
+		Body:    &body,
+		ClassID: enrollment.ClassID,
+	}, tx)
+}
+
+// GetAll do the business logic of listing the notifications of an
+// user, the newest first, along with how many are still unread
+func GetAll(in *INFilter) (out *OUTList, err error) {
+	var repo domain.INotification = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = checkUser(*in.UserID, db); err != nil {
+		return nil, err
+	}
+
+	filter := &domain.Filter{UserID: *in.UserID, Unread: in.Unread, Limit: defaultLimit}
+
+	if in.Limit != nil {
+		filter.Limit = *in.Limit
+	}
+
+	if in.Offset != nil {
+		filter.Offset = *in.Offset
+	}
+
+	if out, err = list(filter, db); err != nil {
+		return nil, err
+	}
+
+	if out.Unread, err = repo.CountUnread(*in.UserID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when counting unread notifications.")
+	}
+
+	return out, nil
+}
+
+// MarkRead do the business logic of marking notifications of an user as read
+func MarkRead(in *INRead) (err error) {
+	var repo domain.INotification = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = checkUser(*in.UserID, tx); err != nil {
+		return err
+	}
+
+	if err = repo.MarkRead(*in.UserID, in.IDs, tx); err != nil {
+		return oops.Wrap(err, "Error when marking notifications as read.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// StreamCursor do the business logic of starting the stream of an user,
+// returning the cursor after which notifications are pushed. A reconnecting
+// client resumes from the last event ID it received, otherwise only the
+// notifications committed from now on are pushed
+func StreamCursor(userID uint, lastEventID string) (cursor *Cursor, err error) {
+	var repo domain.INotification = &repository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = checkUser(userID, db); err != nil {
+		return nil, err
+	}
+
+	cursor = &Cursor{userID: userID, sent: map[uint]time.Time{}}
+
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return nil, oops.NewErr("Cabeçalho Last-Event-ID inválido")
+		}
+
+		// what was sent before reconnecting is unknown, so the unread
+		// notifications of the rescan window may be pushed again
+		cursor.after = uint(id)
+		return cursor, nil
+	}
+
+	if cursor.after, err = repo.LastID(userID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching last notification.")
+	}
+
+	// the pending notifications already committed are not new to the client
+	if _, err = cursor.Next(); err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// Cursor tracks the notifications pushed to a stream. Besides the ones
+// after the newest pushed, the unread notifications created within the
+// rescan window are fetched again, as a transaction may commit a lower
+// ID after a higher one was pushed. Clients should ignore repeated IDs
+type Cursor struct {
+	userID uint
+	after  uint
+	sent   map[uint]time.Time
+}
+
+// Next do the business logic of listing the notifications
+// not pushed yet to the stream of an user, the oldest first
+func (c *Cursor) Next() (out *OUTList, err error) {
+	since := time.Now().Add(-rescanWindow)
+
+	filter := &domain.Filter{UserID: c.userID, AfterID: &c.after, PendingSince: &since, Limit: streamBatch}
+
+	for id, createdAt := range c.sent {
+		if createdAt.Before(since) {
+			delete(c.sent, id)
+			continue
+		}
+		filter.Sent = append(filter.Sent, id)
+	}
+
+	if out, err = list(filter, database.GetDBSession()); err != nil {
+		return nil, err
+	}
+
+	for _, item := range out.Data {
+		c.sent[*item.ID] = *item.CreatedAt
+		if *item.ID > c.after {
+			c.after = *item.ID
+		}
+	}
+
+	return out, nil
+}
+
+func checkUser(userID uint, db *gorm.DB) error {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	if err := userRepo.Get(&userDomain.User{ID: &userID}, db); err != nil {
+		return oops.Wrap(err, "Error when fetching user.")
+	}
+
+	return nil
+}
+
+func list(filter *domain.Filter, db *gorm.DB) (out *OUTList, err error) {
+	var repo domain.INotification = &repository.Repository{}
+
+	data := []domain.Notification{}
+
+	if err = repo.GetAll(&data, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing notifications.")
+	}
+
+	out = &OUTList{Data: make([]OUTNotification, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return out, nil
+}
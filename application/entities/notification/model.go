@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"time"
+)
+
+// INFilter models the query string listing the notifications of an user
+type INFilter struct {
+	UserID *uint `form:"user_id" binding:"required"`
+	Unread bool  `form:"unread"`
+	Limit  *int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset *int  `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// INRead models notifications of an user being marked as read,
+// every unread notification of the user when IDs is empty
+type INRead struct {
+	UserID *uint  `json:"user_id" binding:"required"`
+	IDs    []uint `json:"ids"`
+}
+
+// INStream models the query string of the notification stream of an user
+type INStream struct {
+	UserID *uint `form:"user_id" binding:"required"`
+}
+
+// OUTNotification models a notification for retrieval
+type OUTNotification struct {
+	ID        *uint      `json:"id,omitempty" conversor:"id"`
+	UserID    *uint      `json:"user_id,omitempty" conversor:"user_id"`
+	Kind      *string    `json:"kind,omitempty" conversor:"kind"`
+	Title     *string    `json:"title,omitempty" conversor:"title"`
+	Body      *string    `json:"body,omitempty" conversor:"body"`
+	ClassID   *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	ReadAt    *time.Time `json:"read_at,omitempty" conversor:"read_at"`
+	CreatedAt *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTList models a page of notifications along with
+// how many notifications of the user are still unread
+type OUTList struct {
+	Data   []OUTNotification
+	Unread int64 `json:"unread"`
+}
@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"go-api/database"
+	domain "go-api/domain/entities/notification"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// subscribers holds the signal channels of the streams
+// connected to this instance, grouped by user
+var subscribers = struct {
+	sync.Mutex
+	users map[uint]map[chan struct{}]bool
+}{users: map[uint]map[chan struct{}]bool{}}
+
+// Subscribe registers a stream of an user, returning the channel signaled
+// when new notifications of the user are committed and the function that
+// unregisters it. Signals are coalesced, so after each one every
+// notification newer than the last one sent should be fetched
+func Subscribe(userID uint) (<-chan struct{}, func()) {
+	signal := make(chan struct{}, 1)
+
+	subscribers.Lock()
+	if subscribers.users[userID] == nil {
+		subscribers.users[userID] = map[chan struct{}]bool{}
+	}
+	subscribers.users[userID][signal] = true
+	subscribers.Unlock()
+
+	return signal, func() {
+		subscribers.Lock()
+		delete(subscribers.users[userID], signal)
+		if len(subscribers.users[userID]) == 0 {
+			delete(subscribers.users, userID)
+		}
+		subscribers.Unlock()
+	}
+}
+
+// RunPublisher feeds the connected streams with the notifications committed
+// by any instance, which the database announces on the notifications channel
+func RunPublisher() {
+	for {
+		if err := database.Listen(domain.Channel, publish); err != nil {
+			log.Println("Error when listening to notifications:", err)
+		}
+
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// publish signals the streams of the user in the payload,
+// or every stream when the payload is empty
+func publish(payload string) {
+	subscribers.Lock()
+	defer subscribers.Unlock()
+
+	if payload == "" {
+		for _, signals := range subscribers.users {
+			signalAll(signals)
+		}
+		return
+	}
+
+	userID, err := strconv.ParseUint(payload, 10, 64)
+	if err != nil {
+		log.Printf("Invalid notification payload %q\n", payload)
+		return
+	}
+
+	signalAll(subscribers.users[uint(userID)])
+}
+
+func signalAll(signals map[chan struct{}]bool) {
+	for signal := range signals {
+		select {
+		case signal <- struct{}{}:
+		default:
+			// a signal is already pending
+		}
+	}
+}
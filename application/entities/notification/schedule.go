@@ -0,0 +1,123 @@
+package notification
+
+import (
+	"fmt"
+	classApp "go-api/application/entities/class"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	domain "go-api/domain/entities/notification"
+	classRepository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	"go-api/oops"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dateLayout formats the dates written in notifications
+const dateLayout = "02/01/2006 às 15:04"
+
+// SchedulesCanceled tells the students holding a seat in a class that its
+// upcoming schedules were canceled. Meant to be registered as a schedule cancel hook
+func SchedulesCanceled(cancel *classApp.ScheduleCancel, tx *gorm.DB) error {
+	now := time.Now()
+
+	var upcoming []classDomain.Schedule
+	for _, s := range cancel.Schedules {
+		if s.End.After(now) {
+			upcoming = append(upcoming, s)
+		}
+	}
+
+	if len(upcoming) == 0 {
+		return nil
+	}
+
+	class, loc, err := classInfo(cancel.ClassID, tx)
+	if err != nil {
+		return err
+	}
+
+	title := "Aula cancelada"
+	body := fmt.Sprintf("A aula de %s do dia %s (%s) foi cancelada",
+		*class.Name, upcoming[0].Start.In(loc).Format(dateLayout), loc)
+
+	if len(upcoming) > 1 {
+		title = "Aulas canceladas"
+		body = fmt.Sprintf("%d aulas de %s foram canceladas a partir do dia %s (%s)",
+			len(upcoming), *class.Name, upcoming[0].Start.In(loc).Format(dateLayout), loc)
+	}
+
+	if cancel.Reason != "" {
+		body += ". Motivo: " + cancel.Reason
+	}
+
+	return notifyStudents(cancel.ClassID, domain.KindScheduleCanceled, title, body, tx)
+}
+
+// Rescheduled tells the students holding a seat in a class that one of
+// its schedules was moved. Meant to be registered as a reschedule hook
+func Rescheduled(change *classApp.ScheduleChange, tx *gorm.DB) error {
+	class, loc, err := classInfo(change.ClassID, tx)
+	if err != nil {
+		return err
+	}
+
+	title := "Aula remarcada"
+	body := fmt.Sprintf("A aula de %s do dia %s foi remarcada para o dia %s (%s)", *class.Name,
+		change.Previous.Start.In(loc).Format(dateLayout), change.Current.Start.In(loc).Format(dateLayout), loc)
+
+	if change.Following > 0 {
+		body += fmt.Sprintf(", assim como as %d aulas seguintes", change.Following)
+	}
+
+	if change.Reason != "" {
+		body += ". Motivo: " + change.Reason
+	}
+
+	return notifyStudents(change.ClassID, domain.KindRescheduled, title, body, tx)
+}
+
+// classInfo fetches a class along with the time zone its dates are written on
+func classInfo(classID uint, tx *gorm.DB) (*classDomain.Class, *time.Location, error) {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	loc, err := classApp.Location(class)
+	if err != nil {
+		return nil, nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	return class, loc, nil
+}
+
+// notifyStudents sends the same notification to every student holding a seat in a class
+func notifyStudents(classID uint, kind, title, body string, tx *gorm.DB) error {
+	var enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+
+	enrollments := []enrollmentDomain.Enrollment{}
+	filter := &enrollmentDomain.Filter{ClassID: &classID, Status: []string{enrollmentDomain.StatusEnrolled}}
+
+	if err := enrollmentRepo.GetAll(&enrollments, filter, tx); err != nil {
+		return oops.Wrap(err, "Error when listing enrollments.")
+	}
+
+	for _, e := range enrollments {
+		if err := Send(&domain.Notification{
+			UserID:  e.StudentID,
+			Kind:    &kind,
+			Title:   &title,
+			Body:    &body,
+			ClassID: &classID,
+		}, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
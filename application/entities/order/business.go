@@ -0,0 +1,457 @@
+package order
+
+import (
+	"fmt"
+	classApp "go-api/application/entities/class"
+	couponApp "go-api/application/entities/coupon"
+	"go-api/database"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	domain "go-api/domain/entities/order"
+	userDomain "go-api/domain/entities/user"
+	"go-api/infrastructure/payment"
+	classRepository "go-api/infrastructure/persistance/class"
+	enrollmentRepository "go-api/infrastructure/persistance/enrollment"
+	repository "go-api/infrastructure/persistance/order"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// provider is the payment gateway used to charge orders
+var provider payment.PaymentProvider = payment.NewFake("")
+
+// transitions lists the statuses an order may move to from each status
+var transitions = map[string][]string{
+	domain.StatusPending: {domain.StatusPaid, domain.StatusFailed},
+	domain.StatusPaid:    {domain.StatusRefunded},
+}
+
+// feeRate is the platform fee, in basis points, charged on the new orders
+var feeRate int64
+
+// SetProvider sets the payment provider used by checkouts and webhooks
+func SetProvider(p payment.PaymentProvider) {
+	provider = p
+}
+
+// SetPlatformFee sets the platform fee, in basis points, charged on the new orders
+func SetPlatformFee(rate int64) error {
+	if rate < 0 || rate > 10000 {
+		return fmt.Errorf("platform fee of %d basis points out of range", rate)
+	}
+	feeRate = rate
+	return nil
+}
+
+// Checkout do the business logic of charging a student for an enrollment.
+// A pending order of the enrollment is returned instead of creating a new one.
+// The order is committed before its payment is created on the provider, so
+// no lock is held during the request and a failed request is retried on the
+// next checkout of the same order
+func Checkout(classID uint, in *INCheckout, locale string) (out *OUTOrder, err error) {
+	data, class, err := placeOrder(classID, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if *data.Status == domain.StatusPending && data.ExternalID == nil {
+		if err = createPayment(data, *class.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if out, err = toOUTOrder(data, locale); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// placeOrder inserts the pending order of an enrollment, redeeming its coupon,
+// or returns the pending order already placed. Free orders are paid right away
+func placeOrder(classID uint, in *INCheckout) (data *domain.Order, class *classDomain.Class, err error) {
+	var (
+		classRepo      classDomain.IClass           = &classRepository.Repository{}
+		enrollmentRepo enrollmentDomain.IEnrollment = &enrollmentRepository.Repository{}
+		repo           domain.IOrder                = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	class = &classDomain.Class{ID: &classID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	enrollment := &enrollmentDomain.Enrollment{ClassID: &classID, StudentID: in.StudentID}
+
+	if err = enrollmentRepo.GetActive(enrollment, tx); err != nil {
+		if oops.IsNotFound(err) {
+			return nil, nil, oops.NewErr("Aluno não está inscrito nesta turma")
+		}
+		return nil, nil, oops.Wrap(err, "Error when fetching enrollment.")
+	}
+
+	if *enrollment.Status != enrollmentDomain.StatusEnrolled {
+		return nil, nil, oops.NewErr("Aluno ainda está na lista de espera desta turma")
+	}
+
+	data = &domain.Order{EnrollmentID: enrollment.ID}
+
+	if err = repo.GetOpen(data, tx); err == nil {
+		if *data.Status == domain.StatusPaid {
+			return nil, nil, oops.NewConflict("Inscrição já foi paga", nil)
+		}
+		return data, class, nil
+	} else if !oops.IsNotFound(err) {
+		return nil, nil, oops.Wrap(err, "Error when fetching order.")
+	}
+
+	price := classApp.Price(class)
+	discount := utils.Money{Currency: price.Currency}
+
+	var redemptionID *uint
+
+	if in.CouponCode != nil && *in.CouponCode != "" {
+		var id uint
+		if discount, id, err = couponApp.Redeem(*in.CouponCode, class, *in.StudentID, tx); err != nil {
+			return nil, nil, err
+		}
+		redemptionID = &id
+	}
+
+	total, err := price.Sub(discount)
+	if err != nil {
+		return nil, nil, oops.Wrap(err, "Error when applying discount.")
+	}
+
+	status, name, fee := domain.StatusPending, provider.Name(), feeRate
+
+	data = &domain.Order{
+		EnrollmentID: enrollment.ID,
+		ClassID:      &classID,
+		UserID:       in.StudentID,
+		Status:       &status,
+		Price:        &price.Amount,
+		Discount:     &discount.Amount,
+		Amount:       &total.Amount,
+		Currency:     &total.Currency,
+		FeeRate:      &fee,
+		Provider:     &name,
+		RedemptionID: redemptionID,
+	}
+
+	if in.CouponCode != nil && *in.CouponCode != "" {
+		code := strings.ToUpper(strings.TrimSpace(*in.CouponCode))
+		data.CouponCode = &code
+	}
+
+	if err = repo.Add(data, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when adding new order.")
+	}
+
+	// free enrollments are settled without going through the provider
+	if total.IsZero() {
+		if _, err = transition(data, domain.StatusPaid, tx); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return data, class, nil
+}
+
+// createPayment registers the payment of a committed pending order on the
+// provider and stores its reference. The idempotency key depends only on the
+// order, so retries get back the payment created before instead of a new one
+func createPayment(data *domain.Order, description string) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	charge := &payment.Charge{
+		Reference:      fmt.Sprintf("order-%d", *data.ID),
+		IdempotencyKey: fmt.Sprintf("order-%d-payment", *data.ID),
+		Amount:         utils.Money{Amount: *data.Amount, Currency: *data.Currency},
+		Description:    description,
+	}
+
+	created, err := provider.CreatePayment(charge)
+	if err != nil {
+		return oops.Wrap(err, "Error when creating payment.")
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	// a concurrent checkout of the same order may have stored it already
+	if err = repo.Lock(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching order.")
+	}
+
+	if data.ExternalID == nil {
+		data.ExternalID, data.CheckoutURL = &created.ExternalID, &created.CheckoutURL
+
+		if err = repo.Update(&domain.Order{ID: data.ID, ExternalID: data.ExternalID, CheckoutURL: data.CheckoutURL}, tx); err != nil {
+			return oops.Wrap(err, "Error when updating order.")
+		}
+
+		// some providers capture the payment right away
+		if created.Status != "" {
+			if _, err = transition(data, created.Status, tx); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// HandleWebhook do the business logic of applying a payment status update
+// notified by the provider. Redelivered events are acknowledged without effect
+func HandleWebhook(payload []byte, signature string) (err error) {
+	var repo domain.IOrder = &repository.Repository{}
+
+	event, err := provider.ParseWebhook(payload, signature)
+	if err == payment.ErrInvalidSignature {
+		return oops.Err(&oops.ErrInvalidSignature)
+	} else if err != nil {
+		return oops.NewErr("Evento de pagamento inválido")
+	}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	name := provider.Name()
+	data := &domain.Order{Provider: &name, ExternalID: &event.ExternalID}
+
+	// the order lock serializes concurrent deliveries of the same event
+	if err = repo.LockByExternalID(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching order.")
+	}
+
+	received, err := repo.HasEvent(name, event.ID, tx)
+	if err != nil {
+		return oops.Wrap(err, "Error when fetching order events.")
+	}
+
+	if received {
+		return nil
+	}
+
+	applied := false
+
+	switch {
+	case event.Status == domain.StatusRefunded && *data.Status == domain.StatusPaid:
+		// the provider refunded whatever was still due on its side
+		remaining := utils.Money{Amount: *data.Amount - *data.Refunded, Currency: *data.Currency}
+		if err = recordRefund(data, remaining, "Estorno pelo provedor de pagamento", true, tx); err != nil {
+			return err
+		}
+		applied = *data.Status == domain.StatusRefunded
+
+	case event.Status == domain.StatusPaid && *data.Status == domain.StatusFailed:
+		if err = refundLatePayment(data, tx); err != nil {
+			return err
+		}
+		applied = true
+
+	default:
+		if applied, err = transition(data, event.Status, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = repo.AddEvent(&domain.Event{
+		OrderID:  data.ID,
+		Provider: &name,
+		EventID:  &event.ID,
+		Status:   &event.Status,
+		Applied:  &applied,
+	}, tx); err != nil {
+		return oops.Wrap(err, "Error when adding order event.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Settle do the business logic of simulating a payment status update,
+// only available when the fake provider is in use
+func Settle(externalID string, in *INSettlement) (err error) {
+	fake, ok := provider.(*payment.Fake)
+	if !ok {
+		return oops.NewErr("Simulação de pagamento disponível apenas com o provedor fake")
+	}
+
+	payload, signature, err := fake.Settle(externalID, *in.Status)
+	if err != nil {
+		return oops.Wrap(err, "Error when simulating payment.")
+	}
+
+	return HandleWebhook(payload, signature)
+}
+
+// Get do the business logic of fetching an order by its ID
+func Get(id uint, locale string) (out *OUTOrder, err error) {
+	var repo domain.IOrder = &repository.Repository{}
+
+	data := &domain.Order{ID: &id}
+
+	if err = repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching order.")
+	}
+
+	if out, err = toOUTOrder(data, locale); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetByUser do the business logic of listing the orders of an user
+func GetByUser(userID uint, locale string) (out *OUTList, err error) {
+	var (
+		userRepo userDomain.IUser = &userRepository.Repository{}
+		repo     domain.IOrder    = &repository.Repository{}
+	)
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &userID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	data := []domain.Order{}
+
+	if err = repo.GetAll(&data, &domain.Filter{UserID: &userID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing orders.")
+	}
+
+	out = &OUTList{Data: make([]OUTOrder, len(data))}
+
+	for i := range data {
+		item, err := toOUTOrder(&data[i], locale)
+		if err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+		out.Data[i] = *item
+	}
+
+	return out, nil
+}
+
+// refundLatePayment handles a payment captured after its order failed, as
+// when the student left the class meanwhile. The order stays failed, the
+// payment is recorded and given back in full, both showing on the ledger
+func refundLatePayment(data *domain.Order, tx *gorm.DB) error {
+	paid := utils.Money{Amount: *data.Amount, Currency: *data.Currency}
+
+	if err := addEntry(data, domain.EntryPayment, paid, "Pagamento recebido após a falha do pedido", tx); err != nil {
+		return err
+	}
+
+	return refund(data, paid, "Estorno de pagamento recebido após a falha do pedido", false, tx)
+}
+
+// transition moves an order to a new status, reporting whether it changed.
+// Repeated and out of order statuses are ignored so providers may resend them
+func transition(data *domain.Order, status string, tx *gorm.DB) (bool, error) {
+	var repo domain.IOrder = &repository.Repository{}
+
+	if *data.Status == status {
+		return false, nil
+	}
+
+	allowed := false
+	for _, next := range transitions[*data.Status] {
+		allowed = allowed || next == status
+	}
+
+	if !allowed {
+		log.Printf("Ignoring transition of order %d from %s to %s", *data.ID, *data.Status, status)
+		return false, nil
+	}
+
+	now := time.Now()
+	update := &domain.Order{ID: data.ID, Status: &status}
+
+	switch status {
+	case domain.StatusPaid:
+		update.PaidAt, data.PaidAt = &now, &now
+	case domain.StatusFailed:
+		update.FailedAt, data.FailedAt = &now, &now
+	case domain.StatusRefunded:
+		update.RefundedAt, data.RefundedAt = &now, &now
+	}
+
+	if err := repo.Update(update, tx); err != nil {
+		return false, oops.Wrap(err, "Error when updating order.")
+	}
+
+	data.Status = &status
+
+	// failed and refunded orders give the use of their coupon back
+	if (status == domain.StatusFailed || status == domain.StatusRefunded) && data.RedemptionID != nil {
+		if err := couponApp.Release(*data.RedemptionID, tx); err != nil {
+			return false, err
+		}
+	}
+
+	if status == domain.StatusPaid && *data.Amount > 0 {
+		paid := utils.Money{Amount: *data.Amount, Currency: *data.Currency}
+		if err := addEntry(data, domain.EntryPayment, paid, "Pagamento do pedido", tx); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func toOUTOrder(data *domain.Order, locale string) (out *OUTOrder, err error) {
+	out = &OUTOrder{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, err
+	}
+
+	money := func(amount *int64) *classApp.OUTMoney {
+		if amount == nil || data.Currency == nil {
+			return nil
+		}
+		return classApp.ToOUTMoney(utils.Money{Amount: *amount, Currency: *data.Currency}, locale)
+	}
+
+	out.Price, out.Discount, out.Amount = money(data.Price), money(data.Discount), money(data.Amount)
+	out.Refunded = money(data.Refunded)
+
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package order
+
+import (
+	classApp "go-api/application/entities/class"
+	"time"
+)
+
+// INCheckout models the checkout of an enrollment, optionally using a coupon
+type INCheckout struct {
+	StudentID  *uint   `json:"student_id" binding:"required"`
+	CouponCode *string `json:"coupon_code"`
+}
+
+// INSettlement models a payment status notified by the fake provider
+type INSettlement struct {
+	Status *string `json:"status" binding:"required,oneof=paid failed refunded"`
+}
+
+// OUTOrder models an order for retrieval
+type OUTOrder struct {
+	ID           *uint              `json:"id,omitempty" conversor:"id"`
+	EnrollmentID *uint              `json:"enrollment_id,omitempty" conversor:"enrollment_id"`
+	ClassID      *uint              `json:"class_id,omitempty" conversor:"class_id"`
+	UserID       *uint              `json:"user_id,omitempty" conversor:"user_id"`
+	Status       *string            `json:"status,omitempty" conversor:"status"`
+	Price        *classApp.OUTMoney `json:"price,omitempty"`
+	Discount     *classApp.OUTMoney `json:"discount,omitempty"`
+	Amount       *classApp.OUTMoney `json:"amount,omitempty"`
+	Refunded     *classApp.OUTMoney `json:"refunded,omitempty"`
+	CouponCode   *string            `json:"coupon_code,omitempty" conversor:"coupon_code"`
+	Provider     *string            `json:"provider,omitempty" conversor:"provider"`
+	CheckoutURL  *string            `json:"checkout_url,omitempty" conversor:"checkout_url"`
+	PaidAt       *time.Time         `json:"paid_at,omitempty" conversor:"paid_at"`
+	FailedAt     *time.Time         `json:"failed_at,omitempty" conversor:"failed_at"`
+	RefundedAt   *time.Time         `json:"refunded_at,omitempty" conversor:"refunded_at"`
+	CreatedAt    *time.Time         `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt    *time.Time         `json:"updated_at,omitempty" conversor:"updated_at"`
+}
+
+// OUTList models a list of orders
+type OUTList struct {
+	Data []OUTOrder
+}
+
+// OUTLedgerEntry models a financial movement of an user for retrieval
+type OUTLedgerEntry struct {
+	ID          *uint              `json:"id,omitempty" conversor:"id"`
+	OrderID     *uint              `json:"order_id,omitempty" conversor:"order_id"`
+	Kind        *string            `json:"kind,omitempty" conversor:"kind"`
+	Amount      *classApp.OUTMoney `json:"amount,omitempty"`
+	Description *string            `json:"description,omitempty" conversor:"description"`
+	CreatedAt   *time.Time         `json:"created_at,omitempty" conversor:"created_at"`
+}
+
+// OUTBalance models the totals moved by an user in a currency
+type OUTBalance struct {
+	Paid     *classApp.OUTMoney `json:"paid"`
+	Refunded *classApp.OUTMoney `json:"refunded"`
+	Net      *classApp.OUTMoney `json:"net"`
+}
+
+// OUTLedger models the ledger of an user
+type OUTLedger struct {
+	Data     []OUTLedgerEntry
+	Balances []OUTBalance
+}
+
+// INReport models the query string of the earnings reports. Dates without
+// timezone are read on TimeZone, a To date without time includes the whole
+// day and Group splits each period by class or teacher
+type INReport struct {
+	From     *string `form:"from"`
+	To       *string `form:"to"`
+	Period   string  `form:"period" binding:"omitempty,oneof=day week month year"`
+	Group    string  `form:"group" binding:"omitempty,oneof=class teacher"`
+	ClassID  *uint   `form:"class_id"`
+	TimeZone *string `form:"time_zone" binding:"omitempty,timezone"`
+}
+
+// OUTEarning models the aggregated payments and refunds of a period, teacher
+// or class. Refunds count on the period they were issued, not the one the
+// order was paid. Net is what is left to the teacher after refunds and the platform fee
+type OUTEarning struct {
+	Period    *string            `json:"period,omitempty"`
+	TeacherID *uint              `json:"teacher_id,omitempty"`
+	ClassID   *uint              `json:"class_id,omitempty"`
+	ClassName *string            `json:"class_name,omitempty"`
+	Orders    int64              `json:"orders"`
+	Gross     *classApp.OUTMoney `json:"gross"`
+	Refunded  *classApp.OUTMoney `json:"refunded"`
+	Fee       *classApp.OUTMoney `json:"fee"`
+	Net       *classApp.OUTMoney `json:"net"`
+}
+
+// OUTEarnings models the earnings of a teacher by period, with the totals
+// of the whole report in each currency
+type OUTEarnings struct {
+	Data   []OUTEarning
+	Totals []OUTEarning
+}
+
+// OUTRevenue models the revenue of the platform by period, with the totals
+// of the whole report and of each teacher in each currency
+type OUTRevenue struct {
+	Data     []OUTEarning
+	Totals   []OUTEarning
+	Teachers []OUTEarning
+}
@@ -0,0 +1,392 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	classApp "go-api/application/entities/class"
+	"go-api/database"
+	classDomain "go-api/domain/entities/class"
+	enrollmentDomain "go-api/domain/entities/enrollment"
+	domain "go-api/domain/entities/order"
+	userDomain "go-api/domain/entities/user"
+	classRepository "go-api/infrastructure/persistance/class"
+	repository "go-api/infrastructure/persistance/order"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// refundBatchSize is the number of refunds sent on each run
+	refundBatchSize = 50
+	// maxRefundAttempts is the number of times a refund is sent before giving up
+	maxRefundAttempts = 30
+)
+
+// RefundEnrollment applies the cancellation policy of the class when a
+// student cancels an enrollment. Pending orders are abandoned and paid
+// ones are refunded through the provider. Meant to be registered
+// as an enrollment cancel hook
+func RefundEnrollment(enrollment *enrollmentDomain.Enrollment, tx *gorm.DB) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	orders := []domain.Order{}
+	filter := &domain.Filter{
+		EnrollmentID: enrollment.ID,
+		Status:       []string{domain.StatusPending, domain.StatusPaid},
+	}
+
+	if err := repo.GetAll(&orders, filter, tx); err != nil {
+		return oops.Wrap(err, "Error when listing orders.")
+	}
+
+	for i := range orders {
+		data := &orders[i]
+
+		if err := repo.Lock(data, tx); err != nil {
+			return oops.Wrap(err, "Error when fetching order.")
+		}
+
+		switch *data.Status {
+		case domain.StatusPending:
+			if _, err := transition(data, domain.StatusFailed, tx); err != nil {
+				return err
+			}
+
+		case domain.StatusPaid:
+			amount, err := cancellationRefund(data, tx)
+			if err != nil {
+				return err
+			}
+
+			if err = refund(data, amount, "Cancelamento da inscrição", true, tx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RefundSchedules compensates the students of a class for the upcoming
+// schedules canceled by the teacher, refunding their share in proportion to
+// the schedules of the class unless asked otherwise. Meant to be registered
+// as a schedule cancel hook
+func RefundSchedules(cancel *classApp.ScheduleCancel, tx *gorm.DB) error {
+	var (
+		scheduleRepo classDomain.ISchedule = &classRepository.ScheduleRepository{}
+		repo         domain.IOrder         = &repository.Repository{}
+	)
+
+	if cancel.Compensation == classApp.CompensationNone {
+		return nil
+	}
+
+	classID, canceled, now := cancel.ClassID, cancel.Schedules, time.Now()
+
+	upcoming := 0
+	for _, s := range canceled {
+		if s.Start.After(now) {
+			upcoming++
+		}
+	}
+
+	if upcoming == 0 {
+		return nil
+	}
+
+	remaining := []classDomain.Schedule{}
+
+	if err := scheduleRepo.GetAll(&remaining, classID, tx); err != nil {
+		return oops.Wrap(err, "Error when listing schedules.")
+	}
+
+	total := int64(len(remaining) + len(canceled))
+
+	orders := []domain.Order{}
+	filter := &domain.Filter{ClassID: &classID, Status: []string{domain.StatusPaid}}
+
+	if err := repo.GetAll(&orders, filter, tx); err != nil {
+		return oops.Wrap(err, "Error when listing orders.")
+	}
+
+	for i := range orders {
+		data := &orders[i]
+
+		if err := repo.Lock(data, tx); err != nil {
+			return oops.Wrap(err, "Error when fetching order.")
+		}
+
+		if *data.Status != domain.StatusPaid {
+			continue
+		}
+
+		share := *data.Amount * int64(upcoming) / total
+		if due := compensable(data); share > due {
+			share = due
+		}
+
+		amount := utils.Money{Amount: share, Currency: *data.Currency}
+
+		if err := refund(data, amount, "Cancelamento de aula pelo professor", false, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetLedger do the business logic of listing the financial movements
+// of an user along with the balance of each currency
+func GetLedger(userID uint, locale string) (out *OUTLedger, err error) {
+	var (
+		userRepo userDomain.IUser = &userRepository.Repository{}
+		repo     domain.IOrder    = &repository.Repository{}
+	)
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &userID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	data := []domain.LedgerEntry{}
+
+	if err = repo.GetEntries(&data, userID, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing ledger entries.")
+	}
+
+	out = &OUTLedger{Data: make([]OUTLedgerEntry, len(data)), Balances: []OUTBalance{}}
+
+	paid, refunded, currencies := map[string]int64{}, map[string]int64{}, []string{}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+
+		m := utils.Money{Amount: *data[i].Amount, Currency: *data[i].Currency}
+		out.Data[i].Amount = classApp.ToOUTMoney(m, locale)
+
+		if _, ok := paid[m.Currency]; !ok {
+			currencies = append(currencies, m.Currency)
+			paid[m.Currency] = 0
+		}
+
+		switch *data[i].Kind {
+		case domain.EntryPayment:
+			paid[m.Currency] += m.Amount
+		case domain.EntryRefund:
+			refunded[m.Currency] += m.Amount
+		}
+	}
+
+	for _, currency := range currencies {
+		out.Balances = append(out.Balances, OUTBalance{
+			Paid:     classApp.ToOUTMoney(utils.Money{Amount: paid[currency], Currency: currency}, locale),
+			Refunded: classApp.ToOUTMoney(utils.Money{Amount: refunded[currency], Currency: currency}, locale),
+			Net:      classApp.ToOUTMoney(utils.Money{Amount: paid[currency] - refunded[currency], Currency: currency}, locale),
+		})
+	}
+
+	return out, nil
+}
+
+// cancellationRefund computes how much of a paid order is refunded when the
+// student cancels: everything until the policy deadline before the first
+// schedule of the class, and the partial percentage of the amount after it
+func cancellationRefund(data *domain.Order, tx *gorm.DB) (utils.Money, error) {
+	var (
+		classRepo    classDomain.IClass    = &classRepository.Repository{}
+		scheduleRepo classDomain.ISchedule = &classRepository.ScheduleRepository{}
+	)
+
+	refundable := utils.Money{Amount: compensable(data), Currency: *data.Currency}
+
+	class := &classDomain.Class{ID: data.ClassID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return refundable, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	schedules := []classDomain.Schedule{}
+
+	if err := scheduleRepo.GetAll(&schedules, *data.ClassID, tx); err != nil {
+		return refundable, oops.Wrap(err, "Error when listing schedules.")
+	}
+
+	fullHours, partialPercent := classApp.RefundPolicy(class)
+
+	if len(schedules) == 0 {
+		return refundable, nil
+	}
+
+	deadline := schedules[0].Start.Add(-time.Duration(fullHours) * time.Hour)
+	if !time.Now().After(deadline) {
+		return refundable, nil
+	}
+
+	partial := utils.Money{Amount: *data.Amount, Currency: *data.Currency}.Percent(partialPercent)
+
+	return partial.Min(refundable)
+}
+
+// refund records an amount of a paid order to be given back through the
+// provider, which is only asked once the transaction commits. Closing the
+// order marks it as refunded even when the refund is partial, as no further
+// refunds are due
+func refund(data *domain.Order, amount utils.Money, description string, close bool, tx *gorm.DB) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	if err := recordRefund(data, amount, description, close, tx); err != nil {
+		return err
+	}
+
+	if amount.IsZero() || data.ExternalID == nil {
+		return nil
+	}
+
+	if *data.Provider != provider.Name() {
+		return oops.NewErr("Pedido foi pago através de outro provedor de pagamento")
+	}
+
+	// the amount refunded so far only grows, so it tells the refunds of an order apart
+	key := fmt.Sprintf("order-%d-refund-%d", *data.ID, *data.Refunded)
+
+	if err := repo.AddRefund(&domain.Refund{
+		OrderID:        data.ID,
+		Amount:         &amount.Amount,
+		Currency:       &amount.Currency,
+		IdempotencyKey: &key,
+	}, tx); err != nil {
+		return oops.Wrap(err, "Error when adding refund.")
+	}
+
+	return nil
+}
+
+// SendRefunds sends to the provider the refunds recorded by committed changes.
+// Refunds the provider rejects are retried on the next runs with the same
+// idempotency key, and left failed after maxRefundAttempts for manual handling
+func SendRefunds() (err error) {
+	var repo domain.IOrder = &repository.Repository{}
+
+	pending := []domain.Refund{}
+
+	if err = repo.PendingRefunds(&pending, refundBatchSize, database.GetDBSession()); err != nil {
+		return oops.Wrap(err, "Error when listing pending refunds.")
+	}
+
+	for i := range pending {
+		if err := sendRefund(&pending[i]); err != nil {
+			log.Printf("Failed sending refund %d: %v\n", *pending[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunRefunder periodically sends the pending refunds to the provider
+func RunRefunder(interval time.Duration) {
+	for {
+		if err := SendRefunds(); err != nil {
+			log.Println(err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// sendRefund asks the provider for a refund and records the outcome. No lock
+// is held during the request, the idempotency key covers concurrent senders
+func sendRefund(r *domain.Refund) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	amount := utils.Money{Amount: *r.Amount, Currency: *r.Currency}
+	attempts := *r.Attempts + 1
+	update := &domain.Refund{ID: r.ID, Attempts: &attempts}
+
+	err := errors.New("order paid through provider " + *r.Order.Provider)
+	if *r.Order.Provider == provider.Name() {
+		err = provider.Refund(*r.Order.ExternalID, amount, *r.IdempotencyKey)
+	}
+
+	if err == nil {
+		status, now := domain.RefundCompleted, time.Now()
+		update.Status, update.CompletedAt = &status, &now
+	} else {
+		message := err.Error()
+		update.LastError = &message
+
+		if attempts >= maxRefundAttempts {
+			status := domain.RefundFailed
+			update.Status = &status
+		}
+	}
+
+	if err := repo.UpdateRefund(update, database.GetDBSession()); err != nil {
+		return oops.Wrap(err, "Error when updating refund.")
+	}
+
+	return err
+}
+
+// recordRefund records an amount refunded to the student of an order, marking
+// a paid order as refunded once it is closed or fully refunded.
+// Nothing is recorded for zero amounts
+func recordRefund(data *domain.Order, amount utils.Money, description string, close bool, tx *gorm.DB) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	if amount.IsZero() {
+		return nil
+	}
+
+	refunded := *data.Refunded + amount.Amount
+
+	if err := repo.Update(&domain.Order{ID: data.ID, Refunded: &refunded}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating order.")
+	}
+
+	data.Refunded = &refunded
+
+	if err := addEntry(data, domain.EntryRefund, amount, description, tx); err != nil {
+		return err
+	}
+
+	if *data.Status == domain.StatusPaid && (close || refunded == *data.Amount) {
+		if _, err := transition(data, domain.StatusRefunded, tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compensable returns how much of a paid order wasn't refunded yet
+func compensable(data *domain.Order) int64 {
+	return *data.Amount - *data.Refunded
+}
+
+// addEntry records a financial movement of the student of an order
+func addEntry(data *domain.Order, kind string, amount utils.Money, description string, tx *gorm.DB) error {
+	var repo domain.IOrder = &repository.Repository{}
+
+	entry := &domain.LedgerEntry{
+		UserID:      data.UserID,
+		OrderID:     data.ID,
+		Kind:        &kind,
+		Amount:      &amount.Amount,
+		Currency:    &amount.Currency,
+		Description: &description,
+	}
+
+	if err := repo.AddEntry(entry, tx); err != nil {
+		return oops.Wrap(err, "Error when adding ledger entry.")
+	}
+
+	return nil
+}
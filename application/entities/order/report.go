@@ -0,0 +1,264 @@
+package order
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	classApp "go-api/application/entities/class"
+	userApp "go-api/application/entities/user"
+	"go-api/database"
+	domain "go-api/domain/entities/order"
+	userDomain "go-api/domain/entities/user"
+	repository "go-api/infrastructure/persistance/order"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultReportPeriod is the unit reports are grouped by when none is asked
+	defaultReportPeriod = "month"
+	// defaultReportMonths is how many months back reports go when no start is given
+	defaultReportMonths = 12
+	// maxDailyReport is the longest range reported day by day
+	maxDailyReport = 366 * 24 * time.Hour
+)
+
+// csvHeader lists the columns of the exported reports
+var csvHeader = []string{"period", "teacher_id", "class_id", "class_name", "currency",
+	"orders", "gross", "refunded", "fee", "net"}
+
+// GetEarnings do the business logic of reporting the earnings of a teacher,
+// summing in the database the payments and refunds of the classes they teach. Only
+// the teacher may see them, proving it with their secret token
+func GetEarnings(teacherID uint, token string, in *INReport, locale string) (out *OUTEarnings, err error) {
+	var userRepo userDomain.IUser = &userRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = userApp.Authenticate(teacherID, token, db); err != nil {
+		return nil, err
+	}
+
+	teacher := &userDomain.User{ID: &teacherID}
+
+	if err = userRepo.Get(teacher, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	zone := utils.DefaultTimeZone
+	if teacher.TimeZone != nil && *teacher.TimeZone != "" {
+		zone = *teacher.TimeZone
+	}
+
+	filter, err := reportFilter(in, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.TeacherID, filter.ClassID = &teacherID, in.ClassID
+	filter.ByClass = in.Group == "class"
+
+	out = &OUTEarnings{}
+
+	if out.Data, err = earnings(filter, locale, db); err != nil {
+		return nil, err
+	}
+
+	totals := *filter
+	totals.Period, totals.ByClass = "", false
+
+	if out.Totals, err = earnings(&totals, locale, db); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// GetRevenue do the business logic of reporting the revenue of the whole
+// platform, for the admin dashboard
+func GetRevenue(in *INReport, locale string) (out *OUTRevenue, err error) {
+	db := database.GetDBSession()
+
+	filter, err := reportFilter(in, utils.DefaultTimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.ClassID = in.ClassID
+	filter.ByClass, filter.ByTeacher = in.Group == "class", in.Group == "teacher"
+
+	out = &OUTRevenue{}
+
+	if out.Data, err = earnings(filter, locale, db); err != nil {
+		return nil, err
+	}
+
+	totals := *filter
+	totals.Period, totals.ByClass, totals.ByTeacher = "", false, false
+
+	if out.Totals, err = earnings(&totals, locale, db); err != nil {
+		return nil, err
+	}
+
+	totals.ByTeacher = true
+
+	if out.Teachers, err = earnings(&totals, locale, db); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// EarningsCSV renders the rows of a report as CSV, with the amounts in major units
+func EarningsCSV(rows []OUTEarning) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, oops.Wrap(err, "Error when writing report.")
+	}
+
+	// spreadsheets run the cells starting like a formula
+	text := func(v *string) string {
+		if v == nil {
+			return ""
+		}
+		if *v != "" && strings.ContainsRune("=+-@", rune((*v)[0])) {
+			return "'" + *v
+		}
+		return *v
+	}
+
+	id := func(v *uint) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	}
+
+	amount := func(m *classApp.OUTMoney) string {
+		return utils.Money{Amount: m.Amount, Currency: m.Currency}.Decimal()
+	}
+
+	for _, row := range rows {
+		record := []string{text(row.Period), id(row.TeacherID), id(row.ClassID), text(row.ClassName),
+			row.Gross.Currency, strconv.FormatInt(row.Orders, 10), amount(row.Gross), amount(row.Refunded),
+			amount(row.Fee), amount(row.Net)}
+
+		if err := w.Write(record); err != nil {
+			return nil, oops.Wrap(err, "Error when writing report.")
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return nil, oops.Wrap(err, "Error when writing report.")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// reportFilter reads the period of a report on the given zone, unless
+// another one is asked. It defaults to the last twelve months by month
+func reportFilter(in *INReport, zone string) (*domain.ReportFilter, error) {
+	if in.TimeZone != nil && *in.TimeZone != "" {
+		zone = *in.TimeZone
+	}
+
+	loc, err := utils.LoadTimeZone(zone)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when loading location.")
+	}
+
+	filter := &domain.ReportFilter{Period: in.Period, TimeZone: loc.String(), To: time.Now()}
+
+	if filter.Period == "" {
+		filter.Period = defaultReportPeriod
+	}
+
+	now := time.Now().In(loc)
+	filter.From = time.Date(now.Year(), now.Month()-defaultReportMonths+1, 1, 0, 0, 0, 0, loc)
+
+	if in.From != nil {
+		t, err := utils.ParseDateTimeIn(*in.From, loc)
+		if err != nil {
+			return nil, reportDateError("from", err)
+		}
+		filter.From = *t
+	}
+
+	if in.To != nil {
+		t, err := utils.ParseDateTimeIn(*in.To, loc)
+		if err != nil {
+			return nil, reportDateError("to", err)
+		}
+		filter.To = *t
+
+		if len(*in.To) == len("2006-01-02") {
+			filter.To = t.AddDate(0, 0, 1)
+		}
+	}
+
+	if !filter.To.After(filter.From) {
+		return nil, oops.NewErr("Data final deve ser posterior à inicial")
+	}
+
+	if filter.Period == "day" && filter.To.Sub(filter.From) > maxDailyReport {
+		return nil, oops.NewErr("Relatório diário não pode ser maior que 366 dias")
+	}
+
+	return filter, nil
+}
+
+func reportDateError(field string, err error) error {
+	var nonexistent *utils.NonexistentTimeError
+	if errors.As(err, &nonexistent) {
+		return oops.Err(err)
+	}
+	return oops.NewErr("Campo " + field + " não contém uma data válida")
+}
+
+// earnings aggregates the orders in the database and converts the amounts
+func earnings(filter *domain.ReportFilter, locale string, db *gorm.DB) ([]OUTEarning, error) {
+	var repo domain.IOrder = &repository.Repository{}
+
+	data := []domain.Earning{}
+
+	if err := repo.Earnings(&data, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when aggregating orders.")
+	}
+
+	out := make([]OUTEarning, len(data))
+
+	for i, row := range data {
+		money := func(amount int64) *classApp.OUTMoney {
+			return classApp.ToOUTMoney(utils.Money{Amount: amount, Currency: row.Currency}, locale)
+		}
+
+		out[i] = OUTEarning{
+			TeacherID: row.TeacherID,
+			ClassID:   row.ClassID,
+			ClassName: row.ClassName,
+			Orders:    row.Orders,
+			Gross:     money(row.Gross),
+			Refunded:  money(row.Refunded),
+			Fee:       money(row.Fee),
+			Net:       money(row.Net),
+		}
+
+		if row.Period != nil {
+			period := row.Period.Format("2006-01-02")
+			out[i].Period = &period
+		}
+	}
+
+	return out, nil
+}
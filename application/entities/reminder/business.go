@@ -0,0 +1,206 @@
+package reminder
+
+import (
+	"fmt"
+	notificationApp "go-api/application/entities/notification"
+	"go-api/database"
+	notificationDomain "go-api/domain/entities/notification"
+	domain "go-api/domain/entities/reminder"
+	userDomain "go-api/domain/entities/user"
+	repository "go-api/infrastructure/persistance/reminder"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"log"
+	"time"
+)
+
+// batchSize is the number of reminders fetched at once by the scheduler
+const batchSize = 100
+
+// reminders are the kinds of reminder, from the farthest to the closest to
+// the start of a schedule. Each one is due once the time left until the start
+// is at most its offset, unless the next one is already due as well
+var reminders = []struct {
+	kind   string
+	before time.Duration
+	label  string
+}{
+	{domain.KindDayBefore, 24 * time.Hour, "24 horas"},
+	{domain.KindHourBefore, time.Hour, "1 hora"},
+}
+
+// GetPreference do the business logic of fetching the reminder
+// preference of an user, every reminder when never set
+func GetPreference(userID uint) (out *OUTPreference, err error) {
+	var (
+		userRepo userDomain.IUser = &userRepository.Repository{}
+		repo     domain.IReminder = &repository.Repository{}
+	)
+
+	db := database.GetDBSession()
+
+	if err = userRepo.Get(&userDomain.User{ID: &userID}, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	data := &domain.Preference{UserID: &userID}
+
+	if err = repo.GetPreference(data, db); err != nil {
+		if !oops.IsNotFound(err) {
+			return nil, oops.Wrap(err, "Error when fetching reminder preference.")
+		}
+
+		enabled := true
+		data.DayBefore, data.HourBefore = &enabled, &enabled
+	}
+
+	out = &OUTPreference{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// UpdatePreference do the business logic of choosing which reminders an user receives
+func UpdatePreference(userID uint, in *INPreference) (err error) {
+	var (
+		userRepo userDomain.IUser = &userRepository.Repository{}
+		repo     domain.IReminder = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = userRepo.Get(&userDomain.User{ID: &userID}, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching user.")
+	}
+
+	data := &domain.Preference{}
+
+	if err = utils.ConvertStruct(in, data); err != nil {
+		return oops.Wrap(err, "Error when converting struct.")
+	}
+
+	data.UserID = &userID
+
+	if err = repo.SavePreference(data, tx); err != nil {
+		return oops.Wrap(err, "Error when saving reminder preference.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// SendReminders delivers the reminders due to the enrolled students and the
+// teachers of the upcoming schedules. Each delivery is recorded along with
+// its notification, so a reminder is sent once even with many instances
+func SendReminders() (err error) {
+	var repo domain.IReminder = &repository.Repository{}
+
+	now := time.Now()
+
+	for i, reminder := range reminders {
+		filter := &domain.Filter{Kind: reminder.kind, From: now, To: now.Add(reminder.before), Limit: batchSize}
+
+		// schedules that are due to the next reminder skip this one
+		if i+1 < len(reminders) {
+			filter.From = now.Add(reminders[i+1].before)
+		}
+
+		for {
+			pending := []domain.Pending{}
+
+			if err = repo.Pending(&pending, filter, database.GetDBSession()); err != nil {
+				return oops.Wrap(err, "Error when listing pending reminders.")
+			}
+
+			for _, p := range pending {
+				if err = deliver(&p, reminder.kind, reminder.label); err != nil {
+					return err
+				}
+			}
+
+			if len(pending) < batchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunScheduler periodically sends the reminders that became due
+func RunScheduler(interval time.Duration) {
+	for {
+		if err := SendReminders(); err != nil {
+			log.Println(err)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// deliver records the delivery of a reminder and notifies the user in the
+// same transaction, doing nothing when another instance delivered it first
+func deliver(p *domain.Pending, kind, label string) (err error) {
+	var repo domain.IReminder = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	inserted, err := repo.AddDelivery(&domain.Delivery{ScheduleID: &p.ScheduleID, UserID: &p.UserID, Kind: &kind}, tx)
+	if err != nil {
+		return oops.Wrap(err, "Error when recording reminder delivery.")
+	}
+
+	if !inserted {
+		return nil
+	}
+
+	// the start is shown on the time zone of the user, or on the one of the class
+	zone := p.ClassTimeZone
+	if p.UserTimeZone != nil {
+		zone = *p.UserTimeZone
+	}
+
+	loc, err := utils.LoadTimeZone(zone)
+	if err != nil {
+		return oops.Wrap(err, "Error when loading location.")
+	}
+
+	notificationKind := notificationDomain.KindReminder
+	title := "Lembrete de aula"
+	body := fmt.Sprintf("Sua aula de %s começa em %s, %s (%s)",
+		p.ClassName, label, p.StartsAt.In(loc).Format("02/01/2006 às 15:04"), loc)
+
+	err = notificationApp.Send(&notificationDomain.Notification{
+		UserID:  &p.UserID,
+		Kind:    &notificationKind,
+		Title:   &title,
+		Body:    &body,
+		ClassID: &p.ClassID,
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package reminder
+
+import (
+	"time"
+)
+
+// INPreference models which reminders an user wants to receive
+type INPreference struct {
+	DayBefore  *bool `json:"day_before" binding:"required" conversor:"day_before"`
+	HourBefore *bool `json:"hour_before" binding:"required" conversor:"hour_before"`
+}
+
+// OUTPreference models the reminder preference of an user for retrieval
+type OUTPreference struct {
+	UserID     *uint      `json:"user_id,omitempty" conversor:"user_id"`
+	DayBefore  *bool      `json:"day_before" conversor:"day_before"`
+	HourBefore *bool      `json:"hour_before" conversor:"hour_before"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
+}
@@ -0,0 +1,451 @@
+package review
+
+import (
+	userApp "go-api/application/entities/user"
+	"go-api/database"
+	attendanceDomain "go-api/domain/entities/attendance"
+	classDomain "go-api/domain/entities/class"
+	domain "go-api/domain/entities/review"
+	userDomain "go-api/domain/entities/user"
+	attendanceRepository "go-api/infrastructure/persistance/attendance"
+	classRepository "go-api/infrastructure/persistance/class"
+	repository "go-api/infrastructure/persistance/review"
+	userRepository "go-api/infrastructure/persistance/user"
+	"go-api/oops"
+	"go-api/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the SQLSTATE raised when a student reviews
+// a class twice or an user flags a review twice
+const uniqueViolation = "23505"
+
+// flagsToHide is the number of flags that hides a review until the
+// moderation decides about it. Reviews already moderated stay as decided
+const flagsToHide = 3
+
+// Add do the business logic of a student reviewing a class. Only students
+// who attended the class may review it, once
+func Add(classID uint, token string, in *INReview) (id uint, err error) {
+	var (
+		attendanceRepo attendanceDomain.IAttendance = &attendanceRepository.Repository{}
+		repo           domain.IReview               = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return id, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = userApp.Authenticate(*in.StudentID, token, tx); err != nil {
+		return id, err
+	}
+
+	class, err := lockRatings(classID, tx)
+	if err != nil {
+		return id, err
+	}
+
+	if *class.TeacherID == *in.StudentID {
+		return id, oops.NewErr("Professor não pode avaliar a própria turma")
+	}
+
+	attended, err := attendanceRepo.Attended(classID, *in.StudentID, tx)
+	if err != nil {
+		return id, oops.Wrap(err, "Error when fetching attendances.")
+	}
+
+	if !attended {
+		return id, oops.NewErr("Apenas alunos que participaram da turma podem avaliá-la")
+	}
+
+	data := &domain.Review{}
+
+	if err = utils.ConvertStruct(in, data); err != nil {
+		return id, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	status := domain.StatusVisible
+	data.ClassID, data.Status = &classID, &status
+
+	if err = repo.Add(data, tx); err != nil {
+		if oops.IsPgCode(err, uniqueViolation) {
+			return id, oops.NewConflict("Aluno já avaliou esta turma", nil)
+		}
+		return id, oops.Wrap(err, "Error when adding new review.")
+	}
+
+	if err = refreshRatings(class, tx); err != nil {
+		return id, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return id, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return *data.ID, nil
+}
+
+// Update do the business logic of a student changing the rating or
+// the text of a review
+func Update(id uint, token string, in *INReview) (err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	current, class, err := lockReview(id, tx)
+	if err != nil {
+		return err
+	}
+
+	if *current.StudentID != *in.StudentID {
+		return oops.NewErr("Avaliação pertence a outro aluno")
+	}
+
+	if err = userApp.Authenticate(*in.StudentID, token, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Update(&domain.Review{ID: &id, Rating: in.Rating, Text: in.Text}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating review.")
+	}
+
+	if err = refreshRatings(class, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Delete do the business logic of the student who wrote
+// a review removing it, proven by their secret token
+func Delete(id uint, token string) (err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	current, class, err := lockReview(id, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = userApp.Authenticate(*current.StudentID, token, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Delete(id, tx); err != nil {
+		return oops.Wrap(err, "Error when removing review.")
+	}
+
+	if err = refreshRatings(class, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Reply do the business logic of the teacher of the class replying to
+// a review, replacing the previous reply
+func Reply(id uint, token string, in *INReply) (err error) {
+	var (
+		classRepo classDomain.IClass = &classRepository.Repository{}
+		repo      domain.IReview     = &repository.Repository{}
+	)
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data := &domain.Review{ID: &id}
+
+	if err = repo.Get(data, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching review.")
+	}
+
+	class := &classDomain.Class{ID: data.ClassID}
+
+	if err = classRepo.Get(class, nil, tx); err != nil {
+		return oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err = userApp.Authenticate(*class.TeacherID, token, tx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if err = repo.Update(&domain.Review{ID: &id, Reply: in.Text, RepliedAt: &now}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating review.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Flag do the business logic of an user reporting a review to the
+// moderation. Reviews reaching the flag limit are hidden until moderated
+func Flag(id uint, token string, in *INFlag) (err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	current, class, err := lockReview(id, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = userApp.Authenticate(*in.UserID, token, tx); err != nil {
+		return err
+	}
+
+	if *current.StudentID == *in.UserID {
+		return oops.NewErr("Aluno não pode denunciar a própria avaliação")
+	}
+
+	if err = repo.AddFlag(&domain.Flag{ReviewID: &id, UserID: in.UserID, Reason: in.Reason}, tx); err != nil {
+		if oops.IsPgCode(err, uniqueViolation) {
+			return oops.NewConflict("Usuário já denunciou esta avaliação", nil)
+		}
+		return oops.Wrap(err, "Error when adding review flag.")
+	}
+
+	flags := *current.Flags + 1
+	update := &domain.Review{ID: &id, Flags: &flags}
+
+	hide := flags >= flagsToHide && *current.Status == domain.StatusVisible && current.ModeratedAt == nil
+	if hide {
+		status := domain.StatusHidden
+		update.Status = &status
+	}
+
+	if err = repo.Update(update, tx); err != nil {
+		return oops.Wrap(err, "Error when updating review.")
+	}
+
+	if hide {
+		if err = refreshRatings(class, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Moderate do the business logic of the moderation showing or hiding
+// a review. Hidden reviews are not listed nor counted on the ratings
+func Moderate(id uint, in *INModeration) (err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	_, class, err := lockReview(id, tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if err = repo.Update(&domain.Review{ID: &id, Status: in.Status, ModeratedAt: &now}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating review.")
+	}
+
+	if err = refreshRatings(class, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Get do the business logic of fetching a review by its ID
+func Get(id uint) (out *OUTReview, err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	data := &domain.Review{ID: &id}
+
+	if err = repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching review.")
+	}
+
+	out = &OUTReview{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetByClass do the business logic of listing the visible reviews of a class
+func GetByClass(classID uint) (out *OUTList, err error) {
+	var classRepo classDomain.IClass = &classRepository.Repository{}
+
+	db := database.GetDBSession()
+
+	if err = classRepo.Get(&classDomain.Class{ID: &classID}, nil, db); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return list(&domain.Filter{ClassID: &classID, Status: []string{domain.StatusVisible}}, db)
+}
+
+// GetAll do the business logic of listing the reviews for the moderation,
+// optionally only the ones that were flagged
+func GetAll(flagged bool) (out *OUTList, err error) {
+	return list(&domain.Filter{Flagged: flagged}, database.GetDBSession())
+}
+
+func list(filter *domain.Filter, db *gorm.DB) (out *OUTList, err error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	data := []domain.Review{}
+
+	if err = repo.GetAll(&data, filter, db); err != nil {
+		return nil, oops.Wrap(err, "Error when listing reviews.")
+	}
+
+	out = &OUTList{Data: make([]OUTReview, len(data))}
+
+	for i := range data {
+		if err = utils.ConvertStruct(&data[i], &out.Data[i]); err != nil {
+			return nil, oops.Wrap(err, "Error when converting struct.")
+		}
+	}
+
+	return out, nil
+}
+
+// lockRatings locks the teacher and then the class whose ratings are about
+// to change, so concurrent reviews recompute them one after the other
+func lockRatings(classID uint, tx *gorm.DB) (*classDomain.Class, error) {
+	var (
+		classRepo classDomain.IClass = &classRepository.Repository{}
+		userRepo  userDomain.IUser   = &userRepository.Repository{}
+	)
+
+	class := &classDomain.Class{ID: &classID}
+
+	if err := classRepo.Get(class, nil, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	if err := userRepo.Lock(&userDomain.User{ID: class.TeacherID}, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching teacher.")
+	}
+
+	if err := classRepo.Lock(class, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching class.")
+	}
+
+	return class, nil
+}
+
+// lockReview locks the ratings of the class of a review
+// and fetches the review after the lock is held
+func lockReview(id uint, tx *gorm.DB) (*domain.Review, *classDomain.Class, error) {
+	var repo domain.IReview = &repository.Repository{}
+
+	data := &domain.Review{ID: &id}
+
+	if err := repo.Get(data, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching review.")
+	}
+
+	class, err := lockRatings(*data.ClassID, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = repo.Get(data, tx); err != nil {
+		return nil, nil, oops.Wrap(err, "Error when fetching review.")
+	}
+
+	return data, class, nil
+}
+
+// refreshRatings recomputes the denormalized ratings of a class and
+// of its teacher from the visible reviews. Both must have been locked
+func refreshRatings(class *classDomain.Class, tx *gorm.DB) error {
+	var (
+		classRepo classDomain.IClass = &classRepository.Repository{}
+		userRepo  userDomain.IUser   = &userRepository.Repository{}
+		repo      domain.IReview     = &repository.Repository{}
+	)
+
+	visible := []string{domain.StatusVisible}
+
+	rating := &domain.Rating{}
+
+	if err := repo.Summarize(rating, &domain.Filter{ClassID: class.ID, Status: visible}, tx); err != nil {
+		return oops.Wrap(err, "Error when summarizing reviews.")
+	}
+
+	if err := classRepo.Update(&classDomain.Class{
+		ID:            class.ID,
+		RatingAverage: &rating.Average,
+		RatingCount:   &rating.Count,
+	}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating class.")
+	}
+
+	rating = &domain.Rating{}
+
+	if err := repo.Summarize(rating, &domain.Filter{TeacherID: class.TeacherID, Status: visible}, tx); err != nil {
+		return oops.Wrap(err, "Error when summarizing reviews.")
+	}
+
+	if err := userRepo.Update(&userDomain.User{
+		ID:            class.TeacherID,
+		RatingAverage: &rating.Average,
+		RatingCount:   &rating.Count,
+	}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating teacher.")
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package review
+
+import (
+	"time"
+)
+
+// INReview models the review of a class by a student for insertion and update
+type INReview struct {
+	StudentID *uint   `json:"student_id" binding:"required" conversor:"student_id"`
+	Rating    *int64  `json:"rating" binding:"required,min=1,max=5" conversor:"rating"`
+	Text      *string `json:"text" conversor:"text"`
+}
+
+// INReply models the reply of the teacher to a review
+type INReply struct {
+	Text *string `json:"text" binding:"required"`
+}
+
+// INFlag models an user reporting a review to the moderation
+type INFlag struct {
+	UserID *uint   `json:"user_id" binding:"required" conversor:"user_id"`
+	Reason *string `json:"reason" binding:"required" conversor:"reason"`
+}
+
+// INModeration models the decision of the moderation about a review
+type INModeration struct {
+	Status *string `json:"status" binding:"required,oneof=visible hidden"`
+}
+
+// OUTReview models a review for retrieval
+type OUTReview struct {
+	ID          *uint      `json:"id,omitempty" conversor:"id"`
+	ClassID     *uint      `json:"class_id,omitempty" conversor:"class_id"`
+	StudentID   *uint      `json:"student_id,omitempty" conversor:"student_id"`
+	Rating      *int64     `json:"rating,omitempty" conversor:"rating"`
+	Text        *string    `json:"text,omitempty" conversor:"text"`
+	Reply       *string    `json:"reply,omitempty" conversor:"reply"`
+	RepliedAt   *time.Time `json:"replied_at,omitempty" conversor:"replied_at"`
+	Status      *string    `json:"status,omitempty" conversor:"status"`
+	Flags       *int64     `json:"flags,omitempty" conversor:"flags"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty" conversor:"moderated_at"`
+	CreatedAt   *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
+}
+
+// OUTList models a list of reviews
+type OUTList struct {
+	Data []OUTReview
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	classApp "go-api/application/entities/class"
+	"go-api/database"
+	domain "go-api/domain/entities/search"
+	repository "go-api/infrastructure/persistance/search"
+	"go-api/oops"
+	"go-api/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultLimit is the number of results returned when the client doesn't say
+	defaultLimit = 20
+)
+
+// Search do the business logic of searching classes by text along with
+// the price, teacher and schedule filters. Amounts and highlights follow
+// the given locale unless another language is asked for
+func Search(in *INSearch, locale string) (out *OUTSearch, err error) {
+	var repo domain.ISearch = &repository.Repository{}
+
+	filter := &domain.Filter{
+		Query:      strings.TrimSpace(in.Query),
+		Config:     domain.ConfigPortuguese,
+		MinPrice:   in.MinPrice,
+		MaxPrice:   in.MaxPrice,
+		Currency:   in.Currency,
+		TeacherID:  in.TeacherID,
+		CategoryID: in.CategoryID,
+		Tags:       classApp.ParseTags(in.Tags),
+		Limit:      defaultLimit,
+	}
+
+	lang := locale
+	if in.Lang != nil {
+		lang = *in.Lang
+	}
+
+	if strings.HasPrefix(strings.ToLower(lang), "en") {
+		filter.Config = domain.ConfigEnglish
+	}
+
+	if in.Limit != nil {
+		filter.Limit = *in.Limit
+	}
+
+	if in.Offset != nil {
+		filter.Offset = *in.Offset
+	}
+
+	if in.MinPrice != nil && in.MaxPrice != nil && *in.MinPrice > *in.MaxPrice {
+		return nil, oops.NewErr("Preço mínimo deve ser menor ou igual ao preço máximo")
+	}
+
+	// prices are only comparable within the same currency
+	if (in.MinPrice != nil || in.MaxPrice != nil) && in.Currency == nil {
+		currency := utils.DefaultCurrency
+		filter.Currency = &currency
+	}
+
+	// dates are shown on the asked time zone, or on the one of each class
+	var loc *time.Location
+	if in.TimeZone != nil {
+		if loc, err = utils.LoadTimeZone(*in.TimeZone); err != nil {
+			return nil, oops.Wrap(err, "Error when loading location.")
+		}
+	}
+
+	parseLoc := loc
+	if parseLoc == nil {
+		if parseLoc, err = utils.DefaultLocation(); err != nil {
+			return nil, oops.Wrap(err, "Error when loading location.")
+		}
+	}
+
+	if in.From != nil {
+		if filter.From, err = utils.ParseDateTimeIn(*in.From, parseLoc); err != nil {
+			return nil, oops.NewErr("Data inicial inválida")
+		}
+	}
+
+	if in.To != nil {
+		if filter.To, err = utils.ParseDateTimeIn(*in.To, parseLoc); err != nil {
+			return nil, oops.NewErr("Data final inválida")
+		}
+
+		// a period without start goes from now on
+		if filter.From == nil {
+			now := time.Now()
+			filter.From = &now
+		}
+
+		if !filter.To.After(*filter.From) {
+			return nil, oops.NewErr("Data final deve ser posterior à data inicial")
+		}
+	}
+
+	data := []domain.Result{}
+
+	if err = repo.Classes(&data, filter, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when searching classes.")
+	}
+
+	out = &OUTSearch{Data: make([]OUTResult, len(data))}
+
+	for i, r := range data {
+		if r.NextStart != nil {
+			zone := loc
+			if zone == nil {
+				if zone, err = utils.LoadTimeZone(r.TimeZone); err != nil {
+					return nil, oops.Wrap(err, "Error when loading location.")
+				}
+			}
+			next := r.NextStart.In(zone)
+			r.NextStart = &next
+		}
+
+		out.Data[i] = OUTResult{
+			ID:            r.ClassID,
+			Name:          r.Name,
+			Description:   r.Description,
+			Price:         classApp.ToOUTMoney(utils.Money{Amount: r.Price, Currency: r.Currency}, locale),
+			Capacity:      r.Capacity,
+			Teacher:       &OUTTeacher{ID: r.TeacherID, Name: r.TeacherName},
+			RatingAverage: r.RatingAverage,
+			RatingCount:   r.RatingCount,
+			NextStart:     r.NextStart,
+			Rank:          r.Rank,
+		}
+
+		if filter.Query != "" {
+			out.Data[i].Highlights = &OUTHighlights{
+				Name:        r.NameHighlight,
+				Description: r.DescriptionHighlight,
+				Teacher:     r.TeacherHighlight,
+				Bio:         r.BioHighlight,
+			}
+		}
+	}
+
+	return out, nil
+}
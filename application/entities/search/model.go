@@ -0,0 +1,61 @@
+package search
+
+import (
+	classApp "go-api/application/entities/class"
+	"time"
+)
+
+// INSearch models the query string of a search. Prices are in the minor
+// unit of the currency and dates are accepted in the same formats of the
+// schedules, read on TimeZone when they have none. Lang picks the language
+// of the highlights, pt or en
+type INSearch struct {
+	Query      string  `form:"q"`
+	MinPrice   *int64  `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice   *int64  `form:"max_price" binding:"omitempty,gte=0"`
+	Currency   *string `form:"currency" binding:"omitempty,iso4217"`
+	TeacherID  *uint   `form:"teacher_id"`
+	CategoryID *uint   `form:"category_id"`
+	Tags       *string `form:"tags"`
+	From       *string `form:"from"`
+	To         *string `form:"to"`
+	TimeZone   *string `form:"time_zone" binding:"omitempty,timezone"`
+	Lang       *string `form:"lang" binding:"omitempty,oneof=pt en"`
+	Limit      *int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     *int    `form:"offset" binding:"omitempty,gte=0"`
+}
+
+// OUTResult models a class found by a search. Highlights hold the matched
+// snippets of the searched fields, HTML escaped and with the terms wrapped in <b> tags
+type OUTResult struct {
+	ID            uint               `json:"id"`
+	Name          string             `json:"name"`
+	Description   *string            `json:"description,omitempty"`
+	Price         *classApp.OUTMoney `json:"price"`
+	Capacity      *int64             `json:"capacity,omitempty"`
+	Teacher       *OUTTeacher        `json:"teacher"`
+	RatingAverage float64            `json:"rating_average"`
+	RatingCount   int64              `json:"rating_count"`
+	NextStart     *time.Time         `json:"next_start,omitempty"`
+	Rank          float64            `json:"rank"`
+	Highlights    *OUTHighlights     `json:"highlights,omitempty"`
+}
+
+// OUTTeacher models the teacher of a class found by a search
+type OUTTeacher struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// OUTHighlights models the matched snippets of a class found by a search
+type OUTHighlights struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Teacher     *string `json:"teacher,omitempty"`
+	Bio         *string `json:"bio,omitempty"`
+}
+
+// OUTSearch models the results of a search
+type OUTSearch struct {
+	Data []OUTResult
+}
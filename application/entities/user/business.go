@@ -2,16 +2,26 @@ package user
 
 import (
 	"fmt"
+	classApp "go-api/application/entities/class"
 	"go-api/database"
 	domain "go-api/domain/entities/user"
 	repository "go-api/infrastructure/persistance/user"
 	"go-api/oops"
 	"go-api/utils"
 	"log"
+
+	"gorm.io/gorm"
 )
 
-// Add do the business logic of inserting an user into the database
-func Add(in *INUser) (id uint, err error) {
+// calendarTokenSize is the number of random bytes of the calendar feed tokens
+const calendarTokenSize = 24
+
+// apiTokenSize is the number of random bytes of the tokens users authenticate with
+const apiTokenSize = 32
+
+// Add do the business logic of inserting an user into the database,
+// returning the secret tokens of the new user, which aren't shown again
+func Add(in *INUser) (out *OUTCredentials, err error) {
 	var repo domain.IUser = &repository.Repository{}
 
 	fmt.Printf("\nBusiness in:  %+v\n", in)
@@ -19,7 +29,7 @@ func Add(in *INUser) (id uint, err error) {
 	tx, err := database.NewTransaction()
 
 	if err != nil {
-		return id, oops.Wrap(err, "Error when initializing transaction.")
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
 	}
 
 	defer tx.Rollback()
@@ -28,16 +38,210 @@ func Add(in *INUser) (id uint, err error) {
 
 	if err = utils.ConvertStruct(in, data); err != nil {
 		log.Println(err)
-		return id, oops.Wrap(err, "Error when converting struct.")
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	token, err := utils.RandomToken(apiTokenSize)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when generating token.")
+	}
+
+	calendarToken, err := utils.RandomToken(calendarTokenSize)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when generating calendar token.")
 	}
 
+	data.APIToken, data.CalendarToken = &token, &calendarToken
+
 	fmt.Printf("\nBusiness data:  %+v\n", data)
 
 	if err = repo.Add(data, tx); err != nil {
-		return id, oops.Wrap(err, "Error when adding new user.")
+		return nil, oops.Wrap(err, "Error when adding new user.")
 	}
 
 	tx.Commit()
 
-	return *data.ID, nil
+	return &OUTCredentials{ID: data.ID, Token: &token, CalendarToken: &calendarToken}, nil
+}
+
+// IssueToken do the business logic of an user trading their password for
+// a new secret token, invalidating the one previously issued
+func IssueToken(id uint, in *INLogin) (out *OUTToken, err error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data := &domain.User{ID: &id}
+
+	if err = repo.Lock(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	if data.Password == nil || !utils.SameToken(*data.Password, *in.Password) {
+		return nil, oops.Err(&oops.ErrInvalidToken)
+	}
+
+	token, err := utils.RandomToken(apiTokenSize)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when generating token.")
+	}
+
+	if err = repo.Update(&domain.User{ID: &id, APIToken: &token}, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when updating user.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return &OUTToken{Token: &token}, nil
+}
+
+// GetProfile do the business logic of fetching the public profile of an user
+func GetProfile(id uint) (out *OUTProfile, err error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	data := &domain.User{ID: &id}
+
+	if err = repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	out = &OUTProfile{}
+
+	if err = utils.ConvertStruct(data, out); err != nil {
+		return nil, oops.Wrap(err, "Error when converting struct.")
+	}
+
+	return out, nil
+}
+
+// GetClasses do the business logic of listing the classes taught by an user,
+// with dates on the given time zone or on the one preferred by the user.
+// Classes not visible to students are only listed with the secret token of the user
+func GetClasses(id uint, include []string, locale, timeZone, token string) (out *classApp.OUTList, err error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	data := &domain.User{ID: &id}
+
+	if err = repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	if timeZone == "" && data.TimeZone != nil {
+		timeZone = *data.TimeZone
+	}
+
+	return classApp.GetAll(&id, sameToken(data.APIToken, token), nil, include, locale, timeZone)
+}
+
+// Calendar do the business logic of rendering the iCalendar feed of an user,
+// which is only available through the secret token of the user
+func Calendar(id uint, token string) ([]byte, error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	data := &domain.User{ID: &id}
+
+	if err := repo.Get(data, database.GetDBSession()); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	if !sameToken(data.CalendarToken, token) {
+		return nil, oops.Err(&oops.ErrInvalidToken)
+	}
+
+	return classApp.UserCalendar(id, *data.Name)
+}
+
+// RotateCalendarToken do the business logic of replacing the calendar feed
+// token of an user, invalidating the feed URL previously shared. The user
+// proves the ownership of the account with their secret token
+func RotateCalendarToken(id uint, token string) (out *OUTCalendarToken, err error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	data := &domain.User{ID: &id}
+
+	if err = repo.Lock(data, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when fetching user.")
+	}
+
+	if !sameToken(data.APIToken, token) {
+		return nil, oops.Err(&oops.ErrInvalidToken)
+	}
+
+	calendarToken, err := utils.RandomToken(calendarTokenSize)
+	if err != nil {
+		return nil, oops.Wrap(err, "Error when generating calendar token.")
+	}
+
+	if err = repo.Update(&domain.User{ID: &id, CalendarToken: &calendarToken}, tx); err != nil {
+		return nil, oops.Wrap(err, "Error when updating user.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return &OUTCalendarToken{Token: &calendarToken}, nil
+}
+
+// UpdateTimeZone do the business logic of changing the time zone the dates
+// shown to an user are converted to, by request of the user themselves
+func UpdateTimeZone(id uint, token string, in *INTimeZone) (err error) {
+	var repo domain.IUser = &repository.Repository{}
+
+	tx, err := database.NewTransaction()
+	if err != nil {
+		return oops.Wrap(err, "Error when initializing transaction.")
+	}
+
+	defer tx.Rollback()
+
+	if err = Authenticate(id, token, tx); err != nil {
+		return err
+	}
+
+	if err = repo.Update(&domain.User{ID: &id, TimeZone: in.TimeZone}, tx); err != nil {
+		return oops.Wrap(err, "Error when updating user.")
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return oops.Wrap(err, "Error when committing transaction.")
+	}
+
+	return nil
+}
+
+// Authenticate ensures the token is the secret one of the user, proving a
+// request comes from them. The calendar feed token is never accepted here
+func Authenticate(id uint, token string, db *gorm.DB) error {
+	var repo domain.IUser = &repository.Repository{}
+
+	data := &domain.User{ID: &id}
+
+	if err := repo.Get(data, db); err != nil {
+		return oops.Wrap(err, "Error when fetching user.")
+	}
+
+	if !sameToken(data.APIToken, token) {
+		return oops.Err(&oops.ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func sameToken(secret *string, token string) bool {
+	return secret != nil && token != "" && utils.SameToken(*secret, token)
 }
@@ -13,6 +13,12 @@ type INUser struct {
 	AvatarURL     *string `json:"avatar_url" conversor:"avatar_url"`
 	Bio           *string `json:"bio" conversor:"bio"`
 	ContactNumber *string `json:"contact_number" conversor:"contact_number"`
+	TimeZone      *string `json:"time_zone" binding:"omitempty,timezone" conversor:"time_zone"`
+}
+
+// INTimeZone models the preferred time zone of an user
+type INTimeZone struct {
+	TimeZone *string `json:"time_zone" binding:"required,timezone"`
 }
 
 // OUTUser models a user for retrieval
@@ -25,11 +31,47 @@ type OUTUser struct {
 	AvatarURL     *string    `json:"avatar_url,omitempty" conversor:"avatar_url"`
 	Bio           *string    `json:"bio,omitempty" conversor:"bio"`
 	ContactNumber *string    `json:"contact_number,omitempty" conversor:"contact_number"`
+	TimeZone      *string    `json:"time_zone,omitempty" conversor:"time_zone"`
 	CreatedAt     *time.Time `json:"created_at,omitempty" conversor:"created_at"`
 	UpdatedAt     *time.Time `json:"updated_at,omitempty" conversor:"updated_at"`
 }
 
+// OUTProfile models the public profile of an user, along with
+// the rating of the classes taught by the user
+type OUTProfile struct {
+	ID            *uint      `json:"id,omitempty" conversor:"id"`
+	Name          *string    `json:"name,omitempty" conversor:"name"`
+	AvatarURL     *string    `json:"avatar_url,omitempty" conversor:"avatar_url"`
+	Bio           *string    `json:"bio,omitempty" conversor:"bio"`
+	RatingAverage *float64   `json:"rating_average" conversor:"rating_average"`
+	RatingCount   *int64     `json:"rating_count" conversor:"rating_count"`
+	CreatedAt     *time.Time `json:"created_at,omitempty" conversor:"created_at"`
+}
+
 // OUTList models a list of users
 type OUTList struct {
 	Data []OUTUser
 }
+
+// INLogin models the password an user trades for a new secret token
+type INLogin struct {
+	Password *string `json:"password" binding:"required"`
+}
+
+// OUTCredentials models the secret tokens of a new user. Token authenticates
+// the user in the Authorization header, CalendarToken only reads their feed
+type OUTCredentials struct {
+	ID            *uint   `json:"id"`
+	Token         *string `json:"token"`
+	CalendarToken *string `json:"calendar_token"`
+}
+
+// OUTToken models the secret token an user authenticates with
+type OUTToken struct {
+	Token *string `json:"token"`
+}
+
+// OUTCalendarToken models the secret token of the user calendar feed
+type OUTCalendarToken struct {
+	Token *string `json:"token"`
+}
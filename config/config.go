@@ -15,10 +15,31 @@ type DatabaseConfig struct {
 	Name     string `json:"name"`
 }
 
+type PaymentConfig struct {
+	Provider      string `json:"provider"`
+	BaseURL       string `json:"base_url"`
+	APIKey        string `json:"api_key"`
+	WebhookSecret string `json:"webhook_secret"`
+	PlatformFee   int64  `json:"platform_fee"`
+}
+
+type StorageConfig struct {
+	Backend       string `json:"backend"`
+	Path          string `json:"path"`
+	SigningSecret string `json:"signing_secret"`
+	MaxSize       int64  `json:"max_size"`
+}
+
 type ApiConfig struct {
 	Database DatabaseConfig `json:"database"`
+	Payment  PaymentConfig  `json:"payment"`
+	Storage  StorageConfig  `json:"storage"`
 	ApiHost  string         `json:"api_host"`
 	ApiPort  string         `json:"api_port"`
+	// DevMode enables the fake payment provider and its simulation endpoint
+	DevMode bool `json:"dev_mode"`
+	// AdminToken guards the platform-wide reports, which are disabled when empty
+	AdminToken string `json:"admin_token"`
 }
 
 const (
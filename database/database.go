@@ -6,6 +6,9 @@ import (
 	"go-api/config"
 	"log"
 	"reflect"
+	"time"
+
+	"github.com/lib/pq"
 
 	// PostgreSQL dialetc for opening connection
 	_ "github.com/jinzhu/gorm/dialects/postgres"
@@ -18,13 +21,20 @@ var (
 	sqlDB *sql.DB
 )
 
-// Open tries to open a database connection
-func Open() error {
+// dataSourceName builds the connection string from the configuration
+func dataSourceName() string {
 	config := config.GetConfig()
 
 	dsn := "user=" + config.Database.User + " host=" + config.Database.Host + " password=" + config.Database.Password + " port=" + config.Database.Port + " dbname=" + config.Database.Name + " sslmode=disable"
 
-	sqlDB, err := sql.Open("postgres", dsn)
+	// timestamps are stored and read in UTC, each response converts them
+	// to the time zone of the class or of the user
+	return dsn + " timezone=UTC"
+}
+
+// Open tries to open a database connection
+func Open() error {
+	sqlDB, err := sql.Open("postgres", dataSourceName())
 
 	if err != nil {
 		log.Println(err.Error())
@@ -37,6 +47,9 @@ func Open() error {
 		Conn: sqlDB,
 	}), &gorm.Config{
 		SkipDefaultTransaction: true,
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
 	})
 
 	if err != nil {
@@ -54,7 +67,12 @@ func NewTransaction() (*gorm.DB, error) {
 		return nil, errors.New("Database not connected")
 	}
 
-	tx := db.Session(&gorm.Session{SkipDefaultTransaction: true})
+	tx := db.Begin()
+
+	if tx.Error != nil {
+		log.Println(tx.Error.Error())
+		return nil, tx.Error
+	}
 
 	return tx, nil
 }
@@ -68,6 +86,39 @@ func Close() {
 	sqlDB.Close()
 }
 
+// Listen waits for the notifications sent to a PostgreSQL channel through a
+// dedicated connection, calling handle with the payload of each one. As
+// notifications may be lost while reconnecting, handle receives an empty
+// payload after every reconnection. It only returns when listening fails
+func Listen(channel string, handle func(payload string)) error {
+	listener := pq.NewListener(dataSourceName(), 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Listener of channel %s: %v\n", channel, err)
+		}
+	})
+
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				handle("")
+				continue
+			}
+			handle(n.Extra)
+
+		case <-time.After(90 * time.Second):
+			// checks the connection when the channel is quiet
+			go listener.Ping()
+		}
+	}
+}
+
 // GetDBSession returns the current database opened session
 func GetDBSession() *gorm.DB {
 	if db == nil {
@@ -90,3 +141,18 @@ func ApplyMigrations(model interface{}) {
 
 	log.Printf("Migrations applied successfully for model %v\n", modelType)
 }
+
+// ApplyStatements run raw SQL statements for the schema changes
+// that cannot be expressed through model tags, stopping at the first failure
+func ApplyStatements(name string, statements []string) error {
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Printf("Failed applying %s migrations: %v\n", name, err)
+			return err
+		}
+	}
+
+	log.Printf("Migrations applied successfully for %s\n", name)
+
+	return nil
+}
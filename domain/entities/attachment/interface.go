@@ -0,0 +1,11 @@
+package attachment
+
+import "gorm.io/gorm"
+
+// IAttachment interface defines the methods that Attachment repository must implement
+type IAttachment interface {
+	Add(*Attachment, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Attachment, *gorm.DB) error
+	GetAll(*[]Attachment, *Filter, *gorm.DB) error
+}
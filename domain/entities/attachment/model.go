@@ -0,0 +1,32 @@
+package attachment
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+// Attachment struct defines the fields of attachment table, a file shared
+// by the teacher with the students of a class, optionally about one of its
+// schedules. The content is kept by the storage Backend under Key
+type Attachment struct {
+	ClassID     *uint           `gorm:"not null;index" conversor:"class_id"`
+	ScheduleID  *uint           `gorm:"index" conversor:"schedule_id"`
+	UploaderID  *uint           `gorm:"not null" conversor:"uploader_id"`
+	Name        *string         `gorm:"not null" conversor:"name"`
+	ContentType *string         `gorm:"not null" conversor:"content_type"`
+	Size        *int64          `gorm:"not null" conversor:"size"`
+	Backend     *string         `gorm:"not null" conversor:"backend"`
+	Key         *string         `gorm:"not null;uniqueIndex" conversor:"key"`
+	ID          *uint           `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time      `conversor:"created_at"`
+	Class       *class.Class    `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Schedule    *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Uploader    *user.User      `gorm:"foreignKey:UploaderID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Filter defines the options used when listing attachments
+type Filter struct {
+	ClassID    *uint
+	ScheduleID *uint
+}
@@ -0,0 +1,18 @@
+package attendance
+
+import "gorm.io/gorm"
+
+// IAttendance interface defines the methods that Attendance repository must implement
+type IAttendance interface {
+	Save(*Attendance, *gorm.DB) error
+	Get(*Attendance, *gorm.DB) error
+	GetAll(*[]Attendance, uint, *gorm.DB) error
+	SaveCode(*CheckInCode, *gorm.DB) error
+	GetCode(*CheckInCode, *gorm.DB) error
+	LockAttempts(*CheckInAttempt, *gorm.DB) error
+	AddFailure(*CheckInAttempt, *gorm.DB) error
+	ResetAttempts(uint, *gorm.DB) error
+	Attended(uint, uint, *gorm.DB) (bool, error)
+	Summarize(*[]Summary, *Filter, *gorm.DB) error
+	CountSessions(*[]Sessions, *Filter, *gorm.DB) error
+}
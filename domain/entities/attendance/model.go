@@ -0,0 +1,79 @@
+package attendance
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// StatusPresent means the student attended the session on time
+	StatusPresent = "present"
+	// StatusAbsent means the student missed the session
+	StatusAbsent = "absent"
+	// StatusLate means the student arrived after the session started
+	StatusLate = "late"
+	// StatusExcused means the student missed the session with a justification
+	StatusExcused = "excused"
+)
+
+// Attendance struct defines the fields of attendance table, recording
+// whether a student attended a schedule session of a class
+type Attendance struct {
+	ScheduleID  *uint           `gorm:"not null;uniqueIndex:idx_attendances_student" conversor:"schedule_id"`
+	StudentID   *uint           `gorm:"not null;uniqueIndex:idx_attendances_student;index" conversor:"student_id"`
+	Status      *string         `gorm:"not null" conversor:"status"`
+	Note        *string         `conversor:"note"`
+	CheckedInAt *time.Time      `conversor:"checked_in_at"`
+	ID          *uint           `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time      `conversor:"created_at"`
+	UpdatedAt   *time.Time      `conversor:"updated_at"`
+	Schedule    *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Student     *user.User      `gorm:"foreignKey:StudentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// CheckInCode struct defines the fields of check in code table, holding
+// the short code students use to check in to a schedule session
+type CheckInCode struct {
+	ScheduleID *uint           `gorm:"not null;uniqueIndex" conversor:"schedule_id"`
+	Code       *string         `gorm:"not null" conversor:"code"`
+	ID         *uint           `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time      `conversor:"created_at"`
+	UpdatedAt  *time.Time      `conversor:"updated_at"`
+	Schedule   *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// CheckInAttempt struct defines the fields of check in attempt table, counting
+// the wrong codes a student sent to check in to a schedule session
+type CheckInAttempt struct {
+	ScheduleID *uint `gorm:"not null;uniqueIndex:idx_check_in_attempts_student"`
+	StudentID  *uint `gorm:"not null;uniqueIndex:idx_check_in_attempts_student"`
+	Failures   *int  `gorm:"not null;default:0"`
+	ID         *uint `gorm:"primaryKey"`
+	CreatedAt  *time.Time
+	UpdatedAt  *time.Time
+	Schedule   *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Student    *user.User      `gorm:"foreignKey:StudentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Summary counts the attendances of a student in a class with a status
+type Summary struct {
+	ClassID   uint
+	StudentID uint
+	Status    string
+	Total     int64
+}
+
+// Sessions counts the sessions of a class an enrolled student was due to attend
+type Sessions struct {
+	ClassID   uint
+	StudentID uint
+	Total     int64
+}
+
+// Filter defines the options used when summarizing attendances
+type Filter struct {
+	ClassID   *uint
+	StudentID *uint
+	Until     time.Time
+}
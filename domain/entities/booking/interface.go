@@ -0,0 +1,16 @@
+package booking
+
+import "gorm.io/gorm"
+
+// IBooking interface defines the methods that Booking repository must implement
+type IBooking interface {
+	AddWindow(*Window, *gorm.DB) error
+	DeleteWindow(uint, *gorm.DB) error
+	GetWindow(*Window, *gorm.DB) error
+	GetWindows(*[]Window, uint, *gorm.DB) error
+	GetSettings(*Settings, *gorm.DB) error
+	SaveSettings(*Settings, *gorm.DB) error
+	Add(*Booking, *gorm.DB) error
+	GetAll(*[]Booking, *Filter, *gorm.DB) error
+	IsBooked(uint, *gorm.DB) (bool, error)
+}
@@ -0,0 +1,69 @@
+package booking
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+// Window struct defines the fields of availability window table, a weekly
+// period in which a teacher accepts private lessons. Weekday counts from
+// Sunday and the minutes from midnight, both on the window time zone
+type Window struct {
+	TeacherID   *uint      `gorm:"not null;index" conversor:"teacher_id"`
+	Weekday     *int64     `gorm:"not null" conversor:"weekday"`
+	StartMinute *int64     `gorm:"not null" conversor:"start_minute"`
+	EndMinute   *int64     `gorm:"not null" conversor:"end_minute"`
+	TimeZone    *string    `gorm:"not null" conversor:"time_zone"`
+	ID          *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time `conversor:"created_at"`
+	UpdatedAt   *time.Time `conversor:"updated_at"`
+	Teacher     *user.User `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of availability windows
+func (Window) TableName() string {
+	return "availability_windows"
+}
+
+// Settings struct defines the fields of booking settings table. Buffer is
+// the free time, in minutes, kept between a private lesson and any other
+// schedule of the teacher and Price is charged for each private lesson
+type Settings struct {
+	TeacherID *uint      `gorm:"not null;uniqueIndex" conversor:"teacher_id"`
+	Buffer    *int64     `gorm:"not null;default:0" conversor:"buffer"`
+	Price     *int64     `gorm:"not null;default:0" conversor:"price"`
+	Currency  *string    `gorm:"type:char(3);not null;default:'BRL'" conversor:"currency"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+	UpdatedAt *time.Time `conversor:"updated_at"`
+	Teacher   *user.User `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of booking settings
+func (Settings) TableName() string {
+	return "booking_settings"
+}
+
+// Booking struct defines the fields of booking table. A private lesson is
+// a class of a single student, enrolled on booking, with a single schedule
+type Booking struct {
+	TeacherID  *uint           `gorm:"not null;index" conversor:"teacher_id"`
+	StudentID  *uint           `gorm:"not null;index" conversor:"student_id"`
+	ClassID    *uint           `gorm:"not null;uniqueIndex" conversor:"class_id"`
+	ScheduleID *uint           `gorm:"not null" conversor:"schedule_id"`
+	Start      *time.Time      `gorm:"column:starts_at;type:timestamptz;not null" conversor:"start"`
+	End        *time.Time      `gorm:"column:ends_at;type:timestamptz;not null" conversor:"end"`
+	ID         *uint           `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time      `conversor:"created_at"`
+	Teacher    *user.User      `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Student    *user.User      `gorm:"foreignKey:StudentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Class      *class.Class    `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Schedule   *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Filter defines the options used when listing bookings
+type Filter struct {
+	TeacherID *uint
+	StudentID *uint
+}
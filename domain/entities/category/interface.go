@@ -0,0 +1,18 @@
+package category
+
+import "gorm.io/gorm"
+
+// ICategory interface defines the methods that Category repository must implement
+type ICategory interface {
+	Add(*Category, *gorm.DB) error
+	Update(*Category, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Category, *gorm.DB) error
+	GetAll(*[]Category, *gorm.DB) error
+	LockTree(*gorm.DB) error
+	InSubtree(uint, uint, *gorm.DB) (bool, error)
+	CountChildren(uint, *gorm.DB) (int64, error)
+	CountClasses(uint, *gorm.DB) (int64, error)
+	Counts(*[]Count, []string, *gorm.DB) error
+	Tags(*[]TagCount, []string, int, *gorm.DB) error
+}
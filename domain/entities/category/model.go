@@ -0,0 +1,30 @@
+package category
+
+import "time"
+
+// Category struct defines the fields of category table. Categories form
+// a tree through ParentID, e.g. Guitar under Music, and Slug identifies
+// them on URLs
+type Category struct {
+	ParentID  *uint      `gorm:"index" conversor:"parent_id"`
+	Name      *string    `gorm:"not null" conversor:"name"`
+	Slug      *string    `gorm:"not null;uniqueIndex" conversor:"slug"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+	UpdatedAt *time.Time `conversor:"updated_at"`
+	Parent    *Category  `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// Count holds how many classes with the given statuses are filed directly
+// under a category and under its whole subtree
+type Count struct {
+	CategoryID uint
+	Classes    int64
+	Total      int64
+}
+
+// TagCount holds how many classes with the given statuses carry a tag
+type TagCount struct {
+	Name  string
+	Count int64
+}
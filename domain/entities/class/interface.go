@@ -1,10 +1,49 @@
 package class
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 // IClass interface defines the methods that Class repository must implement
 type IClass interface {
-	Add(*Class) error
-	Update(*Class) error
-	Delete(int64) error
-	Get(*Class) error
-	GetAll(interface{}) error
+	Add(*Class, *gorm.DB) error
+	Update(*Class, *gorm.DB) error
+	ClearLocation(uint, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Class, *Filter, *gorm.DB) error
+	GetAll(*[]Class, *Filter, *gorm.DB) error
+	Lock(*Class, *gorm.DB) error
+	AddStatusEvent(*StatusEvent, *gorm.DB) error
+	GetStatusEvents(*[]StatusEvent, uint, *gorm.DB) error
+	SetTags(uint, []string, *gorm.DB) error
+}
+
+// ISchedule interface defines the methods that Schedule repository must implement
+type ISchedule interface {
+	Add(*Schedule, *gorm.DB) error
+	Update(*Schedule, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Schedule, *gorm.DB) error
+	GetAll(*[]Schedule, uint, *gorm.DB) error
+	Conflicts(*Schedule, *[]Schedule, *gorm.DB) error
+	Occurrences(uint, time.Time, time.Time, *[]Schedule, *gorm.DB) error
+	DeleteOccurrencesFrom(uint, time.Time, *gorm.DB) error
+	Find(*[]Schedule, *ScheduleFilter, *gorm.DB) error
+	GetWithDeleted(*Schedule, *gorm.DB) error
+	AddEvent(*ScheduleEvent, *gorm.DB) error
+	GetEvents(*[]ScheduleEvent, uint, *gorm.DB) error
+}
+
+// IRecurrence interface defines the methods that Recurrence repository must implement
+type IRecurrence interface {
+	Add(*Recurrence, *gorm.DB) error
+	Update(*Recurrence, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Recurrence, *gorm.DB) error
+	Lock(*Recurrence, *gorm.DB) error
+	GetAll(*[]Recurrence, uint, *gorm.DB) error
+	Pending(*[]Recurrence, time.Time, *gorm.DB) error
+	SaveException(*RecurrenceException, *gorm.DB) error
 }
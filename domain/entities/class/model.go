@@ -1,31 +1,238 @@
 package class
 
 import (
+	"go-api/domain/entities/category"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Class struct defines the fields of class table
+const (
+	// DefaultRefundFullHours is how many hours before the first schedule
+	// a student may cancel with a full refund when the class doesn't say
+	DefaultRefundFullHours = 24
+	// DefaultRefundPartialPercent is the percentage refunded on later
+	// cancellations when the class doesn't say
+	DefaultRefundPartialPercent = 50
+)
+
+const (
+	// StatusDraft means the class is being prepared and only its teacher sees it
+	StatusDraft = "draft"
+	// StatusPublished means the class is open for enrollments
+	StatusPublished = "published"
+	// StatusFull means every seat of a published class is taken
+	StatusFull = "full"
+	// StatusArchived means the class no longer accepts enrollments
+	StatusArchived = "archived"
+)
+
+// Class struct defines the fields of class table. Price is
+// stored in the minor unit of its ISO 4217 currency. The cancellation
+// policy refunds students fully until RefundFullHours before the first
+// schedule and RefundPartialPercent of the price after that. TimeZone
+// is the IANA zone the schedules of the class are written in. Status
+// defaults to published on the database as the classes created before
+// statuses existed were open, new classes start as drafts. A class may
+// be filed under a category and labeled with free-form tags. In-person
+// classes have the address they happen at, with its coordinates in degrees
 type Class struct {
-	Name      *string         `gorm:"not null" conversor:"name"`
-	Price     *int64          `gorm:"not null" conversor:"price"`
-	TeacherID *uint           `conversor:"teacher_id"`
-	ID        *uint           `gorm:"primaryKey" conversor:"id"`
-	CreatedAt *time.Time      `conversor:"created_at"`
-	UpdatedAt *time.Time      `conversor:"updated_at"`
-	DeletedAt *gorm.DeletedAt `gorm:"index" conversor:"deleted_at"`
-	// Schedules []Schedule `gorm:"foreignKey:ClassID"`
+	Name        *string            `gorm:"not null" conversor:"name"`
+	Description *string            `conversor:"description"`
+	Price       *int64             `gorm:"not null" conversor:"price"`
+	Currency    *string            `gorm:"type:char(3);not null;default:'BRL'" conversor:"currency"`
+	Capacity    *int64             `conversor:"capacity"`
+	TimeZone    *string            `gorm:"not null;default:'America/Fortaleza'" conversor:"time_zone"`
+	Status      *string            `gorm:"not null;default:'published'" conversor:"status"`
+	TeacherID   *uint              `gorm:"not null;index" conversor:"teacher_id"`
+	CategoryID  *uint              `gorm:"index" conversor:"category_id"`
+	Address     *string            `conversor:"address"`
+	Latitude    *float64           `conversor:"latitude"`
+	Longitude   *float64           `conversor:"longitude"`
+	ID          *uint              `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time         `conversor:"created_at"`
+	UpdatedAt   *time.Time         `conversor:"updated_at"`
+	DeletedAt   *gorm.DeletedAt    `gorm:"index" conversor:"deleted_at"`
+	Teacher     *Teacher           `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Schedules   []Schedule         `gorm:"foreignKey:ClassID"`
+	Category    *category.Category `gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Tags        []Tag              `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	RefundFullHours      *int64 `conversor:"refund_full_hours"`
+	RefundPartialPercent *int64 `conversor:"refund_partial_percent"`
+
+	// RatingAverage and RatingCount summarize the visible reviews of
+	// the class and are kept in sync by the review business logic
+	RatingAverage *float64 `gorm:"type:numeric(3,2);not null;default:0" conversor:"rating_average"`
+	RatingCount   *int64   `gorm:"not null;default:0" conversor:"rating_count"`
 }
 
-// Schedule defines the field of a class schedule
+const (
+	// ScheduleScheduled means the schedule keeps its original period
+	ScheduleScheduled = "scheduled"
+	// ScheduleRescheduled means the schedule was moved to another period
+	ScheduleRescheduled = "rescheduled"
+	// ScheduleCanceled means the schedule was canceled, its row being soft deleted
+	ScheduleCanceled = "canceled"
+)
+
+// Schedule defines the field of a class schedule. The period column,
+// a tstzrange built from Start and End, is generated by the database
+// and TeacherID is kept in sync with the class by database triggers
 type Schedule struct {
-	Date      *string         `gorm:"not null" conversor:"date"`
-	Start     *string         `gorm:"not null" conversor:"start"`
-	End       *string         `gorm:"not null" conversor:"end"`
-	ClassID   *uint           `conversor:"class_id"`
+	Start     *time.Time      `gorm:"column:starts_at;type:timestamptz;not null" conversor:"start"`
+	End       *time.Time      `gorm:"column:ends_at;type:timestamptz;not null" conversor:"end"`
+	Status    *string         `gorm:"not null;default:'scheduled'" conversor:"status"`
+	ClassID   *uint           `gorm:"not null;index" conversor:"class_id"`
+	TeacherID *uint           `gorm:"index" conversor:"teacher_id"`
 	ID        *uint           `gorm:"primaryKey" conversor:"id"`
 	CreatedAt *time.Time      `conversor:"created_at"`
 	UpdatedAt *time.Time      `conversor:"updated_at"`
 	DeletedAt *gorm.DeletedAt `gorm:"index" conversor:"deleted_at"`
+	Class     *Class          `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// RecurrenceID and OccurrenceStart identify the occurrence of a
+	// recurrence that originated the schedule, even after it is moved
+	RecurrenceID    *uint       `gorm:"uniqueIndex:idx_schedules_occurrence" conversor:"recurrence_id"`
+	OccurrenceStart *time.Time  `gorm:"type:timestamptz;uniqueIndex:idx_schedules_occurrence" conversor:"occurrence_start"`
+	Recurrence      *Recurrence `gorm:"foreignKey:RecurrenceID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// StatusEvent records a change of status of a class and the user who
+// made it. ActorID is empty for the changes made by the system itself
+type StatusEvent struct {
+	ClassID   *uint      `gorm:"not null;index" conversor:"class_id"`
+	From      *string    `gorm:"column:from_status;not null" conversor:"from"`
+	To        *string    `gorm:"column:to_status;not null" conversor:"to"`
+	ActorID   *uint      `gorm:"index" conversor:"actor_id"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+	Class     *Class     `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of class status events
+func (StatusEvent) TableName() string {
+	return "class_status_events"
+}
+
+// ScheduleEvent records a change of status of a schedule along with the
+// reason given for it. Rescheduling keeps the previous period and canceling
+// keeps how the paid students were compensated
+type ScheduleEvent struct {
+	ScheduleID    *uint      `gorm:"not null;index" conversor:"schedule_id"`
+	Status        *string    `gorm:"not null" conversor:"status"`
+	Reason        *string    `conversor:"reason"`
+	PreviousStart *time.Time `gorm:"column:previous_starts_at;type:timestamptz" conversor:"previous_start"`
+	PreviousEnd   *time.Time `gorm:"column:previous_ends_at;type:timestamptz" conversor:"previous_end"`
+	Start         *time.Time `gorm:"column:starts_at;type:timestamptz;not null" conversor:"start"`
+	End           *time.Time `gorm:"column:ends_at;type:timestamptz;not null" conversor:"end"`
+	Compensation  *string    `conversor:"compensation"`
+	ID            *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt     *time.Time `conversor:"created_at"`
+	Schedule      *Schedule  `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Recurrence defines a recurring schedule of a class through an iCalendar
+// RRULE. Its occurrences are materialized as Schedule rows within a rolling
+// window, MaterializedUntil being the end of the window already covered
+type Recurrence struct {
+	RRule             *string               `gorm:"column:rrule;not null" conversor:"rrule"`
+	Start             *time.Time            `gorm:"column:starts_at;type:timestamptz;not null" conversor:"start"`
+	Duration          *int64                `gorm:"not null" conversor:"duration"`
+	ClassID           *uint                 `gorm:"not null;index" conversor:"class_id"`
+	MaterializedUntil *time.Time            `gorm:"type:timestamptz" conversor:"materialized_until"`
+	ID                *uint                 `gorm:"primaryKey" conversor:"id"`
+	CreatedAt         *time.Time            `conversor:"created_at"`
+	UpdatedAt         *time.Time            `conversor:"updated_at"`
+	DeletedAt         *gorm.DeletedAt       `gorm:"index" conversor:"deleted_at"`
+	Class             *Class                `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Exceptions        []RecurrenceException `gorm:"foreignKey:RecurrenceID"`
 }
+
+// RecurrenceException records a single occurrence of a
+// recurrence that was canceled or moved to another period
+type RecurrenceException struct {
+	RecurrenceID    *uint       `gorm:"not null;uniqueIndex:idx_recurrence_exceptions_occurrence" conversor:"recurrence_id"`
+	OccurrenceStart *time.Time  `gorm:"type:timestamptz;not null;uniqueIndex:idx_recurrence_exceptions_occurrence" conversor:"occurrence_start"`
+	Canceled        *bool       `gorm:"not null;default:false" conversor:"canceled"`
+	Start           *time.Time  `gorm:"column:starts_at;type:timestamptz" conversor:"start"`
+	End             *time.Time  `gorm:"column:ends_at;type:timestamptz" conversor:"end"`
+	ID              *uint       `gorm:"primaryKey" conversor:"id"`
+	CreatedAt       *time.Time  `conversor:"created_at"`
+	UpdatedAt       *time.Time  `conversor:"updated_at"`
+	Recurrence      *Recurrence `gorm:"foreignKey:RecurrenceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Teacher is a read only projection of the users table, used for
+// loading the class teacher without importing the user domain package
+type Teacher struct {
+	ID        *uint   `gorm:"primaryKey" conversor:"id"`
+	Name      *string `conversor:"name"`
+	Email     *string `conversor:"email"`
+	AvatarURL *string `conversor:"avatar_url"`
+	Bio       *string `conversor:"bio"`
+
+	RatingAverage *float64 `conversor:"rating_average"`
+	RatingCount   *int64   `conversor:"rating_count"`
+}
+
+// TableName points the Teacher projection to the users table
+func (Teacher) TableName() string {
+	return "users"
+}
+
+// Tag struct defines the fields of class tag table, a free-form label
+// of a class. Names are stored normalized to lower case
+type Tag struct {
+	ClassID   *uint      `gorm:"not null;uniqueIndex:idx_class_tags_name" conversor:"class_id"`
+	Name      *string    `gorm:"not null;uniqueIndex:idx_class_tags_name;index" conversor:"name"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+}
+
+// TableName sets the table name of class tags
+func (Tag) TableName() string {
+	return "class_tags"
+}
+
+// Point is a position on the Earth in degrees
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Filter defines the options used when fetching classes. CategoryID
+// matches its whole subtree and the classes must carry every tag of Tags.
+// Near restricts the classes to the ones within RadiusKm of the point,
+// the closest first
+type Filter struct {
+	TeacherID  *uint
+	CategoryID *uint
+	Tags       []string
+	Near       *Point
+	RadiusKm   float64
+	Status     []string
+	Include    []string
+}
+
+// ScheduleFilter defines the options used when listing schedules across classes
+type ScheduleFilter struct {
+	ClassID   *uint
+	TeacherID *uint
+	// AttendeeID lists the schedules of the classes the user
+	// teaches or is enrolled in
+	AttendeeID *uint
+	EndsAfter  *time.Time
+	// WithDeleted also lists the canceled schedules
+	WithDeleted bool
+}
+
+const (
+	// IncludeTeacher preloads the class teacher
+	IncludeTeacher = "Teacher"
+	// IncludeSchedules preloads the class schedules
+	IncludeSchedules = "Schedules"
+	// IncludeCategory preloads the class category
+	IncludeCategory = "Category"
+	// IncludeTags preloads the class tags
+	IncludeTags = "Tags"
+)
@@ -0,0 +1,18 @@
+package coupon
+
+import "gorm.io/gorm"
+
+// ICoupon interface defines the methods that Coupon repository must implement
+type ICoupon interface {
+	Add(*Coupon, *gorm.DB) error
+	Update(*Coupon, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Coupon, *gorm.DB) error
+	GetByCode(*Coupon, *gorm.DB) error
+	GetAll(*[]Coupon, *gorm.DB) error
+	Lock(*Coupon, *gorm.DB) error
+	SetRestrictions(uint, []Restriction, *gorm.DB) error
+	CountRedemptions(uint, *uint, *gorm.DB) (int64, error)
+	AddRedemption(*Redemption, *gorm.DB) error
+	ReleaseRedemption(uint, *gorm.DB) error
+}
@@ -0,0 +1,73 @@
+package coupon
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// KindPercentage discounts a percentage of the class price
+	KindPercentage = "percentage"
+	// KindFixed discounts a fixed amount of the class price currency
+	KindFixed = "fixed"
+)
+
+// Coupon struct defines the fields of coupon table. Codes are stored
+// in upper case and a coupon without restrictions applies to any class
+type Coupon struct {
+	Code           *string    `gorm:"not null" conversor:"code"`
+	Kind           *string    `gorm:"not null" conversor:"kind"`
+	Percentage     *int64     `conversor:"percentage"`
+	Amount         *int64     `conversor:"amount"`
+	Currency       *string    `gorm:"type:char(3)" conversor:"currency"`
+	StartsAt       *time.Time `conversor:"starts_at"`
+	EndsAt         *time.Time `conversor:"ends_at"`
+	MaxUses        *int64     `conversor:"max_uses"`
+	MaxUsesPerUser *int64     `conversor:"max_uses_per_user"`
+	ID             *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt      *time.Time `conversor:"created_at"`
+	UpdatedAt      *time.Time `conversor:"updated_at"`
+	DeletedAt      gorm.DeletedAt
+	Restrictions   []Restriction `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Restriction struct defines the fields of coupon restriction table,
+// limiting a coupon to a class or to the classes of a teacher
+type Restriction struct {
+	CouponID  *uint        `gorm:"not null;index" conversor:"coupon_id"`
+	ClassID   *uint        `gorm:"index" conversor:"class_id"`
+	TeacherID *uint        `gorm:"index" conversor:"teacher_id"`
+	ID        *uint        `gorm:"primaryKey" conversor:"id"`
+	Class     *class.Class `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Teacher   *user.User   `gorm:"foreignKey:TeacherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of coupon restrictions
+func (Restriction) TableName() string {
+	return "coupon_restrictions"
+}
+
+// Redemption struct defines the fields of coupon redemption table,
+// recording each use of a coupon by an user on a class. Redemptions of
+// orders that failed or were refunded are released and no longer count
+type Redemption struct {
+	CouponID   *uint        `gorm:"not null;index" conversor:"coupon_id"`
+	UserID     *uint        `gorm:"not null;index" conversor:"user_id"`
+	ClassID    *uint        `gorm:"not null" conversor:"class_id"`
+	Discount   *int64       `gorm:"not null" conversor:"discount"`
+	Currency   *string      `gorm:"type:char(3);not null" conversor:"currency"`
+	ReleasedAt *time.Time   `conversor:"released_at"`
+	ID         *uint        `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time   `conversor:"created_at"`
+	Coupon     *Coupon      `gorm:"foreignKey:CouponID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Class      *class.Class `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	User       *user.User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// TableName sets the table name of coupon redemptions
+func (Redemption) TableName() string {
+	return "coupon_redemptions"
+}
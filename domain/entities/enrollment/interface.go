@@ -0,0 +1,13 @@
+package enrollment
+
+import "gorm.io/gorm"
+
+// IEnrollment interface defines the methods that Enrollment repository must implement
+type IEnrollment interface {
+	Add(*Enrollment, *gorm.DB) error
+	Update(*Enrollment, *gorm.DB) error
+	GetActive(*Enrollment, *gorm.DB) error
+	GetAll(*[]Enrollment, *Filter, *gorm.DB) error
+	Count(uint, string, *gorm.DB) (int64, error)
+	NextWaitlisted(*Enrollment, uint, *gorm.DB) error
+}
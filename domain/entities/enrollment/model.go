@@ -0,0 +1,38 @@
+package enrollment
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// StatusEnrolled means the student holds a seat in the class
+	StatusEnrolled = "enrolled"
+	// StatusWaitlisted means the student waits for a seat to be freed
+	StatusWaitlisted = "waitlisted"
+	// StatusCanceled means the student left the class or the waitlist
+	StatusCanceled = "canceled"
+)
+
+// Enrollment struct defines the fields of enrollment table, linking a
+// student to a class. Canceled enrollments are kept for history
+type Enrollment struct {
+	ClassID    *uint        `gorm:"not null;index" conversor:"class_id"`
+	StudentID  *uint        `gorm:"not null;index" conversor:"student_id"`
+	Status     *string      `gorm:"not null" conversor:"status"`
+	EnrolledAt *time.Time   `conversor:"enrolled_at"`
+	CanceledAt *time.Time   `conversor:"canceled_at"`
+	ID         *uint        `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time   `conversor:"created_at"`
+	UpdatedAt  *time.Time   `conversor:"updated_at"`
+	Class      *class.Class `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Student    *user.User   `gorm:"foreignKey:StudentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Filter defines the options used when listing enrollments
+type Filter struct {
+	ClassID   *uint
+	StudentID *uint
+	Status    []string
+}
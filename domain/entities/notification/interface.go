@@ -0,0 +1,12 @@
+package notification
+
+import "gorm.io/gorm"
+
+// INotification interface defines the methods that Notification repository must implement
+type INotification interface {
+	Add(*Notification, *gorm.DB) error
+	GetAll(*[]Notification, *Filter, *gorm.DB) error
+	CountUnread(uint, *gorm.DB) (int64, error)
+	LastID(uint, *gorm.DB) (uint, error)
+	MarkRead(uint, []uint, *gorm.DB) error
+}
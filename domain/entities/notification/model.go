@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// KindSeatOpened tells a student of the waitlist that a seat was taken for them
+	KindSeatOpened = "seat_opened"
+	// KindReminder reminds students and teachers of an upcoming schedule
+	KindReminder = "reminder"
+	// KindBooked tells a teacher that a student booked a private lesson
+	KindBooked = "booked"
+	// KindScheduleCanceled tells the students of a class that schedules were canceled
+	KindScheduleCanceled = "schedule_canceled"
+	// KindRescheduled tells the students of a class that a schedule was moved
+	KindRescheduled = "rescheduled"
+
+	// Channel is the PostgreSQL channel notified, with the user ID as
+	// payload, when a notification is committed
+	Channel = "notifications"
+)
+
+// Notification struct defines the fields of notification table. ClassID
+// optionally points to the class the notification is about and ReadAt is
+// set once the user reads it
+type Notification struct {
+	UserID    *uint        `gorm:"not null;index:idx_notifications_user" conversor:"user_id"`
+	Kind      *string      `gorm:"not null" conversor:"kind"`
+	Title     *string      `gorm:"not null" conversor:"title"`
+	Body      *string      `conversor:"body"`
+	ClassID   *uint        `gorm:"index" conversor:"class_id"`
+	ReadAt    *time.Time   `conversor:"read_at"`
+	ID        *uint        `gorm:"primaryKey;index:idx_notifications_user" conversor:"id"`
+	CreatedAt *time.Time   `conversor:"created_at"`
+	User      *user.User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Class     *class.Class `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Filter defines the options used when listing notifications. With AfterID
+// only the newer notifications are listed, the oldest first, along with the
+// unread ones created from PendingSince that are not in Sent. IDs are taken
+// before commit, so these cover notifications committed after a newer one
+type Filter struct {
+	UserID       uint
+	Unread       bool
+	AfterID      *uint
+	PendingSince *time.Time
+	Sent         []uint
+	Limit        int
+	Offset       int
+}
@@ -0,0 +1,22 @@
+package order
+
+import "gorm.io/gorm"
+
+// IOrder interface defines the methods that Order repository must implement
+type IOrder interface {
+	Add(*Order, *gorm.DB) error
+	Update(*Order, *gorm.DB) error
+	Get(*Order, *gorm.DB) error
+	Lock(*Order, *gorm.DB) error
+	LockByExternalID(*Order, *gorm.DB) error
+	GetOpen(*Order, *gorm.DB) error
+	GetAll(*[]Order, *Filter, *gorm.DB) error
+	AddEvent(*Event, *gorm.DB) error
+	HasEvent(string, string, *gorm.DB) (bool, error)
+	AddEntry(*LedgerEntry, *gorm.DB) error
+	GetEntries(*[]LedgerEntry, uint, *gorm.DB) error
+	AddRefund(*Refund, *gorm.DB) error
+	UpdateRefund(*Refund, *gorm.DB) error
+	PendingRefunds(*[]Refund, int, *gorm.DB) error
+	Earnings(*[]Earning, *ReportFilter, *gorm.DB) error
+}
@@ -0,0 +1,166 @@
+package order
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/coupon"
+	"go-api/domain/entities/enrollment"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// StatusPending means the order waits for its payment
+	StatusPending = "pending"
+	// StatusPaid means the payment of the order was captured
+	StatusPaid = "paid"
+	// StatusFailed means the payment of the order was declined
+	StatusFailed = "failed"
+	// StatusRefunded means the payment of the order was given back
+	StatusRefunded = "refunded"
+)
+
+// Order struct defines the fields of order table, charging a student for an
+// enrollment. Amounts are stored in the minor unit of the order currency.
+// Refunded is given back through the provider.
+// FeeRate is the platform fee, in basis points, agreed when the order was placed.
+// RedemptionID is the use of the coupon, released if the order fails or is refunded
+type Order struct {
+	EnrollmentID *uint                  `gorm:"not null;index" conversor:"enrollment_id"`
+	ClassID      *uint                  `gorm:"not null;index" conversor:"class_id"`
+	UserID       *uint                  `gorm:"not null;index" conversor:"user_id"`
+	Status       *string                `gorm:"not null" conversor:"status"`
+	Price        *int64                 `gorm:"not null" conversor:"price"`
+	Discount     *int64                 `gorm:"not null;default:0" conversor:"discount"`
+	Amount       *int64                 `gorm:"not null" conversor:"amount"`
+	Refunded     *int64                 `gorm:"not null;default:0" conversor:"refunded"`
+	FeeRate      *int64                 `gorm:"not null;default:0" conversor:"fee_rate"`
+	Currency     *string                `gorm:"type:char(3);not null" conversor:"currency"`
+	CouponCode   *string                `conversor:"coupon_code"`
+	RedemptionID *uint                  `gorm:"index" conversor:"redemption_id"`
+	Provider     *string                `gorm:"not null" conversor:"provider"`
+	ExternalID   *string                `gorm:"index" conversor:"external_id"`
+	CheckoutURL  *string                `conversor:"checkout_url"`
+	PaidAt       *time.Time             `conversor:"paid_at"`
+	FailedAt     *time.Time             `conversor:"failed_at"`
+	RefundedAt   *time.Time             `conversor:"refunded_at"`
+	ID           *uint                  `gorm:"primaryKey" conversor:"id"`
+	CreatedAt    *time.Time             `conversor:"created_at"`
+	UpdatedAt    *time.Time             `conversor:"updated_at"`
+	Enrollment   *enrollment.Enrollment `gorm:"foreignKey:EnrollmentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Class        *class.Class           `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	User         *user.User             `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Redemption   *coupon.Redemption     `gorm:"foreignKey:RedemptionID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Events       []Event                `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Event struct defines the fields of order event table, recording the
+// webhook events received for an order so each one is applied only once
+type Event struct {
+	OrderID   *uint      `gorm:"not null;index" conversor:"order_id"`
+	Provider  *string    `gorm:"not null" conversor:"provider"`
+	EventID   *string    `gorm:"not null" conversor:"event_id"`
+	Status    *string    `gorm:"not null" conversor:"status"`
+	Applied   *bool      `gorm:"not null" conversor:"applied"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+}
+
+// TableName sets the table name of order events
+func (Event) TableName() string {
+	return "order_events"
+}
+
+const (
+	// EntryPayment records money paid by an user for an order
+	EntryPayment = "payment"
+	// EntryRefund records money given back to an user for an order
+	EntryRefund = "refund"
+)
+
+// LedgerEntry struct defines the fields of ledger entry table, recording
+// every financial movement of an user. Entries are never updated, the
+// amount is positive and its direction is given by the kind
+type LedgerEntry struct {
+	UserID      *uint      `gorm:"not null;index" conversor:"user_id"`
+	OrderID     *uint      `gorm:"not null;index" conversor:"order_id"`
+	Kind        *string    `gorm:"not null" conversor:"kind"`
+	Amount      *int64     `gorm:"not null" conversor:"amount"`
+	Currency    *string    `gorm:"type:char(3);not null" conversor:"currency"`
+	Description *string    `conversor:"description"`
+	ID          *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time `conversor:"created_at"`
+	Order       *Order     `gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	User        *user.User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+const (
+	// RefundPending means the refund waits to be sent to the provider
+	RefundPending = "pending"
+	// RefundCompleted means the provider gave the amount back
+	RefundCompleted = "completed"
+	// RefundFailed means the provider kept refusing the refund
+	RefundFailed = "failed"
+)
+
+// Refund struct defines the fields of order refund table, recording each
+// amount to be given back through the provider. Refunds are recorded by the
+// change that causes them and only sent once it is committed, so a rolled back
+// change never gives money back. The idempotency key is kept across attempts
+// so the provider executes each refund once
+type Refund struct {
+	OrderID        *uint      `gorm:"not null;index" conversor:"order_id"`
+	Amount         *int64     `gorm:"not null" conversor:"amount"`
+	Currency       *string    `gorm:"type:char(3);not null" conversor:"currency"`
+	Status         *string    `gorm:"not null;default:'pending'" conversor:"status"`
+	IdempotencyKey *string    `gorm:"not null;uniqueIndex" conversor:"idempotency_key"`
+	Attempts       *int64     `gorm:"not null;default:0" conversor:"attempts"`
+	LastError      *string    `conversor:"last_error"`
+	CompletedAt    *time.Time `conversor:"completed_at"`
+	ID             *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt      *time.Time `conversor:"created_at"`
+	UpdatedAt      *time.Time `conversor:"updated_at"`
+	Order          *Order     `gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// TableName sets the table name of order refunds
+func (Refund) TableName() string {
+	return "order_refunds"
+}
+
+// Filter defines the options used when listing orders
+type Filter struct {
+	UserID       *uint
+	ClassID      *uint
+	EnrollmentID *uint
+	Status       []string
+}
+
+// Earning aggregates the payments and refunds of a period, teacher or class
+// in a currency. Orders counts the orders paid in the period. Period,
+// TeacherID and ClassID are only set when grouped by them
+type Earning struct {
+	Period    *time.Time
+	TeacherID *uint
+	ClassID   *uint
+	ClassName *string
+	Currency  string
+	Orders    int64
+	Gross     int64
+	Refunded  int64
+	Fee       int64
+	Net       int64
+}
+
+// ReportFilter defines the options used when aggregating payments and
+// refunds. Period is the unit they are grouped by, none when empty, and
+// the periods start at midnight on TimeZone
+type ReportFilter struct {
+	TeacherID *uint
+	ClassID   *uint
+	From      time.Time
+	To        time.Time
+	Period    string
+	TimeZone  string
+	ByTeacher bool
+	ByClass   bool
+}
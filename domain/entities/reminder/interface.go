@@ -0,0 +1,11 @@
+package reminder
+
+import "gorm.io/gorm"
+
+// IReminder interface defines the methods that Reminder repository must implement
+type IReminder interface {
+	GetPreference(*Preference, *gorm.DB) error
+	SavePreference(*Preference, *gorm.DB) error
+	Pending(*[]Pending, *Filter, *gorm.DB) error
+	AddDelivery(*Delivery, *gorm.DB) (bool, error)
+}
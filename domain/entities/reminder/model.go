@@ -0,0 +1,69 @@
+package reminder
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// KindDayBefore is the reminder sent a day before a schedule starts
+	KindDayBefore = "day_before"
+	// KindHourBefore is the reminder sent an hour before a schedule starts
+	KindHourBefore = "hour_before"
+)
+
+// Preference struct defines the fields of reminder preference table,
+// holding which reminders an user wants. Users without a row get all of them
+type Preference struct {
+	UserID     *uint      `gorm:"not null;uniqueIndex" conversor:"user_id"`
+	DayBefore  *bool      `gorm:"not null;default:true" conversor:"day_before"`
+	HourBefore *bool      `gorm:"not null;default:true" conversor:"hour_before"`
+	ID         *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time `conversor:"created_at"`
+	UpdatedAt  *time.Time `conversor:"updated_at"`
+	User       *user.User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of reminder preferences
+func (Preference) TableName() string {
+	return "reminder_preferences"
+}
+
+// Delivery struct defines the fields of reminder delivery table. The
+// unique index guarantees each reminder reaches an user only once
+type Delivery struct {
+	ScheduleID *uint           `gorm:"not null;uniqueIndex:idx_reminder_deliveries_user" conversor:"schedule_id"`
+	UserID     *uint           `gorm:"not null;uniqueIndex:idx_reminder_deliveries_user;index" conversor:"user_id"`
+	Kind       *string         `gorm:"not null;uniqueIndex:idx_reminder_deliveries_user" conversor:"kind"`
+	ID         *uint           `gorm:"primaryKey" conversor:"id"`
+	CreatedAt  *time.Time      `conversor:"created_at"`
+	Schedule   *class.Schedule `gorm:"foreignKey:ScheduleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User       *user.User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of reminder deliveries
+func (Delivery) TableName() string {
+	return "reminder_deliveries"
+}
+
+// Pending is a reminder due to an user, either an enrolled student or the
+// teacher of the class, along with the time zones to show the start on
+type Pending struct {
+	ScheduleID    uint
+	UserID        uint
+	ClassID       uint
+	ClassName     string
+	StartsAt      time.Time
+	ClassTimeZone string
+	UserTimeZone  *string
+}
+
+// Filter defines the reminders of a kind due for the schedules
+// starting after From until To, at most Limit of them
+type Filter struct {
+	Kind  string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
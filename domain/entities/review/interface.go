@@ -0,0 +1,14 @@
+package review
+
+import "gorm.io/gorm"
+
+// IReview interface defines the methods that Review repository must implement
+type IReview interface {
+	Add(*Review, *gorm.DB) error
+	Update(*Review, *gorm.DB) error
+	Delete(uint, *gorm.DB) error
+	Get(*Review, *gorm.DB) error
+	GetAll(*[]Review, *Filter, *gorm.DB) error
+	AddFlag(*Flag, *gorm.DB) error
+	Summarize(*Rating, *Filter, *gorm.DB) error
+}
@@ -0,0 +1,66 @@
+package review
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/user"
+	"time"
+)
+
+const (
+	// StatusVisible means the review is listed and counts towards the ratings
+	StatusVisible = "visible"
+	// StatusHidden means the review was hidden by the moderation
+	StatusHidden = "hidden"
+)
+
+// Review struct defines the fields of review table. A student reviews
+// each class once and the teacher may reply to it. Flags counts the
+// reports of inappropriate content received by the review
+type Review struct {
+	ClassID     *uint        `gorm:"not null;uniqueIndex:idx_reviews_student" conversor:"class_id"`
+	StudentID   *uint        `gorm:"not null;uniqueIndex:idx_reviews_student;index" conversor:"student_id"`
+	Rating      *int64       `gorm:"not null" conversor:"rating"`
+	Text        *string      `conversor:"text"`
+	Reply       *string      `conversor:"reply"`
+	RepliedAt   *time.Time   `conversor:"replied_at"`
+	Status      *string      `gorm:"not null;default:'visible'" conversor:"status"`
+	Flags       *int64       `gorm:"not null;default:0" conversor:"flags"`
+	ModeratedAt *time.Time   `conversor:"moderated_at"`
+	ID          *uint        `gorm:"primaryKey" conversor:"id"`
+	CreatedAt   *time.Time   `conversor:"created_at"`
+	UpdatedAt   *time.Time   `conversor:"updated_at"`
+	Class       *class.Class `gorm:"foreignKey:ClassID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Student     *user.User   `gorm:"foreignKey:StudentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Flag struct defines the fields of review flag table,
+// recording an user reporting a review to the moderation
+type Flag struct {
+	ReviewID  *uint      `gorm:"not null;uniqueIndex:idx_review_flags_user" conversor:"review_id"`
+	UserID    *uint      `gorm:"not null;uniqueIndex:idx_review_flags_user" conversor:"user_id"`
+	Reason    *string    `gorm:"not null" conversor:"reason"`
+	ID        *uint      `gorm:"primaryKey" conversor:"id"`
+	CreatedAt *time.Time `conversor:"created_at"`
+	Review    *Review    `gorm:"foreignKey:ReviewID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User      *user.User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// TableName sets the table name of review flags
+func (Flag) TableName() string {
+	return "review_flags"
+}
+
+// Rating summarizes the visible reviews of a class or of a teacher
+type Rating struct {
+	Average float64
+	Count   int64
+}
+
+// Filter defines the options used when listing reviews
+type Filter struct {
+	ClassID   *uint
+	TeacherID *uint
+	Status    []string
+	// Flagged lists only the reviews reported at least once
+	Flagged bool
+}
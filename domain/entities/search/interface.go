@@ -0,0 +1,8 @@
+package search
+
+import "gorm.io/gorm"
+
+// ISearch interface defines the methods that Search repository must implement
+type ISearch interface {
+	Classes(*[]Result, *Filter, *gorm.DB) error
+}
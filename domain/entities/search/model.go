@@ -0,0 +1,54 @@
+package search
+
+import "time"
+
+const (
+	// ConfigPortuguese is the text search configuration for Portuguese,
+	// which also ignores accents
+	ConfigPortuguese = "portuguese_unaccent"
+	// ConfigEnglish is the text search configuration for English
+	ConfigEnglish = "english"
+)
+
+// Result is a class matching a search along with its teacher, the rank of
+// the match and the matched snippets of each searched field
+type Result struct {
+	ClassID       uint
+	Name          string
+	Description   *string
+	Price         int64
+	Currency      string
+	Capacity      *int64
+	TimeZone      string
+	TeacherID     uint
+	TeacherName   string
+	RatingAverage float64
+	RatingCount   int64
+	NextStart     *time.Time
+	Rank          float64
+
+	NameHighlight        *string
+	DescriptionHighlight *string
+	TeacherHighlight     *string
+	BioHighlight         *string
+}
+
+// Filter defines the options of a search. Classes are matched against
+// Query on both configurations and Config is the one used to highlight
+// the snippets. From and To restrict the classes to the ones with a
+// schedule starting in the period. CategoryID matches its whole subtree
+// and the classes must carry every tag of Tags
+type Filter struct {
+	Query      string
+	Config     string
+	MinPrice   *int64
+	MaxPrice   *int64
+	Currency   *string
+	TeacherID  *uint
+	CategoryID *uint
+	Tags       []string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
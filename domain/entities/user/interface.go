@@ -5,8 +5,9 @@ import "gorm.io/gorm"
 // IUser interface defines the methods that User repository must implement
 type IUser interface {
 	Add(*User, *gorm.DB) error
-	Update(*User) error
+	Update(*User, *gorm.DB) error
 	Delete(int64) error
-	Get(*User) error
+	Get(*User, *gorm.DB) error
+	Lock(*User, *gorm.DB) error
 	GetAll(interface{}) error
 }
@@ -1,12 +1,16 @@
 package user
 
 import (
+	"go-api/domain/entities/class"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// User struct defines the fields of user table1
+// User struct defines the fields of user table1. RatingAverage and
+// RatingCount summarize the reviews of the classes taught by the user.
+// TimeZone is the IANA zone the user prefers to see dates in. APIToken is the
+// secret the user authenticates with, while CalendarToken only reads the feed
 type User struct {
 	Name          *string         `gorm:"not null" conversor:"name"`
 	Email         *string         `gorm:"unique; not null" conversor:"email"`
@@ -15,9 +19,14 @@ type User struct {
 	AvatarURL     *string         `conversor:"avatar_url"`
 	ContactNumber *string         `conversor:"contact_number"`
 	Bio           *string         `conversor:"bio"`
+	APIToken      *string         `gorm:"uniqueIndex" conversor:"api_token"`
+	CalendarToken *string         `gorm:"uniqueIndex" conversor:"calendar_token"`
+	TimeZone      *string         `conversor:"time_zone"`
+	RatingAverage *float64        `gorm:"type:numeric(3,2);not null;default:0" conversor:"rating_average"`
+	RatingCount   *int64          `gorm:"not null;default:0" conversor:"rating_count"`
 	ID            *uint           `gorm:"primaryKey" conversor:"id"`
 	CreatedAt     *time.Time      `conversor:"created_at"`
 	UpdatedAt     *time.Time      `conversor:"updated_at"`
 	DeletedAt     *gorm.DeletedAt `gorm:"index" conversor:"deleted_at"`
-	// Classes       []class.Class  `gorm:"foreignKey:TeacherID"`
+	Classes       []class.Class   `gorm:"foreignKey:TeacherID"`
 }
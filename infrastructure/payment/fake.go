@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"go-api/utils"
+	"sync"
+)
+
+// FakeName is the name of the in-process provider
+const FakeName = "fake"
+
+// Fake is an in-process payment provider for tests and local development.
+// Payments stay pending until settled through Settle
+type Fake struct {
+	secret   string
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	// keys maps the idempotency keys to the payments created with them
+	keys map[string]string
+}
+
+type fakePayment struct {
+	amount   utils.Money
+	refunded int64
+	// refunds holds the idempotency keys of the refunds already made
+	refunds map[string]bool
+}
+
+// fakeEvent is the webhook payload used by the fake
+// and the http providers
+type fakeEvent struct {
+	ID        string `json:"id"`
+	PaymentID string `json:"payment_id"`
+	Status    string `json:"status"`
+}
+
+// NewFake creates a fake provider signing its events with the given secret
+func NewFake(secret string) *Fake {
+	return &Fake{secret: secret, payments: map[string]*fakePayment{}, keys: map[string]string{}}
+}
+
+// Name identifies the provider
+func (f *Fake) Name() string {
+	return FakeName
+}
+
+// CreatePayment registers a pending payment, or returns the one
+// created before with the same idempotency key
+func (f *Fake) CreatePayment(charge *Charge) (*Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id, ok := f.keys[charge.IdempotencyKey]
+
+	if !ok || charge.IdempotencyKey == "" {
+		token, err := utils.RandomToken(12)
+		if err != nil {
+			return nil, err
+		}
+
+		id = "fake_" + token
+		f.payments[id] = &fakePayment{amount: charge.Amount, refunds: map[string]bool{}}
+
+		if charge.IdempotencyKey != "" {
+			f.keys[charge.IdempotencyKey] = id
+		}
+	}
+
+	return &Payment{ExternalID: id, Status: StatusPending, CheckoutURL: "/v1/payments/fake/" + id}, nil
+}
+
+// Refund refunds part or the whole of a payment, once per idempotency key
+func (f *Fake) Refund(externalID string, amount utils.Money, idempotencyKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// payments created before a restart are unknown and refunded as is
+	p, ok := f.payments[externalID]
+	if !ok || p.refunds[idempotencyKey] {
+		return nil
+	}
+
+	if amount.Currency != p.amount.Currency || p.refunded+amount.Amount > p.amount.Amount {
+		return errors.New("refund exceeds payment " + externalID)
+	}
+
+	p.refunded += amount.Amount
+	p.refunds[idempotencyKey] = true
+	return nil
+}
+
+// ParseWebhook decodes an event signed with the shared secret
+func (f *Fake) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	return parseEvent(f.secret, payload, signature)
+}
+
+// Settle simulates the provider notifying a new status for a payment,
+// returning the signed webhook payload it would send
+func (f *Fake) Settle(externalID, status string) (payload []byte, signature string, err error) {
+	token, err := utils.RandomToken(12)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err = json.Marshal(fakeEvent{ID: "evt_" + token, PaymentID: externalID, Status: status})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, utils.SignPayload(f.secret, payload), nil
+}
+
+// parseEvent verifies and decodes a webhook payload
+func parseEvent(secret string, payload []byte, signature string) (*Event, error) {
+	if err := verify(secret, payload, signature); err != nil {
+		return nil, err
+	}
+
+	var in fakeEvent
+
+	if err := json.Unmarshal(payload, &in); err != nil {
+		return nil, err
+	}
+
+	switch in.Status {
+	case StatusPending, StatusPaid, StatusFailed, StatusRefunded:
+	default:
+		return nil, errors.New("unknown payment status " + in.Status)
+	}
+
+	return &Event{ID: in.ID, ExternalID: in.PaymentID, Status: in.Status}, nil
+}
@@ -0,0 +1,112 @@
+package payment
+
+import (
+	"encoding/json"
+	"go-api/utils"
+	"net/url"
+)
+
+// HTTPName is the name of the provider reached through its REST API
+const HTTPName = "http"
+
+// HTTP is a payment provider reached through a REST API. It creates
+// payments on POST /payments and refunds them on POST /payments/:id/refunds,
+// authenticating with a bearer API key
+type HTTP struct {
+	client *utils.HTTPClient
+	apiKey string
+	secret string
+}
+
+type httpPaymentRequest struct {
+	Reference   string `json:"reference"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+}
+
+type httpPaymentResponse struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	CheckoutURL string `json:"checkout_url"`
+}
+
+type httpRefundRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// httpError is the error body returned by the provider
+type httpError struct {
+	Message string `json:"message"`
+}
+
+func (e *httpError) Error() string {
+	return e.Message
+}
+
+// NewHTTP creates a provider for the API on the given base URL
+func NewHTTP(baseURL, apiKey, secret string) *HTTP {
+	return &HTTP{
+		client: utils.NewHTTPClient(baseURL).WithName("pagamentos").WithTimeout(30),
+		apiKey: apiKey,
+		secret: secret,
+	}
+}
+
+// Name identifies the provider
+func (h *HTTP) Name() string {
+	return HTTPName
+}
+
+func (h *HTTP) headers(idempotencyKey string) map[string][]string {
+	headers := map[string][]string{
+		"Authorization": {"Bearer " + h.apiKey},
+		"Content-Type":  {"application/json"},
+	}
+	if idempotencyKey != "" {
+		headers["Idempotency-Key"] = []string{idempotencyKey}
+	}
+	return headers
+}
+
+// CreatePayment registers a charge on the provider, sending
+// its idempotency key so retries don't create another payment
+func (h *HTTP) CreatePayment(charge *Charge) (*Payment, error) {
+	body, err := json.Marshal(httpPaymentRequest{
+		Reference:   charge.Reference,
+		Amount:      charge.Amount.Amount,
+		Currency:    charge.Amount.Currency,
+		Description: charge.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out httpPaymentResponse
+
+	if err = h.client.Post("criar pagamento", "/payments", h.headers(charge.IdempotencyKey), body, &out, &httpError{}); err != nil {
+		return nil, err
+	}
+
+	return &Payment{ExternalID: out.ID, Status: out.Status, CheckoutURL: out.CheckoutURL}, nil
+}
+
+// Refund refunds an amount of a payment on the provider
+func (h *HTTP) Refund(externalID string, amount utils.Money, idempotencyKey string) error {
+	uri := "/payments/" + url.PathEscape(externalID) + "/refunds"
+
+	body, err := json.Marshal(httpRefundRequest{Amount: amount.Amount, Currency: amount.Currency})
+	if err != nil {
+		return err
+	}
+
+	var out map[string]interface{}
+
+	return h.client.Post("estornar pagamento", uri, h.headers(idempotencyKey), body, &out, &httpError{})
+}
+
+// ParseWebhook decodes an event signed with the shared secret
+func (h *HTTP) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	return parseEvent(h.secret, payload, signature)
+}
@@ -0,0 +1,96 @@
+package payment
+
+import (
+	"errors"
+	"go-api/config"
+	"go-api/utils"
+)
+
+const (
+	// StatusPending means the payment is waiting for the customer
+	StatusPending = "pending"
+	// StatusPaid means the payment was captured
+	StatusPaid = "paid"
+	// StatusFailed means the payment was declined or abandoned
+	StatusFailed = "failed"
+	// StatusRefunded means the payment was given back to the customer
+	StatusRefunded = "refunded"
+)
+
+// ErrInvalidSignature is returned when a webhook payload
+// wasn't signed with the shared secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Charge describes a payment to be created on a provider
+type Charge struct {
+	// Reference identifies the order on our side
+	Reference string
+	// IdempotencyKey makes the provider return the payment already
+	// created with the same key instead of charging twice
+	IdempotencyKey string
+	Amount         utils.Money
+	Description    string
+}
+
+// Payment describes a payment as known by a provider
+type Payment struct {
+	ExternalID  string
+	Status      string
+	CheckoutURL string
+}
+
+// Event is a payment status update notified by a provider
+type Event struct {
+	ID         string
+	ExternalID string
+	Status     string
+}
+
+// PaymentProvider defines the operations a payment gateway must implement
+type PaymentProvider interface {
+	// Name identifies the provider on the stored orders
+	Name() string
+	// CreatePayment registers a charge, returning the payment to be completed by the customer
+	CreatePayment(*Charge) (*Payment, error)
+	// Refund gives back the given amount of a captured payment. Retries with
+	// the same idempotency key must not give the amount back twice
+	Refund(externalID string, amount utils.Money, idempotencyKey string) error
+	// ParseWebhook checks the signature of a webhook payload and decodes its event
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
+
+// New builds the payment provider chosen in the configuration. The fake
+// provider settles payments on request of anyone, so it is only available
+// in dev mode, signing its events with a random secret when none is given
+func New(cfg config.PaymentConfig, devMode bool) (PaymentProvider, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, errors.New("payment provider is required")
+	case FakeName:
+		if !devMode {
+			return nil, errors.New("the fake payment provider is only available in dev mode")
+		}
+		if cfg.WebhookSecret == "" {
+			secret, err := utils.RandomToken(32)
+			if err != nil {
+				return nil, err
+			}
+			cfg.WebhookSecret = secret
+		}
+		return NewFake(cfg.WebhookSecret), nil
+	case HTTPName:
+		if cfg.BaseURL == "" {
+			return nil, errors.New("payment base_url is required for the http provider")
+		}
+		return NewHTTP(cfg.BaseURL, cfg.APIKey, cfg.WebhookSecret), nil
+	}
+	return nil, errors.New("unknown payment provider " + cfg.Provider)
+}
+
+// verify checks a hex encoded HMAC-SHA256 signature of a payload
+func verify(secret string, payload []byte, signature string) error {
+	if secret == "" || !utils.SameToken(utils.SignPayload(secret, payload), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"go-api/domain/entities/attachment"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+)
+
+// PGAttachment is a base structure
+// that implements methods for query execution
+type PGAttachment struct {
+	DB *gorm.DB
+}
+
+// Add insert an attachment into the database
+func (pg *PGAttachment) Add(in *attachment.Attachment) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete removes an attachment by its ID
+func (pg *PGAttachment) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&attachment.Attachment{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches an attachment by its ID
+func (pg *PGAttachment) Get(in *attachment.Attachment) (err error) {
+	if err = pg.DB.Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the attachments matching the filter, the newest first
+func (pg *PGAttachment) GetAll(out *[]attachment.Attachment, filter *attachment.Filter) (err error) {
+	db := pg.DB
+
+	if filter.ClassID != nil {
+		db = db.Where("class_id = ?", filter.ClassID)
+	}
+
+	if filter.ScheduleID != nil {
+		db = db.Where("schedule_id = ?", filter.ScheduleID)
+	}
+
+	if err = db.Order("created_at DESC, id DESC").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package postgres
+
+// Migrations holds the schema changes for attachments
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE attachments ADD CONSTRAINT chk_attachments_size CHECK (size >= 0);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
@@ -0,0 +1,36 @@
+package attachment
+
+import (
+	"go-api/domain/entities/attachment"
+	"go-api/infrastructure/persistance/attachment/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IAttachment methods
+type Repository struct{}
+
+// Add is a function that manage the flow of attachment insertion into database
+func (r *Repository) Add(in *attachment.Attachment, db *gorm.DB) error {
+	data := postgres.PGAttachment{DB: db}
+	return data.Add(in)
+}
+
+// Delete removes an attachment
+func (r *Repository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGAttachment{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns an attachment by its ID
+func (r *Repository) Get(in *attachment.Attachment, db *gorm.DB) error {
+	data := postgres.PGAttachment{DB: db}
+	return data.Get(in)
+}
+
+// GetAll list the attachments matching the filter
+func (r *Repository) GetAll(out *[]attachment.Attachment, filter *attachment.Filter, db *gorm.DB) error {
+	data := postgres.PGAttachment{DB: db}
+	return data.GetAll(out, filter)
+}
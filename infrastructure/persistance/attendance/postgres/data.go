@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"go-api/domain/entities/attendance"
+	"go-api/domain/entities/enrollment"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGAttendance is a base structure
+// that implements methods for query execution
+type PGAttendance struct {
+	DB *gorm.DB
+}
+
+// Save inserts the attendance of a student in a schedule
+// or replaces it when the student was already marked
+func (pg *PGAttendance) Save(in *attendance.Attendance) (err error) {
+	if err = pg.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "schedule_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "note", "checked_in_at", "updated_at"}),
+	}).Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches the attendance of a student in a schedule
+func (pg *PGAttendance) Get(in *attendance.Attendance) (err error) {
+	if err = pg.DB.Where("schedule_id = ? AND student_id = ?", in.ScheduleID, in.StudentID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the attendances of a schedule
+func (pg *PGAttendance) GetAll(out *[]attendance.Attendance, scheduleID uint) (err error) {
+	if err = pg.DB.Where("schedule_id = ?", scheduleID).Order("student_id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SaveCode inserts the check in code of a schedule or replaces the current one
+func (pg *PGAttendance) SaveCode(in *attendance.CheckInCode) (err error) {
+	if err = pg.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "schedule_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"code", "updated_at"}),
+	}).Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetCode fetches the check in code of a schedule
+func (pg *PGAttendance) GetCode(in *attendance.CheckInCode) (err error) {
+	if err = pg.DB.Where("schedule_id = ?", in.ScheduleID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// LockAttempts fetches the failed check in attempts of a student in a
+// schedule, creating the counter when missing. The upsert keeps the row
+// locked, so concurrent attempts of the same student are serialized
+func (pg *PGAttendance) LockAttempts(in *attendance.CheckInAttempt) (err error) {
+	if err = pg.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "schedule_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
+	}).Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+
+	if err = pg.DB.Where("schedule_id = ? AND student_id = ?", in.ScheduleID, in.StudentID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddFailure increments the failed check in attempts of a student in a schedule
+func (pg *PGAttendance) AddFailure(in *attendance.CheckInAttempt) (err error) {
+	if err = pg.DB.Model(&attendance.CheckInAttempt{}).Where("id = ?", in.ID).
+		UpdateColumn("failures", gorm.Expr("failures + 1")).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// ResetAttempts deletes the failed check in attempts of a schedule
+func (pg *PGAttendance) ResetAttempts(scheduleID uint) (err error) {
+	if err = pg.DB.Where("schedule_id = ?", scheduleID).Delete(&attendance.CheckInAttempt{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Attended reports whether the student was present, even if late,
+// in at least one schedule of the class
+func (pg *PGAttendance) Attended(classID, studentID uint) (bool, error) {
+	var count int64
+
+	if err := pg.DB.Table("attendances a").
+		Joins("JOIN schedules s ON s.id = a.schedule_id").
+		Where("s.class_id = ? AND a.student_id = ? AND a.status IN ?",
+			classID, studentID, []string{attendance.StatusPresent, attendance.StatusLate}).
+		Count(&count).Error; err != nil {
+		return false, oops.Err(err)
+	}
+	return count > 0, nil
+}
+
+// Summarize counts the attendances by class, student and status,
+// considering only the sessions started until the filter instant
+func (pg *PGAttendance) Summarize(out *[]attendance.Summary, filter *attendance.Filter) (err error) {
+	db := pg.DB.Table("attendances a").
+		Select("s.class_id, a.student_id, a.status, count(*) AS total").
+		Joins("JOIN schedules s ON s.id = a.schedule_id AND s.deleted_at IS NULL").
+		Where("s.starts_at <= ?", filter.Until)
+
+	if filter.ClassID != nil {
+		db = db.Where("s.class_id = ?", filter.ClassID)
+	}
+
+	if filter.StudentID != nil {
+		db = db.Where("a.student_id = ?", filter.StudentID)
+	}
+
+	if err = db.Group("s.class_id, a.student_id, a.status").Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// CountSessions counts, for each student enrolled in a class, the schedules
+// started until the filter instant that were not over at the enrollment
+func (pg *PGAttendance) CountSessions(out *[]attendance.Sessions, filter *attendance.Filter) (err error) {
+	db := pg.DB.Table("enrollments e").
+		Select("e.class_id, e.student_id, count(s.id) AS total").
+		Joins("LEFT JOIN schedules s ON s.class_id = e.class_id AND s.deleted_at IS NULL "+
+			"AND s.starts_at <= ? AND s.ends_at > e.enrolled_at", filter.Until).
+		Where("e.status = ?", enrollment.StatusEnrolled)
+
+	if filter.ClassID != nil {
+		db = db.Where("e.class_id = ?", filter.ClassID)
+	}
+
+	if filter.StudentID != nil {
+		db = db.Where("e.student_id = ?", filter.StudentID)
+	}
+
+	if err = db.Group("e.class_id, e.student_id").Order("e.class_id, e.student_id").Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package postgres
+
+// Migrations holds the schema changes for attendances
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE attendances ADD CONSTRAINT chk_attendances_status
+			CHECK (status IN ('present', 'absent', 'late', 'excused'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
@@ -0,0 +1,79 @@
+package attendance
+
+import (
+	"go-api/domain/entities/attendance"
+	"go-api/infrastructure/persistance/attendance/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IAttendance methods
+type Repository struct{}
+
+// Save inserts or replaces the attendance of a student
+func (r *Repository) Save(in *attendance.Attendance, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.Save(in)
+}
+
+// Get returns the attendance of a student in a schedule
+func (r *Repository) Get(in *attendance.Attendance, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.Get(in)
+}
+
+// GetAll list the attendances of a schedule
+func (r *Repository) GetAll(out *[]attendance.Attendance, scheduleID uint, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.GetAll(out, scheduleID)
+}
+
+// SaveCode inserts or replaces the check in code of a schedule
+func (r *Repository) SaveCode(in *attendance.CheckInCode, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.SaveCode(in)
+}
+
+// GetCode returns the check in code of a schedule
+func (r *Repository) GetCode(in *attendance.CheckInCode, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.GetCode(in)
+}
+
+// LockAttempts returns the failed check in attempts of a student
+// in a schedule, locking them until the end of the transaction
+func (r *Repository) LockAttempts(in *attendance.CheckInAttempt, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.LockAttempts(in)
+}
+
+// AddFailure counts one more failed check in attempt
+func (r *Repository) AddFailure(in *attendance.CheckInAttempt, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.AddFailure(in)
+}
+
+// ResetAttempts clears the failed check in attempts of a schedule
+func (r *Repository) ResetAttempts(scheduleID uint, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.ResetAttempts(scheduleID)
+}
+
+// Attended reports whether the student attended the class
+func (r *Repository) Attended(classID, studentID uint, db *gorm.DB) (bool, error) {
+	data := postgres.PGAttendance{DB: db}
+	return data.Attended(classID, studentID)
+}
+
+// Summarize counts the attendances by class, student and status
+func (r *Repository) Summarize(out *[]attendance.Summary, filter *attendance.Filter, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.Summarize(out, filter)
+}
+
+// CountSessions counts the sessions each enrolled student was due to attend
+func (r *Repository) CountSessions(out *[]attendance.Sessions, filter *attendance.Filter, db *gorm.DB) error {
+	data := postgres.PGAttendance{DB: db}
+	return data.CountSessions(out, filter)
+}
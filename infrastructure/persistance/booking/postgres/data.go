@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"go-api/domain/entities/booking"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGBooking is a base structure
+// that implements methods for query execution
+type PGBooking struct {
+	DB *gorm.DB
+}
+
+// AddWindow insert an availability window into the database
+func (pg *PGBooking) AddWindow(in *booking.Window) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// DeleteWindow removes an availability window by its ID
+func (pg *PGBooking) DeleteWindow(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&booking.Window{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetWindow fetches an availability window of a teacher by its ID
+func (pg *PGBooking) GetWindow(in *booking.Window) (err error) {
+	if err = pg.DB.Where("id = ? AND teacher_id = ?", in.ID, in.TeacherID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetWindows lists the availability windows of a teacher along the week
+func (pg *PGBooking) GetWindows(out *[]booking.Window, teacherID uint) (err error) {
+	if err = pg.DB.Where("teacher_id = ?", teacherID).Order("weekday, start_minute, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetSettings fetches the booking settings of a teacher
+func (pg *PGBooking) GetSettings(in *booking.Settings) (err error) {
+	if err = pg.DB.Where("teacher_id = ?", in.TeacherID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SaveSettings inserts the booking settings of a teacher or replaces the current ones
+func (pg *PGBooking) SaveSettings(in *booking.Settings) (err error) {
+	if err = pg.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "teacher_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"buffer", "price", "currency", "updated_at"}),
+	}).Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Add insert a booking into the database
+func (pg *PGBooking) Add(in *booking.Booking) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the bookings matching the filter whose schedule
+// wasn't canceled, the earliest first
+func (pg *PGBooking) GetAll(out *[]booking.Booking, filter *booking.Filter) (err error) {
+	db := pg.DB.Where("schedule_id IN (SELECT id FROM schedules WHERE deleted_at IS NULL)")
+
+	if filter.TeacherID != nil {
+		db = db.Where("teacher_id = ?", filter.TeacherID)
+	}
+
+	if filter.StudentID != nil {
+		db = db.Where("student_id = ?", filter.StudentID)
+	}
+
+	if err = db.Order("starts_at, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// IsBooked reports whether the class was created by a booking,
+// even when its schedule was canceled later
+func (pg *PGBooking) IsBooked(classID uint) (bool, error) {
+	var count int64
+
+	if err := pg.DB.Model(&booking.Booking{}).Where("class_id = ?", classID).Count(&count).Error; err != nil {
+		return false, oops.Err(err)
+	}
+	return count > 0, nil
+}
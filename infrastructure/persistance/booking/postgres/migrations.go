@@ -0,0 +1,16 @@
+package postgres
+
+// Migrations holds the schema changes for availability windows
+// and bookings that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE availability_windows ADD CONSTRAINT chk_availability_windows_period
+			CHECK (weekday BETWEEN 0 AND 6 AND start_minute >= 0 AND end_minute <= 1440 AND end_minute > start_minute);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE booking_settings ADD CONSTRAINT chk_booking_settings_amounts
+			CHECK (buffer >= 0 AND price >= 0);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
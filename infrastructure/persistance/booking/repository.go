@@ -0,0 +1,66 @@
+package booking
+
+import (
+	"go-api/domain/entities/booking"
+	"go-api/infrastructure/persistance/booking/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IBooking methods
+type Repository struct{}
+
+// AddWindow inserts an availability window
+func (r *Repository) AddWindow(in *booking.Window, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.AddWindow(in)
+}
+
+// DeleteWindow removes an availability window
+func (r *Repository) DeleteWindow(id uint, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.DeleteWindow(id)
+}
+
+// GetWindow returns an availability window of a teacher by its ID
+func (r *Repository) GetWindow(in *booking.Window, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.GetWindow(in)
+}
+
+// GetWindows list the availability windows of a teacher
+func (r *Repository) GetWindows(out *[]booking.Window, teacherID uint, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.GetWindows(out, teacherID)
+}
+
+// GetSettings returns the booking settings of a teacher
+func (r *Repository) GetSettings(in *booking.Settings, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.GetSettings(in)
+}
+
+// SaveSettings inserts or replaces the booking settings of a teacher
+func (r *Repository) SaveSettings(in *booking.Settings, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.SaveSettings(in)
+}
+
+// Add inserts a booking
+func (r *Repository) Add(in *booking.Booking, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.Add(in)
+}
+
+// GetAll list the bookings matching the filter
+func (r *Repository) GetAll(out *[]booking.Booking, filter *booking.Filter, db *gorm.DB) error {
+	data := postgres.PGBooking{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// IsBooked reports whether the class is a booked private lesson
+func (r *Repository) IsBooked(classID uint, db *gorm.DB) (bool, error) {
+	data := postgres.PGBooking{DB: db}
+	return data.IsBooked(classID)
+}
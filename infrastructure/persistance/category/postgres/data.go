@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"go-api/domain/entities/category"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+)
+
+// SubtreeSQL selects the IDs of a category and of all its descendants,
+// taking the ID of the root category as its only argument. UNION stops
+// the recursion on rows already seen, should the tree ever have a cycle
+const SubtreeSQL = `WITH RECURSIVE subtree AS (
+		SELECT id FROM categories WHERE id = ?
+		UNION
+		SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+	) SELECT id FROM subtree`
+
+// PGCategory is a base structure
+// that implements methods for query execution
+type PGCategory struct {
+	DB *gorm.DB
+}
+
+// Add insert a category into the database
+func (pg *PGCategory) Add(in *category.Category) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update replaces the name, slug and parent of a category, so
+// a category without parent is moved to the root of the tree
+func (pg *PGCategory) Update(in *category.Category) (err error) {
+	if err = pg.DB.Model(&category.Category{}).Where("id = ?", in.ID).
+		Select("name", "slug", "parent_id", "updated_at").Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete removes a category by its ID
+func (pg *PGCategory) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&category.Category{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a category by its ID
+func (pg *PGCategory) Get(in *category.Category) (err error) {
+	if err = pg.DB.Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists every category ordered by name
+func (pg *PGCategory) GetAll(out *[]category.Category) (err error) {
+	if err = pg.DB.Order("name, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// LockTree takes a transaction level advisory lock on the category tree, so
+// concurrent moves can't both pass the cycle check and create a cycle together
+func (pg *PGCategory) LockTree() (err error) {
+	if err = pg.DB.Exec("SELECT pg_advisory_xact_lock(hashtext('categories'))").Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// InSubtree reports whether a category is the root one or one of its descendants
+func (pg *PGCategory) InSubtree(id, root uint) (found bool, err error) {
+	var count int64
+
+	if err = pg.DB.Raw("SELECT count(*) FROM ("+SubtreeSQL+") t WHERE t.id = ?", root, id).
+		Scan(&count).Error; err != nil {
+		return false, oops.Err(err)
+	}
+	return count > 0, nil
+}
+
+// CountChildren counts the categories directly under a category
+func (pg *PGCategory) CountChildren(id uint) (count int64, err error) {
+	if err = pg.DB.Model(&category.Category{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return count, nil
+}
+
+// CountClasses counts the classes filed under a category, the deleted ones
+// included as they still reference it
+func (pg *PGCategory) CountClasses(id uint) (count int64, err error) {
+	if err = pg.DB.Table("classes").Where("category_id = ?", id).Count(&count).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return count, nil
+}
+
+// Counts counts, for every category, the classes with the given statuses
+// filed directly under it and under its whole subtree
+func (pg *PGCategory) Counts(out *[]category.Count, statuses []string) (err error) {
+	sql := `WITH RECURSIVE tree AS (
+			SELECT id AS ancestor_id, id AS category_id FROM categories
+			UNION
+			SELECT t.ancestor_id, c.id FROM categories c JOIN tree t ON c.parent_id = t.category_id
+		)
+		SELECT t.ancestor_id AS category_id,
+			count(cl.id) FILTER (WHERE t.category_id = t.ancestor_id) AS classes,
+			count(cl.id) AS total
+		FROM tree t
+		LEFT JOIN classes cl ON cl.category_id = t.category_id
+			AND cl.deleted_at IS NULL AND cl.status IN ?
+		GROUP BY t.ancestor_id`
+
+	if err = pg.DB.Raw(sql, statuses).Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Tags counts the classes with the given statuses carrying each tag,
+// the most used tags first
+func (pg *PGCategory) Tags(out *[]category.TagCount, statuses []string, limit int) (err error) {
+	if err = pg.DB.Table("class_tags t").
+		Select("t.name, count(*) AS count").
+		Joins("JOIN classes c ON c.id = t.class_id AND c.deleted_at IS NULL").
+		Where("c.status IN ?", statuses).
+		Group("t.name").Order("count DESC, t.name").Limit(limit).
+		Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
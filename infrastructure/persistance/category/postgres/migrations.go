@@ -0,0 +1,10 @@
+package postgres
+
+// Migrations holds the schema changes for categories
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE categories ADD CONSTRAINT chk_categories_parent CHECK (parent_id <> id);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
@@ -0,0 +1,78 @@
+package category
+
+import (
+	"go-api/domain/entities/category"
+	"go-api/infrastructure/persistance/category/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements ICategory methods
+type Repository struct{}
+
+// Add is a function that manage the flow of category insertion into database
+func (r *Repository) Add(in *category.Category, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Add(in)
+}
+
+// Update replaces the fields of a category
+func (r *Repository) Update(in *category.Category, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Update(in)
+}
+
+// Delete removes a category
+func (r *Repository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a category by its ID
+func (r *Repository) Get(in *category.Category, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Get(in)
+}
+
+// GetAll list every category
+func (r *Repository) GetAll(out *[]category.Category, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.GetAll(out)
+}
+
+// LockTree serializes the moves of categories until the end of the transaction
+func (r *Repository) LockTree(db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.LockTree()
+}
+
+// InSubtree reports whether a category belongs to the subtree of another
+func (r *Repository) InSubtree(id, root uint, db *gorm.DB) (bool, error) {
+	data := postgres.PGCategory{DB: db}
+	return data.InSubtree(id, root)
+}
+
+// CountChildren counts the subcategories of a category
+func (r *Repository) CountChildren(id uint, db *gorm.DB) (int64, error) {
+	data := postgres.PGCategory{DB: db}
+	return data.CountChildren(id)
+}
+
+// CountClasses counts the classes filed under a category
+func (r *Repository) CountClasses(id uint, db *gorm.DB) (int64, error) {
+	data := postgres.PGCategory{DB: db}
+	return data.CountClasses(id)
+}
+
+// Counts counts the classes of every category
+func (r *Repository) Counts(out *[]category.Count, statuses []string, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Counts(out, statuses)
+}
+
+// Tags counts the classes carrying each tag
+func (r *Repository) Tags(out *[]category.TagCount, statuses []string, limit int, db *gorm.DB) error {
+	data := postgres.PGCategory{DB: db}
+	return data.Tags(out, statuses, limit)
+}
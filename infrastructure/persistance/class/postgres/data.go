@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"go-api/domain/entities/class"
+	categoryPostgres "go-api/infrastructure/persistance/category/postgres"
+	"go-api/oops"
+	"go-api/utils"
+	"math"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGClass is a base structure
+// that implements methods for query execution
+type PGClass struct {
+	DB *gorm.DB
+}
+
+// Add insert a class into the database
+func (pg *PGClass) Add(in *class.Class) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of a class
+func (pg *PGClass) Update(in *class.Class) (err error) {
+	if err = pg.DB.Model(&class.Class{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// ClearLocation removes the address and coordinates of a class, which
+// Update can't do as it skips the empty fields
+func (pg *PGClass) ClearLocation(id uint) (err error) {
+	if err = pg.DB.Model(&class.Class{}).Where("id = ?", id).
+		Select("address", "latitude", "longitude", "updated_at").Updates(&class.Class{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete soft deletes a class by its ID
+func (pg *PGClass) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&class.Class{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a class by its ID
+func (pg *PGClass) Get(in *class.Class, filter *class.Filter) (err error) {
+	if err = pg.scoped(filter).Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists all classes matching the filter, the closest
+// first when they are searched near a point
+func (pg *PGClass) GetAll(out *[]class.Class, filter *class.Filter) (err error) {
+	db := pg.scoped(filter)
+
+	if filter != nil && filter.Near != nil {
+		db = db.Order(distanceSQL(*filter.Near))
+	}
+
+	if err = db.Order("id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Lock fetches a class by its ID, locking its row until the
+// end of the transaction so concurrent changes are serialized
+func (pg *PGClass) Lock(in *class.Class) (err error) {
+	if err = pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddStatusEvent insert a class status change into the database
+func (pg *PGClass) AddStatusEvent(in *class.StatusEvent) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetStatusEvents lists the status changes of a class, the oldest first
+func (pg *PGClass) GetStatusEvents(out *[]class.StatusEvent, classID uint) (err error) {
+	if err = pg.DB.Where("class_id = ?", classID).Order("created_at, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SetTags replaces the tags of a class
+func (pg *PGClass) SetTags(classID uint, tags []string) (err error) {
+	if err = pg.DB.Where("class_id = ?", classID).Delete(&class.Tag{}).Error; err != nil {
+		return oops.Err(err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	data := make([]class.Tag, len(tags))
+	for i := range tags {
+		data[i] = class.Tag{ClassID: &classID, Name: &tags[i]}
+	}
+
+	if err = pg.DB.Create(&data).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// TaggedSQL selects the IDs of the classes carrying every tag of a list,
+// taking the list and its length as arguments
+const TaggedSQL = `SELECT class_id FROM class_tags WHERE name IN ?
+	GROUP BY class_id HAVING count(*) = ?`
+
+// scoped applies the filter conditions and preloads to the query
+func (pg *PGClass) scoped(filter *class.Filter) *gorm.DB {
+	db := pg.DB
+
+	if filter == nil {
+		return db
+	}
+
+	if filter.TeacherID != nil {
+		db = db.Where("teacher_id = ?", filter.TeacherID)
+	}
+
+	if len(filter.Status) > 0 {
+		db = db.Where("status IN ?", filter.Status)
+	}
+
+	if filter.CategoryID != nil {
+		db = db.Where("category_id IN ("+categoryPostgres.SubtreeSQL+")", filter.CategoryID)
+	}
+
+	if len(filter.Tags) > 0 {
+		db = db.Where("id IN ("+TaggedSQL+")", filter.Tags, len(filter.Tags))
+	}
+
+	if filter.Near != nil {
+		db = near(db, *filter.Near, filter.RadiusKm)
+	}
+
+	for _, assoc := range filter.Include {
+		switch assoc {
+		case class.IncludeSchedules:
+			db = db.Preload(assoc, func(tx *gorm.DB) *gorm.DB {
+				return tx.Order("starts_at")
+			})
+		case class.IncludeTags:
+			db = db.Preload(assoc, func(tx *gorm.DB) *gorm.DB {
+				return tx.Order("name")
+			})
+		default:
+			db = db.Preload(assoc)
+		}
+	}
+
+	return db
+}
+
+// near restricts the query to the classes within a radius of a point. A
+// bounding box on the coordinates, served by an index, discards the far
+// classes before the exact distance is computed
+func near(db *gorm.DB, p class.Point, radiusKm float64) *gorm.DB {
+	latDelta := radiusKm / (math.Pi * utils.EarthRadiusKm / 180)
+
+	db = db.Where("latitude BETWEEN ? AND ?", p.Latitude-latDelta, p.Latitude+latDelta)
+
+	// close to the poles and across the antimeridian only the exact distance is used
+	if math.Abs(p.Latitude)+latDelta < 90 {
+		lngDelta := latDelta / math.Cos(p.Latitude*math.Pi/180)
+		if p.Longitude-lngDelta >= -180 && p.Longitude+lngDelta <= 180 {
+			db = db.Where("longitude BETWEEN ? AND ?", p.Longitude-lngDelta, p.Longitude+lngDelta)
+		}
+	}
+
+	return db.Where(distanceSQL(p)+" <= ?", radiusKm)
+}
+
+// distanceSQL computes in kilometers the distance of the classes to a point
+// with the haversine formula, the same of utils.Distance. The coordinates
+// are written as literals as the ORDER BY clause takes no arguments
+func distanceSQL(p class.Point) string {
+	lat := strconv.FormatFloat(p.Latitude, 'f', -1, 64)
+	lng := strconv.FormatFloat(p.Longitude, 'f', -1, 64)
+	radius := strconv.FormatFloat(utils.EarthRadiusKm, 'f', -1, 64)
+
+	return "(2 * " + radius + " * asin(sqrt(least(1, " +
+		"power(sin(radians(latitude - " + lat + ") / 2), 2) + " +
+		"cos(radians(" + lat + ")) * cos(radians(latitude)) * " +
+		"power(sin(radians(longitude - " + lng + ") / 2), 2)))))"
+}
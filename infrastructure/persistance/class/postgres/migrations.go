@@ -0,0 +1,157 @@
+package postgres
+
+// legacyTime reads the free text dates and times schedules used to store,
+// as dd/mm/yyyy and hh:mm[:ss] wall clock times of America/Fortaleza, the
+// zone the dates were read in. The format is explicit so the DateStyle of
+// the server doesn't swap days and months, and invalid dates that
+// to_timestamp would roll over, like 31/02, are refused
+const legacyTime = `CREATE OR REPLACE FUNCTION legacy_schedule_time(day_text text, clock_text text)
+	RETURNS timestamptz AS $$
+	DECLARE
+		stamp text := btrim(day_text) || ' ' || btrim(clock_text);
+		parsed timestamptz;
+	BEGIN
+		IF stamp ~ '^\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}$' THEN
+			stamp := stamp || ':00';
+		END IF;
+
+		IF stamp IS NULL OR stamp !~ '^\d{1,2}/\d{1,2}/\d{4} \d{1,2}:\d{2}:\d{2}$' THEN
+			RAISE EXCEPTION 'unreadable schedule time "%"', stamp;
+		END IF;
+
+		parsed := to_timestamp(stamp, 'DD/MM/YYYY HH24:MI:SS');
+
+		IF to_char(parsed, 'FMDD/FMMM/YYYY FMHH24:MI:SS') <> regexp_replace(stamp, '(^|[/ ])0(\d)', '\1\2', 'g') THEN
+			RAISE EXCEPTION 'unreadable schedule time "%"', stamp;
+		END IF;
+
+		RETURN parsed::timestamp AT TIME ZONE 'America/Fortaleza';
+	END $$ LANGUAGE plpgsql SET TimeZone = 'UTC'`
+
+// PreMigrations holds the schema changes that must run before gorm
+// AutoMigrate, as it can't add the required columns to filled tables
+var PreMigrations = []string{
+	legacyTime,
+	// schedules used to store date, start and end as free text. Every
+	// unreadable row is reported and the whole backfill is rolled back,
+	// so they are fixed by hand before the old columns are dropped
+	`DO $$
+	DECLARE
+		r record;
+		failures text[] := '{}';
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'schedules' AND column_name = 'date') THEN
+			RETURN;
+		END IF;
+
+		ALTER TABLE schedules
+			ADD COLUMN IF NOT EXISTS starts_at timestamptz,
+			ADD COLUMN IF NOT EXISTS ends_at timestamptz,
+			ALTER COLUMN "date" DROP NOT NULL,
+			ALTER COLUMN "start" DROP NOT NULL,
+			ALTER COLUMN "end" DROP NOT NULL;
+
+		FOR r IN SELECT id, "date", "start", "end" FROM schedules WHERE starts_at IS NULL OR ends_at IS NULL LOOP
+			BEGIN
+				UPDATE schedules SET
+					starts_at = legacy_schedule_time(r."date", r."start"),
+					ends_at = legacy_schedule_time(r."date", r."end")
+						-- periods crossing midnight end on the next day
+						+ CASE WHEN legacy_schedule_time(r."date", r."end") <= legacy_schedule_time(r."date", r."start")
+							THEN interval '1 day' ELSE interval '0' END
+				WHERE id = r.id;
+			EXCEPTION WHEN others THEN
+				failures := failures || format('schedule %s: %s %s %s', r.id, r."date", r."start", r."end");
+			END;
+		END LOOP;
+
+		IF cardinality(failures) > 0 THEN
+			RAISE EXCEPTION 'schedules with unreadable periods: %', array_to_string(failures, '; ');
+		END IF;
+	END $$`,
+}
+
+// Migrations holds the schema changes for classes and schedules
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	// the free text columns are dropped once every schedule was backfilled
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM schedules WHERE starts_at IS NULL OR ends_at IS NULL) THEN
+			ALTER TABLE schedules
+				DROP COLUMN IF EXISTS "date",
+				DROP COLUMN IF EXISTS "start",
+				DROP COLUMN IF EXISTS "end";
+			DROP FUNCTION IF EXISTS legacy_schedule_time(text, text);
+		END IF;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE schedules ADD CONSTRAINT chk_schedules_period CHECK (ends_at > starts_at);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`ALTER TABLE schedules ADD COLUMN IF NOT EXISTS period tstzrange
+		GENERATED ALWAYS AS (tstzrange(starts_at, ends_at, '[)')) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_schedules_period ON schedules USING gist (period)`,
+	// teacher_id is denormalized into schedules so the exclusion
+	// constraint can prevent overlapping periods for the same teacher
+	`CREATE OR REPLACE FUNCTION schedules_set_teacher() RETURNS trigger AS $$
+	BEGIN
+		SELECT teacher_id INTO NEW.teacher_id FROM classes WHERE id = NEW.class_id;
+		RETURN NEW;
+	END $$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_schedules_set_teacher ON schedules`,
+	`CREATE TRIGGER trg_schedules_set_teacher BEFORE INSERT OR UPDATE OF class_id ON schedules
+		FOR EACH ROW EXECUTE FUNCTION schedules_set_teacher()`,
+	`CREATE OR REPLACE FUNCTION classes_sync_schedules_teacher() RETURNS trigger AS $$
+	BEGIN
+		UPDATE schedules SET teacher_id = NEW.teacher_id WHERE class_id = NEW.id;
+		RETURN NEW;
+	END $$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_classes_sync_schedules_teacher ON classes`,
+	`CREATE TRIGGER trg_classes_sync_schedules_teacher AFTER UPDATE OF teacher_id ON classes
+		FOR EACH ROW WHEN (OLD.teacher_id IS DISTINCT FROM NEW.teacher_id)
+		EXECUTE FUNCTION classes_sync_schedules_teacher()`,
+	`UPDATE schedules s SET teacher_id = c.teacher_id
+		FROM classes c WHERE c.id = s.class_id AND s.teacher_id IS NULL`,
+	`ALTER TABLE schedules ALTER COLUMN teacher_id SET NOT NULL`,
+	`CREATE EXTENSION IF NOT EXISTS btree_gist`,
+	`DO $$ BEGIN
+		ALTER TABLE schedules ADD CONSTRAINT excl_schedules_teacher_period
+			EXCLUDE USING gist (teacher_id WITH =, period WITH &&) WHERE (deleted_at IS NULL);
+	EXCEPTION WHEN duplicate_object OR duplicate_table THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE schedules ADD CONSTRAINT chk_schedules_status
+			CHECK (status IN ('scheduled', 'rescheduled', 'canceled'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE classes ADD CONSTRAINT chk_classes_status
+			CHECK (status IN ('draft', 'published', 'full', 'archived'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE class_status_events ADD CONSTRAINT chk_class_status_events_status
+			CHECK (from_status IN ('draft', 'published', 'full', 'archived')
+				AND to_status IN ('draft', 'published', 'full', 'archived'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// schedules removed before statuses existed were canceled
+	`UPDATE schedules SET status = 'canceled' WHERE deleted_at IS NOT NULL AND status <> 'canceled'`,
+	`DO $$ BEGIN
+		ALTER TABLE schedule_events ADD CONSTRAINT chk_schedule_events_status
+			CHECK (status IN ('rescheduled', 'canceled')
+				AND (compensation IS NULL OR compensation IN ('refund', 'none')));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// both coordinates of an in-person class are given together
+	`DO $$ BEGIN
+		ALTER TABLE classes ADD CONSTRAINT chk_classes_location
+			CHECK ((latitude IS NULL) = (longitude IS NULL)
+				AND latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// serves the bounding box of the proximity searches
+	`CREATE INDEX IF NOT EXISTS idx_classes_location
+		ON classes (latitude, longitude) WHERE latitude IS NOT NULL`,
+}
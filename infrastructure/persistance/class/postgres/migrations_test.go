@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// TestLegacyScheduleTime runs against the database given in
+// TEST_DATABASE_URL, as the parsing is done by Postgres itself
+func TestLegacyScheduleTime(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		name  string
+		day   string
+		clock string
+		want  string
+		fails bool
+	}{
+		{"day above 12", "25/03/2020", "10:00", "2020-03-25T13:00:00Z", false},
+		{"day below 12", "03/04/2020", "08:30:00", "2020-04-03T11:30:00Z", false},
+		{"unpadded", "5/3/2020", "9:05", "2020-03-05T12:05:00Z", false},
+		{"late night", "31/12/2020", "23:30", "2021-01-01T02:30:00Z", false},
+		{"nonexistent day", "31/02/2020", "10:00", "", true},
+		{"month above 12", "03/25/2020", "10:00", "", true},
+		{"iso date", "2020-03-25", "10:00", "", true},
+		{"hour above 23", "25/03/2020", "24:00", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tx.Rollback()
+
+			if _, err = tx.Exec(legacyTime); err != nil {
+				t.Fatal(err)
+			}
+
+			// the server style that used to swap days and months
+			if _, err = tx.Exec(`SET LOCAL DateStyle = 'ISO, MDY'`); err != nil {
+				t.Fatal(err)
+			}
+
+			var got time.Time
+
+			err = tx.QueryRow(`SELECT legacy_schedule_time($1, $2)`, tt.day, tt.clock).Scan(&got)
+			if tt.fails {
+				if err == nil {
+					t.Fatalf("legacy_schedule_time(%q, %q) = %v, want an error", tt.day, tt.clock, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.UTC().Format(time.RFC3339) != tt.want {
+				t.Errorf("legacy_schedule_time(%q, %q) = %s, want %s", tt.day, tt.clock, got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/oops"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGRecurrence is a base structure
+// that implements methods for query execution
+type PGRecurrence struct {
+	DB *gorm.DB
+}
+
+// Add insert a recurrence into the database
+func (pg *PGRecurrence) Add(in *class.Recurrence) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of a recurrence
+func (pg *PGRecurrence) Update(in *class.Recurrence) (err error) {
+	if err = pg.DB.Model(&class.Recurrence{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete soft deletes a recurrence by its ID
+func (pg *PGRecurrence) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&class.Recurrence{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a recurrence and its exceptions by its ID and class
+func (pg *PGRecurrence) Get(in *class.Recurrence) (err error) {
+	if err = pg.DB.Preload("Exceptions", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("occurrence_start")
+	}).Where("id = ? AND class_id = ?", in.ID, in.ClassID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Lock fetches a recurrence and its exceptions by its ID, locking its row
+// until the end of the transaction so concurrent changes are serialized
+func (pg *PGRecurrence) Lock(in *class.Recurrence) (err error) {
+	db := pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", in.ID)
+
+	if in.ClassID != nil {
+		db = db.Where("class_id = ?", in.ClassID)
+	}
+
+	if err = db.Preload("Exceptions").First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the recurrences of a class and their exceptions
+func (pg *PGRecurrence) GetAll(out *[]class.Recurrence, classID uint) (err error) {
+	if err = pg.DB.Preload("Exceptions", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("occurrence_start")
+	}).Where("class_id = ?", classID).Order("id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Pending lists the recurrences not yet materialized until the given instant
+func (pg *PGRecurrence) Pending(out *[]class.Recurrence, until time.Time) (err error) {
+	if err = pg.DB.Where("materialized_until IS NULL OR materialized_until < ?", until).
+		Order("id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SaveException inserts or replaces the exception of an occurrence
+func (pg *PGRecurrence) SaveException(in *class.RecurrenceException) (err error) {
+	current := class.RecurrenceException{}
+
+	err = pg.DB.Where("recurrence_id = ? AND occurrence_start = ?", in.RecurrenceID, in.OccurrenceStart).
+		Limit(1).Find(&current).Error
+	if err != nil {
+		return oops.Err(err)
+	}
+
+	if current.ID == nil {
+		err = pg.DB.Create(in).Error
+	} else {
+		in.ID = current.ID
+		err = pg.DB.Model(&class.RecurrenceException{}).Where("id = ?", in.ID).
+			Select("canceled", "starts_at", "ends_at").Updates(in).Error
+	}
+
+	if err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
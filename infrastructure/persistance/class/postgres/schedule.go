@@ -0,0 +1,161 @@
+package postgres
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/domain/entities/enrollment"
+	"go-api/oops"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PGSchedule is a base structure
+// that implements methods for query execution
+type PGSchedule struct {
+	DB *gorm.DB
+}
+
+// Add insert a schedule into the database
+func (pg *PGSchedule) Add(in *class.Schedule) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of a schedule
+func (pg *PGSchedule) Update(in *class.Schedule) (err error) {
+	if err = pg.DB.Model(&class.Schedule{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete soft deletes a schedule by its ID, marking it as canceled
+func (pg *PGSchedule) Delete(id uint) (err error) {
+	if err = pg.DB.Model(&class.Schedule{}).Where("id = ?", id).Update("status", class.ScheduleCanceled).Error; err != nil {
+		return oops.Err(err)
+	}
+
+	if err = pg.DB.Where("id = ?", id).Delete(&class.Schedule{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a schedule by its ID and class
+func (pg *PGSchedule) Get(in *class.Schedule) (err error) {
+	if err = pg.DB.Where("id = ? AND class_id = ?", in.ID, in.ClassID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the schedules of a class ordered by start
+func (pg *PGSchedule) GetAll(out *[]class.Schedule, classID uint) (err error) {
+	if err = pg.DB.Where("class_id = ?", classID).Order("starts_at").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Conflicts lists the schedules of the same teacher whose
+// period overlaps the given schedule, ignoring the schedule itself
+func (pg *PGSchedule) Conflicts(in *class.Schedule, out *[]class.Schedule) (err error) {
+	db := pg.DB.Where("teacher_id = ? AND period && tstzrange(?, ?, '[)')", in.TeacherID, in.Start, in.End)
+
+	if in.ID != nil {
+		db = db.Where("id <> ?", in.ID)
+	}
+
+	if err = db.Order("starts_at").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Occurrences lists the schedules materialized from a recurrence whose
+// occurrence starts inside [from, to), including the deleted ones
+func (pg *PGSchedule) Occurrences(recurrenceID uint, from, to time.Time, out *[]class.Schedule) (err error) {
+	if err = pg.DB.Unscoped().
+		Where("recurrence_id = ? AND occurrence_start >= ? AND occurrence_start < ?", recurrenceID, from, to).
+		Order("occurrence_start").
+		Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// DeleteOccurrencesFrom soft deletes the schedules materialized from a recurrence
+// whose occurrence starts at or after the given instant, marking them as canceled
+func (pg *PGSchedule) DeleteOccurrencesFrom(recurrenceID uint, from time.Time) (err error) {
+	where := "recurrence_id = ? AND occurrence_start >= ?"
+
+	if err = pg.DB.Model(&class.Schedule{}).Where(where, recurrenceID, from).
+		Update("status", class.ScheduleCanceled).Error; err != nil {
+		return oops.Err(err)
+	}
+
+	if err = pg.DB.Where(where, recurrenceID, from).Delete(&class.Schedule{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Find lists the schedules of active classes matching
+// the filter, loading the class of each schedule
+func (pg *PGSchedule) Find(out *[]class.Schedule, filter *class.ScheduleFilter) (err error) {
+	db := pg.DB.Preload("Class")
+
+	if filter.WithDeleted {
+		db = db.Unscoped()
+	}
+
+	db = db.Where("class_id IN (?)", pg.DB.Model(&class.Class{}).Select("id"))
+
+	if filter.ClassID != nil {
+		db = db.Where("class_id = ?", filter.ClassID)
+	}
+
+	if filter.TeacherID != nil {
+		db = db.Where("teacher_id = ?", filter.TeacherID)
+	}
+
+	if filter.AttendeeID != nil {
+		db = db.Where("(teacher_id = ? OR class_id IN (SELECT class_id FROM enrollments WHERE student_id = ? AND status = ?))",
+			filter.AttendeeID, filter.AttendeeID, enrollment.StatusEnrolled)
+	}
+
+	if filter.EndsAfter != nil {
+		db = db.Where("ends_at > ?", filter.EndsAfter)
+	}
+
+	if err = db.Order("starts_at").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetWithDeleted fetches a schedule by its ID and class, even when canceled
+func (pg *PGSchedule) GetWithDeleted(in *class.Schedule) (err error) {
+	if err = pg.DB.Unscoped().Where("id = ? AND class_id = ?", in.ID, in.ClassID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddEvent insert a schedule status change into the database
+func (pg *PGSchedule) AddEvent(in *class.ScheduleEvent) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetEvents lists the status changes of a schedule, the oldest first
+func (pg *PGSchedule) GetEvents(out *[]class.ScheduleEvent, scheduleID uint) (err error) {
+	if err = pg.DB.Where("schedule_id = ?", scheduleID).Order("created_at, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package class
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/infrastructure/persistance/class/postgres"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecurrenceRepository is a base structure that
+// implements IRecurrence methods
+type RecurrenceRepository struct{}
+
+// Add is a function that manage the flow of recurrence insertion into database
+func (r *RecurrenceRepository) Add(in *class.Recurrence, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Add(in)
+}
+
+// Update updates a recurrence
+func (r *RecurrenceRepository) Update(in *class.Recurrence, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Update(in)
+}
+
+// Delete removes a recurrence
+func (r *RecurrenceRepository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a recurrence by its ID and class
+func (r *RecurrenceRepository) Get(in *class.Recurrence, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Get(in)
+}
+
+// Lock returns a recurrence by its ID locking it for update
+func (r *RecurrenceRepository) Lock(in *class.Recurrence, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Lock(in)
+}
+
+// GetAll list the recurrences of a class
+func (r *RecurrenceRepository) GetAll(out *[]class.Recurrence, classID uint, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.GetAll(out, classID)
+}
+
+// Pending list the recurrences not yet materialized until an instant
+func (r *RecurrenceRepository) Pending(out *[]class.Recurrence, until time.Time, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.Pending(out, until)
+}
+
+// SaveException inserts or replaces the exception of an occurrence
+func (r *RecurrenceRepository) SaveException(in *class.RecurrenceException, db *gorm.DB) error {
+	data := postgres.PGRecurrence{DB: db}
+	return data.SaveException(in)
+}
@@ -0,0 +1,72 @@
+package class
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/infrastructure/persistance/class/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IClass methods
+type Repository struct{}
+
+// Add is a function that manage the flow of class insertion into database
+func (r *Repository) Add(in *class.Class, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.Add(in)
+}
+
+// Update updates a class
+func (r *Repository) Update(in *class.Class, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.Update(in)
+}
+
+// ClearLocation turns an in-person class back into an online one
+func (r *Repository) ClearLocation(id uint, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.ClearLocation(id)
+}
+
+// Delete removes a class
+func (r *Repository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a class by its ID
+func (r *Repository) Get(in *class.Class, filter *class.Filter, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.Get(in, filter)
+}
+
+// GetAll list all classes matching the filter
+func (r *Repository) GetAll(out *[]class.Class, filter *class.Filter, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// Lock returns a class by its ID locking it for update
+func (r *Repository) Lock(in *class.Class, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.Lock(in)
+}
+
+// AddStatusEvent records a status change of a class
+func (r *Repository) AddStatusEvent(in *class.StatusEvent, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.AddStatusEvent(in)
+}
+
+// GetStatusEvents list the status changes of a class
+func (r *Repository) GetStatusEvents(out *[]class.StatusEvent, classID uint, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.GetStatusEvents(out, classID)
+}
+
+// SetTags replaces the tags of a class
+func (r *Repository) SetTags(classID uint, tags []string, db *gorm.DB) error {
+	data := postgres.PGClass{DB: db}
+	return data.SetTags(classID, tags)
+}
@@ -0,0 +1,85 @@
+package class
+
+import (
+	"go-api/domain/entities/class"
+	"go-api/infrastructure/persistance/class/postgres"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleRepository is a base structure that
+// implements ISchedule methods
+type ScheduleRepository struct{}
+
+// Add is a function that manage the flow of schedule insertion into database
+func (r *ScheduleRepository) Add(in *class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Add(in)
+}
+
+// Update updates a schedule
+func (r *ScheduleRepository) Update(in *class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Update(in)
+}
+
+// Delete removes a schedule
+func (r *ScheduleRepository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a schedule by its ID and class
+func (r *ScheduleRepository) Get(in *class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Get(in)
+}
+
+// GetAll list the schedules of a class
+func (r *ScheduleRepository) GetAll(out *[]class.Schedule, classID uint, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.GetAll(out, classID)
+}
+
+// Conflicts list the schedules of the same teacher overlapping the given one
+func (r *ScheduleRepository) Conflicts(in *class.Schedule, out *[]class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Conflicts(in, out)
+}
+
+// Occurrences list the schedules materialized from a recurrence inside a period
+func (r *ScheduleRepository) Occurrences(recurrenceID uint, from, to time.Time, out *[]class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Occurrences(recurrenceID, from, to, out)
+}
+
+// DeleteOccurrencesFrom removes the schedules materialized from a recurrence after an instant
+func (r *ScheduleRepository) DeleteOccurrencesFrom(recurrenceID uint, from time.Time, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.DeleteOccurrencesFrom(recurrenceID, from)
+}
+
+// Find list the schedules matching the filter
+func (r *ScheduleRepository) Find(out *[]class.Schedule, filter *class.ScheduleFilter, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.Find(out, filter)
+}
+
+// GetWithDeleted returns a schedule by its ID and class, even when canceled
+func (r *ScheduleRepository) GetWithDeleted(in *class.Schedule, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.GetWithDeleted(in)
+}
+
+// AddEvent records a status change of a schedule
+func (r *ScheduleRepository) AddEvent(in *class.ScheduleEvent, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.AddEvent(in)
+}
+
+// GetEvents list the status changes of a schedule
+func (r *ScheduleRepository) GetEvents(out *[]class.ScheduleEvent, scheduleID uint, db *gorm.DB) error {
+	data := postgres.PGSchedule{DB: db}
+	return data.GetEvents(out, scheduleID)
+}
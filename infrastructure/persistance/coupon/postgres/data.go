@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"go-api/domain/entities/coupon"
+	"go-api/oops"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGCoupon is a base structure
+// that implements methods for query execution
+type PGCoupon struct {
+	DB *gorm.DB
+}
+
+// Add insert a coupon and its restrictions into the database
+func (pg *PGCoupon) Add(in *coupon.Coupon) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update replaces the fields of a coupon, clearing the ones left empty
+func (pg *PGCoupon) Update(in *coupon.Coupon) (err error) {
+	if err = pg.DB.Model(&coupon.Coupon{}).
+		Select("code", "kind", "percentage", "amount", "currency", "starts_at", "ends_at", "max_uses", "max_uses_per_user").
+		Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete soft deletes a coupon by its ID
+func (pg *PGCoupon) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&coupon.Coupon{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a coupon by its ID along with its restrictions
+func (pg *PGCoupon) Get(in *coupon.Coupon) (err error) {
+	if err = pg.DB.Preload("Restrictions").Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetByCode fetches a coupon by its code along with its restrictions
+func (pg *PGCoupon) GetByCode(in *coupon.Coupon) (err error) {
+	if err = pg.DB.Preload("Restrictions").Where("code = ?", in.Code).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the coupons along with their restrictions
+func (pg *PGCoupon) GetAll(out *[]coupon.Coupon) (err error) {
+	if err = pg.DB.Preload("Restrictions").Order("id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Lock fetches a coupon by its ID locking it for update
+func (pg *PGCoupon) Lock(in *coupon.Coupon) (err error) {
+	if err = pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SetRestrictions replaces the restrictions of a coupon
+func (pg *PGCoupon) SetRestrictions(couponID uint, in []coupon.Restriction) (err error) {
+	if err = pg.DB.Where("coupon_id = ?", couponID).Delete(&coupon.Restriction{}).Error; err != nil {
+		return oops.Err(err)
+	}
+
+	if len(in) == 0 {
+		return nil
+	}
+
+	for i := range in {
+		in[i].CouponID = &couponID
+	}
+
+	if err = pg.DB.Create(&in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// CountRedemptions counts the uses of a coupon not released, optionally by a single user
+func (pg *PGCoupon) CountRedemptions(couponID uint, userID *uint) (count int64, err error) {
+	db := pg.DB.Model(&coupon.Redemption{}).Where("coupon_id = ? AND released_at IS NULL", couponID)
+
+	if userID != nil {
+		db = db.Where("user_id = ?", userID)
+	}
+
+	if err = db.Count(&count).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return count, nil
+}
+
+// AddRedemption records an use of a coupon
+func (pg *PGCoupon) AddRedemption(in *coupon.Redemption) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// ReleaseRedemption marks an use of a coupon as released by its ID
+func (pg *PGCoupon) ReleaseRedemption(id uint) (err error) {
+	if err = pg.DB.Model(&coupon.Redemption{}).Where("id = ? AND released_at IS NULL", id).
+		Update("released_at", time.Now()).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
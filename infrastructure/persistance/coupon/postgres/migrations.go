@@ -0,0 +1,27 @@
+package postgres
+
+// Migrations holds the schema changes for coupons
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	// codes are unique among the coupons that weren't deleted
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_coupons_code
+		ON coupons (code) WHERE deleted_at IS NULL`,
+	`DO $$ BEGIN
+		ALTER TABLE coupons ADD CONSTRAINT chk_coupons_discount CHECK (
+			(kind = 'percentage' AND percentage BETWEEN 1 AND 100 AND amount IS NULL)
+			OR (kind = 'fixed' AND amount > 0 AND currency IS NOT NULL AND percentage IS NULL)
+		);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE coupons ADD CONSTRAINT chk_coupons_validity
+			CHECK (starts_at IS NULL OR ends_at IS NULL OR ends_at > starts_at);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// a restriction targets either a class or a teacher
+	`DO $$ BEGIN
+		ALTER TABLE coupon_restrictions ADD CONSTRAINT chk_coupon_restrictions_target
+			CHECK ((class_id IS NULL) <> (teacher_id IS NULL));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
@@ -0,0 +1,78 @@
+package coupon
+
+import (
+	"go-api/domain/entities/coupon"
+	"go-api/infrastructure/persistance/coupon/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements ICoupon methods
+type Repository struct{}
+
+// Add is a function that manage the flow of coupon insertion into database
+func (r *Repository) Add(in *coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.Add(in)
+}
+
+// Update updates a coupon
+func (r *Repository) Update(in *coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.Update(in)
+}
+
+// Delete removes a coupon
+func (r *Repository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a coupon by its ID
+func (r *Repository) Get(in *coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.Get(in)
+}
+
+// GetByCode returns a coupon by its code
+func (r *Repository) GetByCode(in *coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.GetByCode(in)
+}
+
+// GetAll list all coupons
+func (r *Repository) GetAll(out *[]coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.GetAll(out)
+}
+
+// Lock returns a coupon by its ID locking it for update
+func (r *Repository) Lock(in *coupon.Coupon, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.Lock(in)
+}
+
+// SetRestrictions replaces the restrictions of a coupon
+func (r *Repository) SetRestrictions(couponID uint, in []coupon.Restriction, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.SetRestrictions(couponID, in)
+}
+
+// CountRedemptions counts the uses of a coupon
+func (r *Repository) CountRedemptions(couponID uint, userID *uint, db *gorm.DB) (int64, error) {
+	data := postgres.PGCoupon{DB: db}
+	return data.CountRedemptions(couponID, userID)
+}
+
+// AddRedemption records an use of a coupon
+func (r *Repository) AddRedemption(in *coupon.Redemption, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.AddRedemption(in)
+}
+
+// ReleaseRedemption releases an use of a coupon by its ID
+func (r *Repository) ReleaseRedemption(id uint, db *gorm.DB) error {
+	data := postgres.PGCoupon{DB: db}
+	return data.ReleaseRedemption(id)
+}
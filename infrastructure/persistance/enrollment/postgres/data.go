@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"go-api/domain/entities/enrollment"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+)
+
+// PGEnrollment is a base structure
+// that implements methods for query execution
+type PGEnrollment struct {
+	DB *gorm.DB
+}
+
+// Add insert an enrollment into the database
+func (pg *PGEnrollment) Add(in *enrollment.Enrollment) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of an enrollment
+func (pg *PGEnrollment) Update(in *enrollment.Enrollment) (err error) {
+	if err = pg.DB.Model(&enrollment.Enrollment{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetActive fetches the enrollment of a student in a class that wasn't canceled
+func (pg *PGEnrollment) GetActive(in *enrollment.Enrollment) (err error) {
+	if err = pg.DB.Where("class_id = ? AND student_id = ? AND status <> ?", in.ClassID, in.StudentID, enrollment.StatusCanceled).
+		First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the enrollments matching the filter in arrival order
+func (pg *PGEnrollment) GetAll(out *[]enrollment.Enrollment, filter *enrollment.Filter) (err error) {
+	db := pg.DB
+
+	if filter.ClassID != nil {
+		db = db.Where("class_id = ?", filter.ClassID)
+	}
+
+	if filter.StudentID != nil {
+		db = db.Where("student_id = ?", filter.StudentID)
+	}
+
+	if len(filter.Status) > 0 {
+		db = db.Where("status IN ?", filter.Status)
+	}
+
+	if err = db.Order("created_at, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Count counts the enrollments of a class with the given status
+func (pg *PGEnrollment) Count(classID uint, status string) (count int64, err error) {
+	if err = pg.DB.Model(&enrollment.Enrollment{}).
+		Where("class_id = ? AND status = ?", classID, status).
+		Count(&count).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return count, nil
+}
+
+// NextWaitlisted fetches the oldest waitlisted enrollment of a class,
+// leaving the output untouched when the waitlist is empty
+func (pg *PGEnrollment) NextWaitlisted(out *enrollment.Enrollment, classID uint) (err error) {
+	if err = pg.DB.Where("class_id = ? AND status = ?", classID, enrollment.StatusWaitlisted).
+		Order("created_at, id").Limit(1).Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
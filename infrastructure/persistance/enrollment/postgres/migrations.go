@@ -0,0 +1,14 @@
+package postgres
+
+// Migrations holds the schema changes for enrollments
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	// a student has at most one active enrollment per class
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_enrollments_active
+		ON enrollments (class_id, student_id) WHERE status <> 'canceled'`,
+	`DO $$ BEGIN
+		ALTER TABLE enrollments ADD CONSTRAINT chk_enrollments_status
+			CHECK (status IN ('enrolled', 'waitlisted', 'canceled'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
@@ -0,0 +1,48 @@
+package enrollment
+
+import (
+	"go-api/domain/entities/enrollment"
+	"go-api/infrastructure/persistance/enrollment/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IEnrollment methods
+type Repository struct{}
+
+// Add is a function that manage the flow of enrollment insertion into database
+func (r *Repository) Add(in *enrollment.Enrollment, db *gorm.DB) error {
+	data := postgres.PGEnrollment{DB: db}
+	return data.Add(in)
+}
+
+// Update updates an enrollment
+func (r *Repository) Update(in *enrollment.Enrollment, db *gorm.DB) error {
+	data := postgres.PGEnrollment{DB: db}
+	return data.Update(in)
+}
+
+// GetActive returns the active enrollment of a student in a class
+func (r *Repository) GetActive(in *enrollment.Enrollment, db *gorm.DB) error {
+	data := postgres.PGEnrollment{DB: db}
+	return data.GetActive(in)
+}
+
+// GetAll list the enrollments matching the filter
+func (r *Repository) GetAll(out *[]enrollment.Enrollment, filter *enrollment.Filter, db *gorm.DB) error {
+	data := postgres.PGEnrollment{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// Count counts the enrollments of a class with a status
+func (r *Repository) Count(classID uint, status string, db *gorm.DB) (int64, error) {
+	data := postgres.PGEnrollment{DB: db}
+	return data.Count(classID, status)
+}
+
+// NextWaitlisted returns the oldest waitlisted enrollment of a class
+func (r *Repository) NextWaitlisted(out *enrollment.Enrollment, classID uint, db *gorm.DB) error {
+	data := postgres.PGEnrollment{DB: db}
+	return data.NextWaitlisted(out, classID)
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"go-api/domain/entities/notification"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+)
+
+// PGNotification is a base structure
+// that implements methods for query execution
+type PGNotification struct {
+	DB *gorm.DB
+}
+
+// Add insert a notification into the database
+func (pg *PGNotification) Add(in *notification.Notification) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the notifications matching the filter, the newest first,
+// or the oldest first when listing the ones after a given ID
+func (pg *PGNotification) GetAll(out *[]notification.Notification, filter *notification.Filter) (err error) {
+	db := pg.DB.Where("user_id = ?", filter.UserID)
+
+	if filter.Unread {
+		db = db.Where("read_at IS NULL")
+	}
+
+	if filter.AfterID != nil {
+		where, args := "id > ?", []interface{}{filter.AfterID}
+
+		if filter.PendingSince != nil {
+			pending := "read_at IS NULL AND created_at >= ?"
+			args = append(args, filter.PendingSince)
+
+			if len(filter.Sent) > 0 {
+				pending += " AND id NOT IN ?"
+				args = append(args, filter.Sent)
+			}
+
+			where = "(" + where + " OR (" + pending + "))"
+		}
+
+		db = db.Where(where, args...).Order("id")
+	} else {
+		db = db.Order("id DESC")
+	}
+
+	if filter.Limit > 0 {
+		db = db.Limit(filter.Limit)
+	}
+
+	if err = db.Offset(filter.Offset).Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// CountUnread counts the notifications of an user whose read date is not set
+func (pg *PGNotification) CountUnread(userID uint) (count int64, err error) {
+	if err = pg.DB.Model(&notification.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return count, nil
+}
+
+// LastID returns the ID of the newest notification of an user, zero when there is none
+func (pg *PGNotification) LastID(userID uint) (id uint, err error) {
+	if err = pg.DB.Model(&notification.Notification{}).
+		Select("coalesce(max(id), 0)").
+		Where("user_id = ?", userID).
+		Scan(&id).Error; err != nil {
+		return 0, oops.Err(err)
+	}
+	return id, nil
+}
+
+// MarkRead sets the read date of the unread notifications of an user,
+// restricted to the given IDs when there is any
+func (pg *PGNotification) MarkRead(userID uint, ids []uint) (err error) {
+	db := pg.DB.Model(&notification.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+
+	if err = db.Update("read_at", gorm.Expr("now()")).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package postgres
+
+// Migrations holds the schema changes for notifications
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	// NOTIFY is only delivered when the transaction commits, so the
+	// streams never push notifications that were rolled back
+	`CREATE OR REPLACE FUNCTION notifications_publish() RETURNS trigger AS $$
+	BEGIN
+		PERFORM pg_notify('notifications', NEW.user_id::text);
+		RETURN NEW;
+	END $$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_notifications_publish ON notifications`,
+	`CREATE TRIGGER trg_notifications_publish AFTER INSERT ON notifications
+		FOR EACH ROW EXECUTE FUNCTION notifications_publish()`,
+}
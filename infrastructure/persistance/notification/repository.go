@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"go-api/domain/entities/notification"
+	"go-api/infrastructure/persistance/notification/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements INotification methods
+type Repository struct{}
+
+// Add inserts a notification
+func (r *Repository) Add(in *notification.Notification, db *gorm.DB) error {
+	data := postgres.PGNotification{DB: db}
+	return data.Add(in)
+}
+
+// GetAll list the notifications matching the filter
+func (r *Repository) GetAll(out *[]notification.Notification, filter *notification.Filter, db *gorm.DB) error {
+	data := postgres.PGNotification{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// CountUnread counts the notifications of an user not read yet
+func (r *Repository) CountUnread(userID uint, db *gorm.DB) (int64, error) {
+	data := postgres.PGNotification{DB: db}
+	return data.CountUnread(userID)
+}
+
+// LastID returns the ID of the newest notification of an user
+func (r *Repository) LastID(userID uint, db *gorm.DB) (uint, error) {
+	data := postgres.PGNotification{DB: db}
+	return data.LastID(userID)
+}
+
+// MarkRead marks notifications of an user as read
+func (r *Repository) MarkRead(userID uint, ids []uint, db *gorm.DB) error {
+	data := postgres.PGNotification{DB: db}
+	return data.MarkRead(userID, ids)
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"go-api/domain/entities/order"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PGOrder is a base structure
+// that implements methods for query execution
+type PGOrder struct {
+	DB *gorm.DB
+}
+
+// Add insert an order into the database
+func (pg *PGOrder) Add(in *order.Order) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of an order
+func (pg *PGOrder) Update(in *order.Order) (err error) {
+	if err = pg.DB.Model(&order.Order{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches an order by its ID
+func (pg *PGOrder) Get(in *order.Order) (err error) {
+	if err = pg.DB.Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Lock fetches an order by its ID locking it for update
+func (pg *PGOrder) Lock(in *order.Order) (err error) {
+	if err = pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// LockByExternalID fetches an order by its provider and external ID locking it for update
+func (pg *PGOrder) LockByExternalID(in *order.Order) (err error) {
+	if err = pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND external_id = ?", in.Provider, in.ExternalID).
+		First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetOpen fetches the pending or paid order of an enrollment
+func (pg *PGOrder) GetOpen(in *order.Order) (err error) {
+	if err = pg.DB.Where("enrollment_id = ? AND status IN ?", in.EnrollmentID, []string{order.StatusPending, order.StatusPaid}).
+		First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the orders matching the filter, newest first
+func (pg *PGOrder) GetAll(out *[]order.Order, filter *order.Filter) (err error) {
+	db := pg.DB
+
+	if filter.UserID != nil {
+		db = db.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.ClassID != nil {
+		db = db.Where("class_id = ?", filter.ClassID)
+	}
+
+	if filter.EnrollmentID != nil {
+		db = db.Where("enrollment_id = ?", filter.EnrollmentID)
+	}
+
+	if len(filter.Status) > 0 {
+		db = db.Where("status IN ?", filter.Status)
+	}
+
+	if err = db.Order("created_at DESC, id DESC").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddEvent records a webhook event of an order
+func (pg *PGOrder) AddEvent(in *order.Event) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// HasEvent checks whether a webhook event of a provider was already received
+func (pg *PGOrder) HasEvent(provider, eventID string) (found bool, err error) {
+	var count int64
+
+	if err = pg.DB.Model(&order.Event{}).
+		Where("provider = ? AND event_id = ?", provider, eventID).
+		Count(&count).Error; err != nil {
+		return false, oops.Err(err)
+	}
+	return count > 0, nil
+}
+
+// AddEntry records a financial movement on the ledger
+func (pg *PGOrder) AddEntry(in *order.LedgerEntry) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetEntries lists the ledger entries of an user in chronological order
+func (pg *PGOrder) GetEntries(out *[]order.LedgerEntry, userID uint) (err error) {
+	if err = pg.DB.Where("user_id = ?", userID).Order("created_at, id").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddRefund insert a refund into the database
+func (pg *PGOrder) AddRefund(in *order.Refund) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// UpdateRefund updates the non empty fields of a refund, as long as
+// it is still pending, so concurrent senders record it only once
+func (pg *PGOrder) UpdateRefund(in *order.Refund) (err error) {
+	if err = pg.DB.Model(&order.Refund{}).Where("id = ? AND status = ?", in.ID, order.RefundPending).
+		Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// PendingRefunds lists the pending refunds along with their orders,
+// the ones attempted less recently first
+func (pg *PGOrder) PendingRefunds(out *[]order.Refund, limit int) (err error) {
+	if err = pg.DB.Preload("Order").Where("status = ?", order.RefundPending).
+		Order("updated_at, id").Limit(limit).Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
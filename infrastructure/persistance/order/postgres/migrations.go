@@ -0,0 +1,54 @@
+package postgres
+
+// Migrations holds the schema changes for orders
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE orders ADD CONSTRAINT chk_orders_status
+			CHECK (status IN ('pending', 'paid', 'failed', 'refunded'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE orders ADD CONSTRAINT chk_orders_amount
+			CHECK (discount >= 0 AND amount >= 0 AND amount = price - discount);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE orders ADD CONSTRAINT chk_orders_refunded
+			CHECK (refunded >= 0 AND refunded <= amount);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE orders ADD CONSTRAINT chk_orders_fee_rate
+			CHECK (fee_rate BETWEEN 0 AND 10000);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// reports aggregate the ledger by the instant of the movements
+	`CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_at
+		ON ledger_entries (created_at)`,
+	// recreated so the kinds added later are accepted
+	`ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS chk_ledger_entries_kind`,
+	`ALTER TABLE ledger_entries ADD CONSTRAINT chk_ledger_entries_kind
+		CHECK (kind IN ('payment', 'refund') AND amount > 0)`,
+	// an enrollment is charged by at most one order at a time
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_open
+		ON orders (enrollment_id) WHERE status IN ('pending', 'paid')`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_external
+		ON orders (provider, external_id) WHERE external_id IS NOT NULL`,
+	`DO $$ BEGIN
+		ALTER TABLE order_refunds ADD CONSTRAINT chk_order_refunds_status
+			CHECK (status IN ('pending', 'completed', 'failed') AND amount > 0);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// a webhook event is applied only once, even when redelivered
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_order_events_event
+		ON order_events (provider, event_id)`,
+	// orders placed before they kept the use of their coupon get the one
+	// recorded by the same checkout, right before the order was added
+	`UPDATE orders o SET redemption_id = (
+		SELECT r.id FROM coupon_redemptions r JOIN coupons c ON c.id = r.coupon_id
+		WHERE c.code = o.coupon_code AND r.user_id = o.user_id AND r.class_id = o.class_id
+			AND r.created_at <= o.created_at
+		ORDER BY r.created_at DESC LIMIT 1)
+	WHERE o.coupon_code IS NOT NULL AND o.redemption_id IS NULL`,
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"go-api/domain/entities/order"
+	"go-api/oops"
+	"strings"
+)
+
+// keptSQL is the amount a ledger entry adds to the earnings, negative for
+// refunds, and feeSQL the platform fee charged on it, rounded half away
+// from zero like utils.Money
+const (
+	keptSQL = "(CASE e.kind WHEN 'refund' THEN -e.amount ELSE e.amount END)"
+	feeSQL  = "round(" + keptSQL + " * o.fee_rate / 10000.0)"
+)
+
+// Earnings aggregates the payments and refunds recorded in the ledger within
+// the filter period, by currency and the groups asked by the filter. Refunds
+// count on the period they happened, so closed periods never change
+func (pg *PGOrder) Earnings(out *[]order.Earning, filter *order.ReportFilter) (err error) {
+	var (
+		fields = []string{
+			"e.currency",
+			"count(DISTINCT e.order_id) FILTER (WHERE e.kind = 'payment') AS orders",
+			"coalesce(sum(e.amount) FILTER (WHERE e.kind = 'payment'), 0)::bigint AS gross",
+			"coalesce(sum(e.amount) FILTER (WHERE e.kind = 'refund'), 0)::bigint AS refunded",
+			"sum(" + feeSQL + ")::bigint AS fee",
+			"sum(" + keptSQL + " - " + feeSQL + ")::bigint AS net",
+		}
+		groups = []string{"e.currency"}
+		args   = []interface{}{}
+	)
+
+	if filter.Period != "" {
+		fields = append([]string{"date_trunc(?, e.created_at AT TIME ZONE ?) AS period"}, fields...)
+		groups = append([]string{"period"}, groups...)
+		args = append(args, filter.Period, filter.TimeZone)
+	}
+
+	if filter.ByTeacher {
+		fields = append(fields, "c.teacher_id")
+		groups = append(groups, "c.teacher_id")
+	}
+
+	if filter.ByClass {
+		fields = append(fields, "c.id AS class_id", "c.name AS class_name")
+		groups = append(groups, "c.id", "c.name")
+	}
+
+	db := pg.DB.Table("ledger_entries e").
+		Select(strings.Join(fields, ", "), args...).
+		Joins("JOIN orders o ON o.id = e.order_id").
+		Joins("JOIN classes c ON c.id = o.class_id").
+		Where("e.created_at >= ? AND e.created_at < ?", filter.From, filter.To)
+
+	if filter.TeacherID != nil {
+		db = db.Where("c.teacher_id = ?", filter.TeacherID)
+	}
+
+	if filter.ClassID != nil {
+		db = db.Where("c.id = ?", filter.ClassID)
+	}
+
+	if err = db.Group(strings.Join(groups, ", ")).Order(strings.Join(groups, ", ")).Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package order
+
+import (
+	"go-api/domain/entities/order"
+	"go-api/infrastructure/persistance/order/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IOrder methods
+type Repository struct{}
+
+// Add is a function that manage the flow of order insertion into database
+func (r *Repository) Add(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.Add(in)
+}
+
+// Update updates an order
+func (r *Repository) Update(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.Update(in)
+}
+
+// Get returns an order by its ID
+func (r *Repository) Get(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.Get(in)
+}
+
+// Lock returns an order by its ID locking it for update
+func (r *Repository) Lock(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.Lock(in)
+}
+
+// LockByExternalID returns an order by its payment ID locking it for update
+func (r *Repository) LockByExternalID(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.LockByExternalID(in)
+}
+
+// GetOpen returns the pending or paid order of an enrollment
+func (r *Repository) GetOpen(in *order.Order, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.GetOpen(in)
+}
+
+// GetAll list the orders matching the filter
+func (r *Repository) GetAll(out *[]order.Order, filter *order.Filter, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// AddEvent records a webhook event of an order
+func (r *Repository) AddEvent(in *order.Event, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.AddEvent(in)
+}
+
+// HasEvent checks whether a webhook event was already received
+func (r *Repository) HasEvent(provider, eventID string, db *gorm.DB) (bool, error) {
+	data := postgres.PGOrder{DB: db}
+	return data.HasEvent(provider, eventID)
+}
+
+// AddEntry records a financial movement on the ledger
+func (r *Repository) AddEntry(in *order.LedgerEntry, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.AddEntry(in)
+}
+
+// GetEntries list the ledger entries of an user
+func (r *Repository) GetEntries(out *[]order.LedgerEntry, userID uint, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.GetEntries(out, userID)
+}
+
+// AddRefund records a refund to be sent to the provider
+func (r *Repository) AddRefund(in *order.Refund, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.AddRefund(in)
+}
+
+// UpdateRefund updates a refund still pending
+func (r *Repository) UpdateRefund(in *order.Refund, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.UpdateRefund(in)
+}
+
+// PendingRefunds lists the refunds waiting to be sent to the provider
+func (r *Repository) PendingRefunds(out *[]order.Refund, limit int, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.PendingRefunds(out, limit)
+}
+
+// Earnings aggregates the paid orders matching the filter
+func (r *Repository) Earnings(out *[]order.Earning, filter *order.ReportFilter, db *gorm.DB) error {
+	data := postgres.PGOrder{DB: db}
+	return data.Earnings(out, filter)
+}
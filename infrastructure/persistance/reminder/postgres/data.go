@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"go-api/domain/entities/enrollment"
+	"go-api/domain/entities/reminder"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// preferenceColumns maps each kind of reminder to the
+// preference column that enables it
+var preferenceColumns = map[string]string{
+	reminder.KindDayBefore:  "day_before",
+	reminder.KindHourBefore: "hour_before",
+}
+
+// PGReminder is a base structure
+// that implements methods for query execution
+type PGReminder struct {
+	DB *gorm.DB
+}
+
+// GetPreference fetches the reminder preference of an user
+func (pg *PGReminder) GetPreference(in *reminder.Preference) (err error) {
+	if err = pg.DB.Where("user_id = ?", in.UserID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// SavePreference inserts the reminder preference of an
+// user or replaces the current one
+func (pg *PGReminder) SavePreference(in *reminder.Preference) (err error) {
+	if err = pg.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"day_before", "hour_before", "updated_at"}),
+	}).Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Pending lists, for the schedules starting in the filter period, the
+// enrolled students and the teacher who want the reminder of the filter
+// kind and haven't received it yet, the earliest schedules first
+func (pg *PGReminder) Pending(out *[]reminder.Pending, filter *reminder.Filter) (err error) {
+	db := pg.DB.Table("schedules s").
+		Select("s.id AS schedule_id, r.user_id, c.id AS class_id, c.name AS class_name, "+
+			"s.starts_at, c.time_zone AS class_time_zone, u.time_zone AS user_time_zone").
+		Joins("JOIN classes c ON c.id = s.class_id AND c.deleted_at IS NULL").
+		Joins("CROSS JOIN LATERAL (SELECT e.student_id AS user_id FROM enrollments e "+
+			"WHERE e.class_id = c.id AND e.status = ? UNION SELECT c.teacher_id) r", enrollment.StatusEnrolled).
+		Joins("JOIN users u ON u.id = r.user_id AND u.deleted_at IS NULL").
+		Joins("LEFT JOIN reminder_preferences p ON p.user_id = r.user_id").
+		Where("s.deleted_at IS NULL AND s.starts_at > ? AND s.starts_at <= ?", filter.From, filter.To).
+		Where("coalesce(p."+preferenceColumns[filter.Kind]+", true)").
+		Where("NOT EXISTS (SELECT 1 FROM reminder_deliveries d "+
+			"WHERE d.schedule_id = s.id AND d.user_id = r.user_id AND d.kind = ?)", filter.Kind)
+
+	if err = db.Order("s.starts_at, s.id, r.user_id").Limit(filter.Limit).Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddDelivery inserts a reminder delivery unless the same reminder was already
+// delivered to the user, reporting whether the delivery was inserted
+func (pg *PGReminder) AddDelivery(in *reminder.Delivery) (inserted bool, err error) {
+	db := pg.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(in)
+	if err = db.Error; err != nil {
+		return false, oops.Err(err)
+	}
+	return db.RowsAffected == 1, nil
+}
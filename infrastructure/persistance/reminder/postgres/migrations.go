@@ -0,0 +1,13 @@
+package postgres
+
+// Migrations holds the schema changes for reminders
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE reminder_deliveries ADD CONSTRAINT chk_reminder_deliveries_kind
+			CHECK (kind IN ('day_before', 'hour_before'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	// the scheduler looks for the schedules starting soon
+	`CREATE INDEX IF NOT EXISTS idx_schedules_starts_at ON schedules (starts_at) WHERE deleted_at IS NULL`,
+}
@@ -0,0 +1,36 @@
+package reminder
+
+import (
+	"go-api/domain/entities/reminder"
+	"go-api/infrastructure/persistance/reminder/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IReminder methods
+type Repository struct{}
+
+// GetPreference returns the reminder preference of an user
+func (r *Repository) GetPreference(in *reminder.Preference, db *gorm.DB) error {
+	data := postgres.PGReminder{DB: db}
+	return data.GetPreference(in)
+}
+
+// SavePreference inserts or replaces the reminder preference of an user
+func (r *Repository) SavePreference(in *reminder.Preference, db *gorm.DB) error {
+	data := postgres.PGReminder{DB: db}
+	return data.SavePreference(in)
+}
+
+// Pending lists the reminders due and not delivered yet
+func (r *Repository) Pending(out *[]reminder.Pending, filter *reminder.Filter, db *gorm.DB) error {
+	data := postgres.PGReminder{DB: db}
+	return data.Pending(out, filter)
+}
+
+// AddDelivery records a reminder delivery, reporting whether it wasn't delivered before
+func (r *Repository) AddDelivery(in *reminder.Delivery, db *gorm.DB) (bool, error) {
+	data := postgres.PGReminder{DB: db}
+	return data.AddDelivery(in)
+}
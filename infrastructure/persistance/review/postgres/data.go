@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"go-api/domain/entities/review"
+	"go-api/oops"
+
+	"gorm.io/gorm"
+)
+
+// PGReview is a base structure
+// that implements methods for query execution
+type PGReview struct {
+	DB *gorm.DB
+}
+
+// Add insert a review into the database
+func (pg *PGReview) Add(in *review.Review) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Update updates the non empty fields of a review
+func (pg *PGReview) Update(in *review.Review) (err error) {
+	if err = pg.DB.Model(&review.Review{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Delete removes a review by its ID
+func (pg *PGReview) Delete(id uint) (err error) {
+	if err = pg.DB.Where("id = ?", id).Delete(&review.Review{}).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches a review by its ID
+func (pg *PGReview) Get(in *review.Review) (err error) {
+	if err = pg.DB.Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// GetAll lists the reviews matching the filter, the newest first
+func (pg *PGReview) GetAll(out *[]review.Review, filter *review.Filter) (err error) {
+	if err = pg.filtered(filter).Order("reviews.created_at DESC, reviews.id DESC").Find(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// AddFlag insert a review flag into the database
+func (pg *PGReview) AddFlag(in *review.Flag) (err error) {
+	if err = pg.DB.Create(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Summarize computes the average and the count of the reviews matching the filter
+func (pg *PGReview) Summarize(out *review.Rating, filter *review.Filter) (err error) {
+	if err = pg.filtered(filter).Model(&review.Review{}).
+		Select("coalesce(round(avg(reviews.rating), 2), 0) AS average, count(*) AS count").
+		Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// filtered applies the filter conditions to the query
+func (pg *PGReview) filtered(filter *review.Filter) *gorm.DB {
+	db := pg.DB
+
+	if filter.ClassID != nil {
+		db = db.Where("reviews.class_id = ?", filter.ClassID)
+	}
+
+	// reviews of removed classes still count for the teacher
+	if filter.TeacherID != nil {
+		db = db.Joins("JOIN classes c ON c.id = reviews.class_id").Where("c.teacher_id = ?", filter.TeacherID)
+	}
+
+	if len(filter.Status) > 0 {
+		db = db.Where("reviews.status IN ?", filter.Status)
+	}
+
+	if filter.Flagged {
+		db = db.Where("reviews.flags > 0")
+	}
+
+	return db
+}
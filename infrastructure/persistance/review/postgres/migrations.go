@@ -0,0 +1,16 @@
+package postgres
+
+// Migrations holds the schema changes for reviews
+// that gorm AutoMigrate is not able to apply by itself
+var Migrations = []string{
+	`DO $$ BEGIN
+		ALTER TABLE reviews ADD CONSTRAINT chk_reviews_rating
+			CHECK (rating BETWEEN 1 AND 5);
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+	`DO $$ BEGIN
+		ALTER TABLE reviews ADD CONSTRAINT chk_reviews_status
+			CHECK (status IN ('visible', 'hidden'));
+	EXCEPTION WHEN duplicate_object THEN NULL;
+	END $$`,
+}
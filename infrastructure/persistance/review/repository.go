@@ -0,0 +1,54 @@
+package review
+
+import (
+	"go-api/domain/entities/review"
+	"go-api/infrastructure/persistance/review/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements IReview methods
+type Repository struct{}
+
+// Add inserts a review
+func (r *Repository) Add(in *review.Review, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.Add(in)
+}
+
+// Update updates a review
+func (r *Repository) Update(in *review.Review, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.Update(in)
+}
+
+// Delete removes a review
+func (r *Repository) Delete(id uint, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.Delete(id)
+}
+
+// Get returns a review by its ID
+func (r *Repository) Get(in *review.Review, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.Get(in)
+}
+
+// GetAll list the reviews matching the filter
+func (r *Repository) GetAll(out *[]review.Review, filter *review.Filter, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.GetAll(out, filter)
+}
+
+// AddFlag inserts a review flag
+func (r *Repository) AddFlag(in *review.Flag, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.AddFlag(in)
+}
+
+// Summarize computes the rating of the reviews matching the filter
+func (r *Repository) Summarize(out *review.Rating, filter *review.Filter, db *gorm.DB) error {
+	data := postgres.PGReview{DB: db}
+	return data.Summarize(out, filter)
+}
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"go-api/domain/entities/search"
+	categoryPostgres "go-api/infrastructure/persistance/category/postgres"
+	classPostgres "go-api/infrastructure/persistance/class/postgres"
+	"go-api/oops"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// PGSearch is a base structure
+// that implements methods for query execution
+type PGSearch struct {
+	DB *gorm.DB
+}
+
+// Classes searches the classes open to students whose name or description,
+// or whose teacher name or bio, match the filter query. Teacher matches weigh
+// half of the class ones on the rank. Without a query the classes are only filtered
+func (pg *PGSearch) Classes(out *[]search.Result, filter *search.Filter) (err error) {
+	var (
+		columns = []string{
+			"c.id AS class_id", "c.name", "c.description", "c.price", "c.currency", "c.capacity", "c.time_zone",
+			"c.teacher_id", "t.name AS teacher_name", "c.rating_average", "c.rating_count",
+		}
+		where = []string{"c.deleted_at IS NULL", "c.status IN ('published', 'full')"}
+		from  = "classes c JOIN users t ON t.id = c.teacher_id"
+		order = "c.id"
+		args  []interface{}
+	)
+
+	// the next schedule is the first one of the period, or the first upcoming one
+	next := "now()"
+	if filter.From != nil {
+		next = "?"
+		args = append(args, filter.From)
+	}
+
+	columns = append(columns, "(SELECT min(s.starts_at) FROM schedules s WHERE s.class_id = c.id "+
+		"AND s.deleted_at IS NULL AND s.starts_at >= "+next+") AS next_start")
+
+	if filter.Query != "" {
+		// the highlights use the query parsed by the configuration they are written in
+		query := "q.pt"
+		if filter.Config == search.ConfigEnglish {
+			query = "q.en"
+		}
+
+		// the source text is HTML escaped before highlighting, so the
+		// <b> tags added by ts_headline are the only markup of the snippets
+		headline := func(field, options, alias string) string {
+			return "ts_headline('" + filter.Config + "', " + escapeHTML(field) + ", " + query + ", '" + options + "') AS " + alias
+		}
+
+		columns = append(columns,
+			"ts_rank(c.search_pt, q.pt) + ts_rank(c.search_en, q.en) + "+
+				"0.5 * (ts_rank(t.search_pt, q.pt) + ts_rank(t.search_en, q.en)) AS rank",
+			headline("c.name", "HighlightAll=true", "name_highlight"),
+			headline("c.description", "MaxFragments=2, MinWords=5, MaxWords=20", "description_highlight"),
+			headline("t.name", "HighlightAll=true", "teacher_highlight"),
+			headline("t.bio", "MaxFragments=1, MinWords=5, MaxWords=20", "bio_highlight"),
+		)
+
+		from += " CROSS JOIN (SELECT websearch_to_tsquery('" + search.ConfigPortuguese + "', ?) AS pt, " +
+			"websearch_to_tsquery('" + search.ConfigEnglish + "', ?) AS en) q"
+		args = append(args, filter.Query, filter.Query)
+
+		where = append(where, "(c.search_pt @@ q.pt OR c.search_en @@ q.en OR t.search_pt @@ q.pt OR t.search_en @@ q.en)")
+		order = "rank DESC, c.id"
+	}
+
+	if filter.MinPrice != nil {
+		where = append(where, "c.price >= ?")
+		args = append(args, filter.MinPrice)
+	}
+
+	if filter.MaxPrice != nil {
+		where = append(where, "c.price <= ?")
+		args = append(args, filter.MaxPrice)
+	}
+
+	if filter.Currency != nil {
+		where = append(where, "c.currency = ?")
+		args = append(args, filter.Currency)
+	}
+
+	if filter.TeacherID != nil {
+		where = append(where, "c.teacher_id = ?")
+		args = append(args, filter.TeacherID)
+	}
+
+	if filter.CategoryID != nil {
+		where = append(where, "c.category_id IN ("+categoryPostgres.SubtreeSQL+")")
+		args = append(args, filter.CategoryID)
+	}
+
+	if len(filter.Tags) > 0 {
+		where = append(where, "c.id IN ("+classPostgres.TaggedSQL+")")
+		args = append(args, filter.Tags, len(filter.Tags))
+	}
+
+	if filter.From != nil {
+		schedules := "EXISTS (SELECT 1 FROM schedules s WHERE s.class_id = c.id AND s.deleted_at IS NULL AND s.starts_at >= ?"
+		args = append(args, filter.From)
+
+		if filter.To != nil {
+			schedules += " AND s.starts_at < ?"
+			args = append(args, filter.To)
+		}
+
+		where = append(where, schedules+")")
+	}
+
+	sql := "SELECT " + strings.Join(columns, ", ") +
+		" FROM " + from +
+		" WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY " + order +
+		" LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	if err = pg.DB.Raw(sql, args...).Scan(out).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// escapeHTML wraps a text column in the replaces escaping its HTML special characters
+func escapeHTML(field string) string {
+	for _, r := range [][2]string{{"&", "&amp;"}, {"<", "&lt;"}, {">", "&gt;"}, {`"`, "&quot;"}, {"'", "&#39;"}} {
+		field = "replace(" + field + ", '" + strings.ReplaceAll(r[0], "'", "''") + "', '" + r[1] + "')"
+	}
+	return field
+}
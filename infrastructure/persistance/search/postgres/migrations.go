@@ -0,0 +1,38 @@
+package postgres
+
+// Migrations holds the full text search columns and indexes of classes
+// and users. The tsvector columns are generated by the database from
+// the searched fields, in Portuguese and in English
+var Migrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`DO $$ BEGIN
+		CREATE TEXT SEARCH CONFIGURATION portuguese_unaccent (COPY = portuguese);
+		ALTER TEXT SEARCH CONFIGURATION portuguese_unaccent
+			ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+	EXCEPTION WHEN unique_violation OR duplicate_object THEN NULL;
+	END $$`,
+	`ALTER TABLE classes ADD COLUMN IF NOT EXISTS search_pt tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('portuguese_unaccent', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('portuguese_unaccent', coalesce(description, '')), 'B')
+		) STORED`,
+	`ALTER TABLE classes ADD COLUMN IF NOT EXISTS search_en tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(description, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_classes_search_pt ON classes USING gin (search_pt)`,
+	`CREATE INDEX IF NOT EXISTS idx_classes_search_en ON classes USING gin (search_en)`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_pt tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('portuguese_unaccent', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('portuguese_unaccent', coalesce(bio, '')), 'B')
+		) STORED`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS search_en tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce(bio, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_users_search_pt ON users USING gin (search_pt)`,
+	`CREATE INDEX IF NOT EXISTS idx_users_search_en ON users USING gin (search_en)`,
+}
@@ -0,0 +1,18 @@
+package search
+
+import (
+	"go-api/domain/entities/search"
+	"go-api/infrastructure/persistance/search/postgres"
+
+	"gorm.io/gorm"
+)
+
+// Repository is a base structure that
+// implements ISearch methods
+type Repository struct{}
+
+// Classes searches the classes matching the filter
+func (r *Repository) Classes(out *[]search.Result, filter *search.Filter, db *gorm.DB) error {
+	data := postgres.PGSearch{DB: db}
+	return data.Classes(out, filter)
+}
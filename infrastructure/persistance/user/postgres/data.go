@@ -6,6 +6,7 @@ import (
 	"go-api/oops"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // PGUser is a base structure
@@ -22,3 +23,28 @@ func (pg *PGUser) Add(in *user.User) (err error) {
 	}
 	return nil
 }
+
+// Update updates the non empty fields of an user
+func (pg *PGUser) Update(in *user.User) (err error) {
+	if err = pg.DB.Model(&user.User{}).Where("id = ?", in.ID).Updates(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Get fetches an user by his ID
+func (pg *PGUser) Get(in *user.User) (err error) {
+	if err = pg.DB.Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
+
+// Lock fetches an user by his ID, locking its row until the
+// end of the transaction so concurrent changes are serialized
+func (pg *PGUser) Lock(in *user.User) (err error) {
+	if err = pg.DB.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", in.ID).First(in).Error; err != nil {
+		return oops.Err(err)
+	}
+	return nil
+}
@@ -21,8 +21,9 @@ func (r *Repository) Add(in *user.User, db *gorm.DB) error {
 }
 
 // Update updates an user
-func (r *Repository) Update(u *user.User) error {
-	return nil
+func (r *Repository) Update(u *user.User, db *gorm.DB) error {
+	data := postgres.PGUser{DB: db}
+	return data.Update(u)
 }
 
 // Delete removes an user
@@ -31,8 +32,15 @@ func (r *Repository) Delete(id int64) error {
 }
 
 // Get returns an user by his ID
-func (r *Repository) Get(u *user.User) error {
-	return nil
+func (r *Repository) Get(u *user.User, db *gorm.DB) error {
+	data := postgres.PGUser{DB: db}
+	return data.Get(u)
+}
+
+// Lock returns an user by his ID locking it for update
+func (r *Repository) Lock(u *user.User, db *gorm.DB) error {
+	data := postgres.PGUser{DB: db}
+	return data.Lock(u)
 }
 
 // GetAll list all users
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// LocalName is the name of the local disk backend
+	LocalName = "local"
+	// defaultLocalPath is the directory files are stored in when the configuration doesn't say
+	defaultLocalPath = "uploads"
+)
+
+// Local stores the files on a directory of the local disk
+type Local struct {
+	root string
+}
+
+// NewLocal creates a local disk backend storing the files under root
+func NewLocal(root string) *Local {
+	return &Local{root: root}
+}
+
+// Name identifies the backend
+func (l *Local) Name() string {
+	return LocalName
+}
+
+// Save writes the content to a temporary file first, so readers
+// never see a partial file and a failed upload leaves nothing behind
+func (l *Local) Save(key string, content io.Reader) (err error) {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0750); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// Open reads a stored file
+func (l *Local) Open(key string) (io.ReadCloser, error) {
+	name, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+// Delete removes a stored file, ignoring the missing ones
+func (l *Local) Delete(key string) error {
+	name, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path maps a key to a file under the root, refusing the keys escaping it
+func (l *Local) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", errors.New("invalid storage key " + key)
+	}
+
+	return filepath.Join(l.root, filepath.FromSlash(clean)), nil
+}
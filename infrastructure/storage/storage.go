@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"go-api/config"
+	"io"
+)
+
+// ErrNotFound is returned when no file is stored under a key
+var ErrNotFound = errors.New("file not found")
+
+// Storage defines the operations a file storage backend must implement.
+// Keys are slash separated paths chosen by the caller
+type Storage interface {
+	// Name identifies the backend on the stored files
+	Name() string
+	// Save stores the content read under the key, replacing any previous file
+	Save(key string, content io.Reader) error
+	// Open reads the file stored under the key
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the file stored under the key, if any
+	Delete(key string) error
+}
+
+// New builds the storage backend chosen in the configuration
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", LocalName:
+		path := cfg.Path
+		if path == "" {
+			path = defaultLocalPath
+		}
+		return NewLocal(path), nil
+	}
+	return nil, errors.New("unknown storage backend " + cfg.Backend)
+}
@@ -0,0 +1,153 @@
+package attachment
+
+import (
+	app "go-api/application/entities/attachment"
+	"go-api/oops"
+	"go-api/utils"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// upload is the handler function to POST requests on /classes/:id/attachments endpoint
+func upload(c *gin.Context) {
+	var in app.INUpload
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if c.Request.ContentLength > app.MaxBodySize() {
+		oops.Handling(app.TooLarge(), c)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, app.MaxBodySize())
+
+	if err := c.ShouldBind(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	attachmentID, err := app.Upload(id, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, attachmentID)
+}
+
+// list is the handler function to GET requests on /classes/:id/attachments endpoint
+func list(c *gin.Context) {
+	var in app.INList
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetAll(id, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// url is the handler function to GET requests on /attachments/:id/url endpoint
+func url(c *gin.Context) {
+	var in app.INAccess
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetURL(id, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// content is the handler function to GET requests on /attachments/:id/content endpoint
+func content(c *gin.Context) {
+	var in app.INContent
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, file, err := app.Open(id, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	defer file.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": *out.Name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	c.Header("Content-Type", *out.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(*out.Size, 10))
+	c.Header("Content-Disposition", disposition)
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(200)
+
+	io.Copy(c.Writer, file)
+}
+
+// remove is the handler function to DELETE requests on /attachments/:id endpoint
+func remove(c *gin.Context) {
+	var in app.INAccess
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Delete(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,11 @@
+package attachment
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.POST("/classes/:id/attachments", upload)
+	r.GET("/classes/:id/attachments", list)
+	r.GET("/attachments/:id/url", url)
+	r.GET("/attachments/:id/content", content)
+	r.DELETE("/attachments/:id", remove)
+}
@@ -0,0 +1,144 @@
+package attendance
+
+import (
+	app "go-api/application/entities/attendance"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mark is the handler function to PUT requests on /classes/:id/schedules/:schedule_id/attendances endpoint
+func mark(c *gin.Context) {
+	var in app.INMarks
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	scheduleID, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Mark(classID, scheduleID, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listBySchedule is the handler function to GET requests on /classes/:id/schedules/:schedule_id/attendances endpoint
+func listBySchedule(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	scheduleID, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetBySchedule(classID, scheduleID, utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// newCheckInCode is the handler function to POST requests on /classes/:id/check-in-codes endpoint
+func newCheckInCode(c *gin.Context) {
+	var in app.INCheckInCode
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.NewCheckInCode(classID, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// checkIn is the handler function to POST requests on /classes/:id/check-ins endpoint
+func checkIn(c *gin.Context) {
+	var in app.INCheckIn
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.CheckIn(classID, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// classReport is the handler function to GET requests on /classes/:id/attendance endpoint
+func classReport(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetClassReport(classID)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// studentReport is the handler function to GET requests on /users/:id/attendance endpoint
+func studentReport(c *gin.Context) {
+	studentID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetStudentReport(studentID)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
@@ -0,0 +1,12 @@
+package attendance
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.PUT("/classes/:id/schedules/:schedule_id/attendances", mark)
+	r.GET("/classes/:id/schedules/:schedule_id/attendances", listBySchedule)
+	r.POST("/classes/:id/check-in-codes", newCheckInCode)
+	r.POST("/classes/:id/check-ins", checkIn)
+	r.GET("/classes/:id/attendance", classReport)
+	r.GET("/users/:id/attendance", studentReport)
+}
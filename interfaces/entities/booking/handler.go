@@ -0,0 +1,184 @@
+package booking
+
+import (
+	app "go-api/application/entities/booking"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addWindow is the handler function to POST requests on /users/:id/availability-windows endpoint
+func addWindow(c *gin.Context) {
+	var in app.INWindow
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	windowID, err := app.AddWindow(id, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, windowID)
+}
+
+// deleteWindow is the handler function to DELETE requests on /users/:id/availability-windows/:window_id endpoint
+func deleteWindow(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	windowID, err := utils.ParseIDParam(c, "window_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.DeleteWindow(id, windowID, utils.ParseBearerToken(c)); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// listWindows is the handler function to GET requests on /users/:id/availability-windows endpoint
+func listWindows(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetWindows(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// getSettings is the handler function to GET requests on /users/:id/booking-settings endpoint
+func getSettings(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetSettings(id, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// updateSettings is the handler function to PUT requests on /users/:id/booking-settings endpoint
+func updateSettings(c *gin.Context) {
+	var in app.INSettings
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.UpdateSettings(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// listSlots is the handler function to GET requests on /users/:id/slots endpoint
+func listSlots(c *gin.Context) {
+	var in app.INSlots
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetSlots(id, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// book is the handler function to POST requests on /users/:id/bookings endpoint
+func book(c *gin.Context) {
+	var in app.INBooking
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Book(id, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// list is the handler function to GET requests on /users/:id/bookings endpoint
+func list(c *gin.Context) {
+	var in app.INBookings
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetBookings(id, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
@@ -0,0 +1,14 @@
+package booking
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/users/:id/availability-windows", listWindows)
+	r.POST("/users/:id/availability-windows", addWindow)
+	r.DELETE("/users/:id/availability-windows/:window_id", deleteWindow)
+	r.GET("/users/:id/booking-settings", getSettings)
+	r.PUT("/users/:id/booking-settings", updateSettings)
+	r.GET("/users/:id/slots", listSlots)
+	r.GET("/users/:id/bookings", list)
+	r.POST("/users/:id/bookings", book)
+}
@@ -0,0 +1,112 @@
+package category
+
+import (
+	app "go-api/application/entities/category"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// add is the handler function to POST requests on /categories endpoint
+func add(c *gin.Context) {
+	var in app.INCategory
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.Add(&in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// update is the handler function to PUT requests on /categories/:id endpoint
+func update(c *gin.Context) {
+	var in app.INCategory
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Update(id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// remove is the handler function to DELETE requests on /categories/:id endpoint
+func remove(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Delete(id); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// get is the handler function to GET requests on /categories/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Get(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// list is the handler function to GET requests on /categories endpoint
+func list(c *gin.Context) {
+	out, err := app.GetTree()
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listTags is the handler function to GET requests on /tags endpoint
+func listTags(c *gin.Context) {
+	var in app.INTags
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetTags(&in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
@@ -0,0 +1,22 @@
+package category
+
+import (
+	"go-api/interfaces/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/categories", list)
+	r.GET("/categories/:id", get)
+	r.GET("/tags", listTags)
+}
+
+// AdminRouter registers the changes to the category tree, only served
+// to requests carrying the admin token in the X-Admin-Token header
+func AdminRouter(r *gin.RouterGroup, token string) {
+	admin := r.Group("/categories", middleware.AdminOnly(token))
+	admin.POST("", add)
+	admin.PUT("/:id", update)
+	admin.DELETE("/:id", remove)
+}
@@ -0,0 +1,170 @@
+package class
+
+import (
+	app "go-api/application/entities/class"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// add is the handler function to POST requests on /classes endpoint
+func add(c *gin.Context) {
+	var in app.INClass
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.Add(&in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// update is the handler function to PUT requests on /classes/:id endpoint
+func update(c *gin.Context) {
+	var in app.INClass
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Update(id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// remove is the handler function to DELETE requests on /classes/:id endpoint
+func remove(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Delete(id); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// get is the handler function to GET requests on /classes/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	include, err := app.ParseInclude(c.Query("include"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Get(id, include, utils.ParseLocale(c), c.Query("time_zone"), utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// list is the handler function to GET requests on /classes endpoint
+func list(c *gin.Context) {
+	var in app.INFilter
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	include, err := app.ParseInclude(c.Query("include"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetAll(nil, false, &in, include, utils.ParseLocale(c), c.Query("time_zone"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// calendar is the handler function to GET requests on /classes/:id/calendar.ics endpoint
+func calendar(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Calendar(id, utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Data(200, "text/calendar; charset=utf-8", out)
+}
+
+// updateStatus is the handler function to PUT requests on /classes/:id/status endpoint
+func updateStatus(c *gin.Context) {
+	var in app.INStatus
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.UpdateStatus(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// statusHistory is the handler function to GET requests on /classes/:id/status-history endpoint
+func statusHistory(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetStatusHistory(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
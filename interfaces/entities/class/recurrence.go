@@ -0,0 +1,151 @@
+package class
+
+import (
+	app "go-api/application/entities/class"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addRecurrence is the handler function to POST requests on /classes/:id/recurrences endpoint
+func addRecurrence(c *gin.Context) {
+	var in app.INRecurrence
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.AddRecurrence(classID, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// removeRecurrence is the handler function to DELETE requests on /classes/:id/recurrences/:recurrence_id endpoint
+func removeRecurrence(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "recurrence_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.DeleteRecurrence(classID, id); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// getRecurrence is the handler function to GET requests on /classes/:id/recurrences/:recurrence_id endpoint
+func getRecurrence(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "recurrence_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetRecurrence(classID, id, utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listRecurrences is the handler function to GET requests on /classes/:id/recurrences endpoint
+func listRecurrences(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetRecurrences(classID, utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// updateOccurrence is the handler function to PUT requests on
+// /classes/:id/recurrences/:recurrence_id/occurrences/:schedule_id endpoint
+func updateOccurrence(c *gin.Context) {
+	var in app.INOccurrence
+
+	classID, recurrenceID, scheduleID, err := occurrenceParams(c)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	scope := c.DefaultQuery("scope", app.ScopeThis)
+
+	if err := app.UpdateOccurrence(classID, recurrenceID, scheduleID, scope, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// cancelOccurrence is the handler function to DELETE requests on
+// /classes/:id/recurrences/:recurrence_id/occurrences/:schedule_id endpoint
+func cancelOccurrence(c *gin.Context) {
+	classID, recurrenceID, scheduleID, err := occurrenceParams(c)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	scope := c.DefaultQuery("scope", app.ScopeThis)
+
+	if err := app.CancelOccurrence(classID, recurrenceID, scheduleID, scope); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+func occurrenceParams(c *gin.Context) (classID, recurrenceID, scheduleID uint, err error) {
+	if classID, err = utils.ParseIDParam(c, "id"); err != nil {
+		return
+	}
+	if recurrenceID, err = utils.ParseIDParam(c, "recurrence_id"); err != nil {
+		return
+	}
+	scheduleID, err = utils.ParseIDParam(c, "schedule_id")
+	return
+}
@@ -0,0 +1,31 @@
+package class
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.POST("", add)
+	r.GET("", list)
+	r.GET("/:id", get)
+	r.PUT("/:id", update)
+	r.DELETE("/:id", remove)
+	r.GET("/:id/calendar.ics", calendar)
+	r.PUT("/:id/status", updateStatus)
+	r.GET("/:id/status-history", statusHistory)
+
+	r.POST("/:id/schedules", addSchedule)
+	r.GET("/:id/schedules", listSchedules)
+	r.POST("/:id/schedules/import", importSchedules)
+	r.GET("/:id/schedules/:schedule_id", getSchedule)
+	r.PUT("/:id/schedules/:schedule_id", updateSchedule)
+	r.DELETE("/:id/schedules/:schedule_id", removeSchedule)
+	r.PUT("/:id/schedules/:schedule_id/cancellation", cancelSchedule)
+	r.PUT("/:id/schedules/:schedule_id/rescheduling", reschedule)
+	r.GET("/:id/schedules/:schedule_id/history", scheduleHistory)
+
+	r.POST("/:id/recurrences", addRecurrence)
+	r.GET("/:id/recurrences", listRecurrences)
+	r.GET("/:id/recurrences/:recurrence_id", getRecurrence)
+	r.DELETE("/:id/recurrences/:recurrence_id", removeRecurrence)
+	r.PUT("/:id/recurrences/:recurrence_id/occurrences/:schedule_id", updateOccurrence)
+	r.DELETE("/:id/recurrences/:recurrence_id/occurrences/:schedule_id", cancelOccurrence)
+}
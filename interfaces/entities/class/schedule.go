@@ -0,0 +1,245 @@
+package class
+
+import (
+	app "go-api/application/entities/class"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// addSchedule is the handler function to POST requests on /classes/:id/schedules endpoint
+func addSchedule(c *gin.Context) {
+	var in app.INSchedule
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.AddSchedule(classID, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// updateSchedule is the handler function to PUT requests on /classes/:id/schedules/:schedule_id endpoint
+func updateSchedule(c *gin.Context) {
+	var in app.INSchedule
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.UpdateSchedule(classID, id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// removeSchedule is the handler function to DELETE requests on /classes/:id/schedules/:schedule_id endpoint
+func removeSchedule(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.DeleteSchedule(classID, id); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// getSchedule is the handler function to GET requests on /classes/:id/schedules/:schedule_id endpoint
+func getSchedule(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetSchedule(classID, id, c.Query("time_zone"), utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listSchedules is the handler function to GET requests on /classes/:id/schedules endpoint
+func listSchedules(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetSchedules(classID, c.Query("time_zone"), utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// importSchedules is the handler function to POST requests on /classes/:id/schedules/import endpoint.
+// The iCalendar file is sent on the file field of a multipart form, and
+// the preview query parameter only checks the resulting schedules
+func importSchedules(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		oops.Handling(oops.NewErr("Campo file é obrigatório"), c)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	defer file.Close()
+
+	preview := c.Query("preview") == "true"
+
+	out, err := app.ImportSchedules(classID, file, preview)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if preview {
+		c.JSON(200, out)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// cancelSchedule is the handler function to PUT requests on /classes/:id/schedules/:schedule_id/cancellation endpoint
+func cancelSchedule(c *gin.Context) {
+	var in app.INCancelSchedule
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.CancelSchedule(classID, id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// reschedule is the handler function to PUT requests on /classes/:id/schedules/:schedule_id/rescheduling endpoint
+func reschedule(c *gin.Context) {
+	var in app.INReschedule
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Reschedule(classID, id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// scheduleHistory is the handler function to GET requests on /classes/:id/schedules/:schedule_id/history endpoint
+func scheduleHistory(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := utils.ParseIDParam(c, "schedule_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetScheduleHistory(classID, id, c.Query("time_zone"), utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
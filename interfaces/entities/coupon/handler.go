@@ -0,0 +1,117 @@
+package coupon
+
+import (
+	app "go-api/application/entities/coupon"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// add is the handler function to POST requests on /coupons endpoint
+func add(c *gin.Context) {
+	var in app.INCoupon
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.Add(&in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// update is the handler function to PUT requests on /coupons/:id endpoint
+func update(c *gin.Context) {
+	var in app.INCoupon
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Update(id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// remove is the handler function to DELETE requests on /coupons/:id endpoint
+func remove(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Delete(id); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// get is the handler function to GET requests on /coupons/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Get(id, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// list is the handler function to GET requests on /coupons endpoint
+func list(c *gin.Context) {
+	out, err := app.GetAll(utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// quote is the handler function to GET requests on /classes/:id/quote endpoint
+func quote(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		oops.Handling(oops.NewErr("Parâmetro code é obrigatório"), c)
+		return
+	}
+
+	out, err := app.Quote(classID, code, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
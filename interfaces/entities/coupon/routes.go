@@ -0,0 +1,22 @@
+package coupon
+
+import (
+	"go-api/interfaces/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/classes/:id/quote", quote)
+}
+
+// AdminRouter registers the management of coupons, only served to
+// requests carrying the admin token in the X-Admin-Token header
+func AdminRouter(r *gin.RouterGroup, token string) {
+	admin := r.Group("/coupons", middleware.AdminOnly(token))
+	admin.POST("", add)
+	admin.GET("", list)
+	admin.GET("/:id", get)
+	admin.PUT("/:id", update)
+	admin.DELETE("/:id", remove)
+}
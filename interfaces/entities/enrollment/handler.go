@@ -0,0 +1,89 @@
+package enrollment
+
+import (
+	app "go-api/application/entities/enrollment"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// enroll is the handler function to POST requests on /classes/:id/enrollments endpoint
+func enroll(c *gin.Context) {
+	var in app.INEnrollment
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Enroll(classID, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// unenroll is the handler function to DELETE requests on /classes/:id/enrollments/:student_id endpoint
+func unenroll(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	studentID, err := utils.ParseIDParam(c, "student_id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Unenroll(classID, studentID); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// listByClass is the handler function to GET requests on /classes/:id/enrollments endpoint
+func listByClass(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetByClass(classID)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listByStudent is the handler function to GET requests on /users/:id/enrollments endpoint
+func listByStudent(c *gin.Context) {
+	studentID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetByStudent(studentID)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
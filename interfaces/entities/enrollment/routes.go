@@ -0,0 +1,10 @@
+package enrollment
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.POST("/classes/:id/enrollments", enroll)
+	r.GET("/classes/:id/enrollments", listByClass)
+	r.DELETE("/classes/:id/enrollments/:student_id", unenroll)
+	r.GET("/users/:id/enrollments", listByStudent)
+}
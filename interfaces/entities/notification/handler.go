@@ -0,0 +1,126 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	app "go-api/application/entities/notification"
+	"go-api/oops"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keepAliveInterval is how often a comment is sent on idle streams,
+// preventing proxies from closing the connection
+const keepAliveInterval = 30 * time.Second
+
+// list is the handler function to GET requests on /notifications endpoint
+func list(c *gin.Context) {
+	var in app.INFilter
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetAll(&in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// markRead is the handler function to PUT requests on /notifications/read endpoint
+func markRead(c *gin.Context) {
+	var in app.INRead
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.MarkRead(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// stream is the handler function to GET requests on /notifications/stream endpoint,
+// pushing the new notifications of an user as Server-Sent Events
+func stream(c *gin.Context) {
+	var in app.INStream
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	userID := *in.UserID
+
+	// subscribing first so nothing committed meanwhile is missed
+	signals, unsubscribe := app.Subscribe(userID)
+	defer unsubscribe()
+
+	cursor, err := app.StreamCursor(userID, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	// push sends the notifications not sent yet
+	push := func(w io.Writer) bool {
+		for {
+			out, err := cursor.Next()
+			if err != nil {
+				log.Println(err)
+				return false
+			}
+
+			if len(out.Data) == 0 {
+				return true
+			}
+
+			for _, item := range out.Data {
+				payload, err := json.Marshal(item)
+				if err != nil {
+					log.Println(err)
+					return false
+				}
+
+				fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", *item.ID, payload)
+			}
+		}
+	}
+
+	if !push(c.Writer) {
+		return
+	}
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			return true
+		case <-signals:
+			return push(w)
+		}
+	})
+}
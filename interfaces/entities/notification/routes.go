@@ -0,0 +1,9 @@
+package notification
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/notifications", list)
+	r.PUT("/notifications/read", markRead)
+	r.GET("/notifications/stream", stream)
+}
@@ -0,0 +1,120 @@
+package order
+
+import (
+	app "go-api/application/entities/order"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// signatureHeader carries the HMAC-SHA256 signature of webhook payloads
+const signatureHeader = "X-Signature"
+
+// checkout is the handler function to POST requests on /classes/:id/checkout endpoint
+func checkout(c *gin.Context) {
+	var in app.INCheckout
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Checkout(classID, &in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// get is the handler function to GET requests on /orders/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Get(id, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listByUser is the handler function to GET requests on /users/:id/orders endpoint
+func listByUser(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetByUser(id, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// webhook is the handler function to POST requests on /payments/webhook endpoint
+func webhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.HandleWebhook(payload, c.GetHeader(signatureHeader)); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// settle is the handler function to POST requests on /payments/fake/:external_id endpoint
+func settle(c *gin.Context) {
+	var in app.INSettlement
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Settle(c.Param("external_id"), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// ledger is the handler function to GET requests on /users/:id/ledger endpoint
+func ledger(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetLedger(id, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
@@ -0,0 +1,107 @@
+package order
+
+import (
+	app "go-api/application/entities/order"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// earnings is the handler function to GET requests on /users/:id/earnings endpoint
+func earnings(c *gin.Context) {
+	var in app.INReport
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetEarnings(id, utils.ParseBearerToken(c), &in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// earningsCSV is the handler function to GET requests on /users/:id/earnings.csv endpoint
+func earningsCSV(c *gin.Context) {
+	var in app.INReport
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetEarnings(id, utils.ParseBearerToken(c), &in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	csv, err := app.EarningsCSV(out.Data)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="earnings.csv"`)
+	c.Data(200, "text/csv; charset=utf-8", csv)
+}
+
+// revenue is the handler function to GET requests on /reports/revenue endpoint
+func revenue(c *gin.Context) {
+	var in app.INReport
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetRevenue(&in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// revenueCSV is the handler function to GET requests on /reports/revenue.csv endpoint
+func revenueCSV(c *gin.Context) {
+	var in app.INReport
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetRevenue(&in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	csv, err := app.EarningsCSV(out.Data)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="revenue.csv"`)
+	c.Data(200, "text/csv; charset=utf-8", csv)
+}
@@ -0,0 +1,31 @@
+package order
+
+import (
+	"go-api/interfaces/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	r.POST("/classes/:id/checkout", checkout)
+	r.GET("/orders/:id", get)
+	r.GET("/users/:id/orders", listByUser)
+	r.GET("/users/:id/ledger", ledger)
+	r.GET("/users/:id/earnings", earnings)
+	r.GET("/users/:id/earnings.csv", earningsCSV)
+	r.POST("/payments/webhook", webhook)
+}
+
+// AdminRouter registers the platform-wide reports, only served
+// to requests carrying the admin token in the X-Admin-Token header
+func AdminRouter(r *gin.RouterGroup, token string) {
+	admin := r.Group("/reports", middleware.AdminOnly(token))
+	admin.GET("/revenue", revenue)
+	admin.GET("/revenue.csv", revenueCSV)
+}
+
+// DevRouter registers the endpoints that simulate the payment provider,
+// which must never be exposed outside of local development
+func DevRouter(r *gin.RouterGroup) {
+	r.POST("/payments/fake/:external_id", settle)
+}
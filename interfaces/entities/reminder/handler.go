@@ -0,0 +1,49 @@
+package reminder
+
+import (
+	app "go-api/application/entities/reminder"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getPreference is the handler function to GET requests on /users/:id/reminder-preferences endpoint
+func getPreference(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetPreference(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// updatePreference is the handler function to PUT requests on /users/:id/reminder-preferences endpoint
+func updatePreference(c *gin.Context) {
+	var in app.INPreference
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.UpdatePreference(id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,8 @@
+package reminder
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/users/:id/reminder-preferences", getPreference)
+	r.PUT("/users/:id/reminder-preferences", updatePreference)
+}
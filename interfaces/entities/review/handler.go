@@ -0,0 +1,188 @@
+package review
+
+import (
+	app "go-api/application/entities/review"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// add is the handler function to POST requests on /classes/:id/reviews endpoint
+func add(c *gin.Context) {
+	var in app.INReview
+
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	id, err := app.Add(classID, utils.ParseBearerToken(c), &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, id)
+}
+
+// listByClass is the handler function to GET requests on /classes/:id/reviews endpoint
+func listByClass(c *gin.Context) {
+	classID, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetByClass(classID)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// list is the handler function to GET requests on /reviews endpoint
+func list(c *gin.Context) {
+	flagged := c.Query("flagged") == "true"
+
+	out, err := app.GetAll(flagged)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// get is the handler function to GET requests on /reviews/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Get(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// update is the handler function to PUT requests on /reviews/:id endpoint
+func update(c *gin.Context) {
+	var in app.INReview
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Update(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// remove is the handler function to DELETE requests on /reviews/:id endpoint
+func remove(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Delete(id, utils.ParseBearerToken(c)); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// reply is the handler function to PUT requests on /reviews/:id/reply endpoint
+func reply(c *gin.Context) {
+	var in app.INReply
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Reply(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// flag is the handler function to POST requests on /reviews/:id/flags endpoint
+func flag(c *gin.Context) {
+	var in app.INFlag
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Flag(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
+
+// moderate is the handler function to PUT requests on /reviews/:id/moderation endpoint
+func moderate(c *gin.Context) {
+	var in app.INModeration
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.Moderate(id, &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
+}
@@ -0,0 +1,25 @@
+package review
+
+import (
+	"go-api/interfaces/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Router(r *gin.RouterGroup) {
+	r.POST("/classes/:id/reviews", add)
+	r.GET("/classes/:id/reviews", listByClass)
+	r.GET("/reviews/:id", get)
+	r.PUT("/reviews/:id", update)
+	r.DELETE("/reviews/:id", remove)
+	r.PUT("/reviews/:id/reply", reply)
+	r.POST("/reviews/:id/flags", flag)
+}
+
+// AdminRouter registers the moderation of reviews, only served to
+// requests carrying the admin token in the X-Admin-Token header
+func AdminRouter(r *gin.RouterGroup, token string) {
+	admin := r.Group("/reviews", middleware.AdminOnly(token))
+	admin.GET("", list)
+	admin.PUT("/:id/moderation", moderate)
+}
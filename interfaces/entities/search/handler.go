@@ -0,0 +1,27 @@
+package search
+
+import (
+	app "go-api/application/entities/search"
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// search is the handler function to GET requests on /search endpoint
+func search(c *gin.Context) {
+	var in app.INSearch
+
+	if err := c.ShouldBindQuery(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Search(&in, utils.ParseLocale(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
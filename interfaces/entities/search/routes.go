@@ -0,0 +1,7 @@
+package search
+
+import "github.com/gin-gonic/gin"
+
+func Router(r *gin.RouterGroup) {
+	r.GET("/search", search)
+}
@@ -1,8 +1,10 @@
 package user
 
 import (
+	classApp "go-api/application/entities/class"
 	app "go-api/application/entities/user"
 	"go-api/oops"
+	"go-api/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,11 +18,132 @@ func add(c *gin.Context) {
 		return
 	}
 
-	id, err := app.Add(&in)
+	out, err := app.Add(&in)
 	if err != nil {
 		oops.Handling(err, c)
 		return
 	}
 
-	c.JSON(201, id)
+	c.JSON(201, out)
+}
+
+// issueToken is the handler function to POST requests on /users/:id/token endpoint
+func issueToken(c *gin.Context) {
+	var in app.INLogin
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.IssueToken(id, &in)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// get is the handler function to GET requests on /users/:id endpoint
+func get(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetProfile(id)
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// listClasses is the handler function to GET requests on /users/:id/classes endpoint
+func listClasses(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	include, err := classApp.ParseInclude(c.Query("include"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.GetClasses(id, include, utils.ParseLocale(c), c.Query("time_zone"), utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(200, out)
+}
+
+// calendar is the handler function to GET requests on /users/:id/calendar.ics endpoint
+func calendar(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.Calendar(id, c.Query("token"))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Data(200, "text/calendar; charset=utf-8", out)
+}
+
+// rotateCalendarToken is the handler function to POST requests on /users/:id/calendar/token endpoint
+func rotateCalendarToken(c *gin.Context) {
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	out, err := app.RotateCalendarToken(id, utils.ParseBearerToken(c))
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.JSON(201, out)
+}
+
+// updateTimeZone is the handler function to PUT requests on /users/:id/time-zone endpoint
+func updateTimeZone(c *gin.Context) {
+	var in app.INTimeZone
+
+	id, err := utils.ParseIDParam(c, "id")
+	if err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	if err := app.UpdateTimeZone(id, utils.ParseBearerToken(c), &in); err != nil {
+		oops.Handling(err, c)
+		return
+	}
+
+	c.Status(204)
 }
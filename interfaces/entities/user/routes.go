@@ -4,4 +4,10 @@ import "github.com/gin-gonic/gin"
 
 func Router(r *gin.RouterGroup) {
 	r.POST("", add)
+	r.GET("/:id", get)
+	r.POST("/:id/token", issueToken)
+	r.GET("/:id/classes", listClasses)
+	r.GET("/:id/calendar.ics", calendar)
+	r.POST("/:id/calendar/token", rotateCalendarToken)
+	r.PUT("/:id/time-zone", updateTimeZone)
 }
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"go-api/oops"
+	"go-api/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly aborts the requests that don't carry the admin token
+// in the X-Admin-Token header
+func AdminOnly(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !utils.SameToken(token, c.GetHeader("X-Admin-Token")) {
+			oops.Handling(oops.Err(&oops.ErrInvalidToken), c)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -2,20 +2,90 @@ package main
 
 import (
 	"fmt"
+	attachmentApp "go-api/application/entities/attachment"
+	classApp "go-api/application/entities/class"
+	enrollmentApp "go-api/application/entities/enrollment"
+	notificationApp "go-api/application/entities/notification"
+	orderApp "go-api/application/entities/order"
+	reminderApp "go-api/application/entities/reminder"
 	"go-api/config"
 	"go-api/database"
+	"go-api/domain/entities/attachment"
+	"go-api/domain/entities/attendance"
+	"go-api/domain/entities/booking"
+	"go-api/domain/entities/category"
 	"go-api/domain/entities/class"
+	"go-api/domain/entities/coupon"
+	"go-api/domain/entities/enrollment"
+	"go-api/domain/entities/notification"
+	"go-api/domain/entities/order"
+	"go-api/domain/entities/reminder"
+	"go-api/domain/entities/review"
 	"go-api/domain/entities/user"
+	"go-api/infrastructure/payment"
+	attachmentPostgres "go-api/infrastructure/persistance/attachment/postgres"
+	attendancePostgres "go-api/infrastructure/persistance/attendance/postgres"
+	bookingPostgres "go-api/infrastructure/persistance/booking/postgres"
+	categoryPostgres "go-api/infrastructure/persistance/category/postgres"
+	classPostgres "go-api/infrastructure/persistance/class/postgres"
+	couponPostgres "go-api/infrastructure/persistance/coupon/postgres"
+	enrollmentPostgres "go-api/infrastructure/persistance/enrollment/postgres"
+	notificationPostgres "go-api/infrastructure/persistance/notification/postgres"
+	orderPostgres "go-api/infrastructure/persistance/order/postgres"
+	reminderPostgres "go-api/infrastructure/persistance/reminder/postgres"
+	reviewPostgres "go-api/infrastructure/persistance/review/postgres"
+	searchPostgres "go-api/infrastructure/persistance/search/postgres"
+	"go-api/infrastructure/storage"
+	attachmentRoutes "go-api/interfaces/entities/attachment"
+	attendanceRoutes "go-api/interfaces/entities/attendance"
+	bookingRoutes "go-api/interfaces/entities/booking"
+	categoryRoutes "go-api/interfaces/entities/category"
+	classRoutes "go-api/interfaces/entities/class"
+	couponRoutes "go-api/interfaces/entities/coupon"
+	enrollmentRoutes "go-api/interfaces/entities/enrollment"
+	notificationRoutes "go-api/interfaces/entities/notification"
+	orderRoutes "go-api/interfaces/entities/order"
+	reminderRoutes "go-api/interfaces/entities/reminder"
+	reviewRoutes "go-api/interfaces/entities/review"
+	searchRoutes "go-api/interfaces/entities/search"
 	userRoutes "go-api/interfaces/entities/user"
+	"go-api/utils"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 var models = []interface{}{
 	user.User{},
+	category.Category{},
 	class.Class{},
+	class.Tag{},
+	class.Recurrence{},
 	class.Schedule{},
+	class.RecurrenceException{},
+	class.ScheduleEvent{},
+	class.StatusEvent{},
+	enrollment.Enrollment{},
+	coupon.Coupon{},
+	coupon.Restriction{},
+	coupon.Redemption{},
+	order.Order{},
+	order.Event{},
+	order.LedgerEntry{},
+	order.Refund{},
+	attendance.Attendance{},
+	attendance.CheckInCode{},
+	attendance.CheckInAttempt{},
+	review.Review{},
+	review.Flag{},
+	notification.Notification{},
+	reminder.Preference{},
+	reminder.Delivery{},
+	booking.Window{},
+	booking.Settings{},
+	booking.Booking{},
+	attachment.Attachment{},
 }
 
 func main() {
@@ -26,6 +96,11 @@ func main() {
 		return
 	}
 
+	if err = utils.RegisterValidations(); err != nil {
+		log.Println("Error when registering validations:", err)
+		return
+	}
+
 	err = database.Open()
 
 	if err != nil {
@@ -38,13 +113,75 @@ func main() {
 	log.Println("Applying migrations...")
 	fmt.Println()
 
+	// schedules must be readable before the models are migrated
+	if err = database.ApplyStatements("schedules backfill", classPostgres.PreMigrations); err != nil {
+		log.Println("Error when backfilling schedules, fix the periods reported and restart")
+		return
+	}
+
 	for _, model := range models {
 		database.ApplyMigrations(model)
 	}
 
+	database.ApplyStatements("classes", classPostgres.Migrations)
+	database.ApplyStatements("enrollments", enrollmentPostgres.Migrations)
+	database.ApplyStatements("coupons", couponPostgres.Migrations)
+	database.ApplyStatements("orders", orderPostgres.Migrations)
+	database.ApplyStatements("attendances", attendancePostgres.Migrations)
+	database.ApplyStatements("reviews", reviewPostgres.Migrations)
+	database.ApplyStatements("search", searchPostgres.Migrations)
+	database.ApplyStatements("notifications", notificationPostgres.Migrations)
+	database.ApplyStatements("reminders", reminderPostgres.Migrations)
+	database.ApplyStatements("bookings", bookingPostgres.Migrations)
+	database.ApplyStatements("attachments", attachmentPostgres.Migrations)
+	database.ApplyStatements("categories", categoryPostgres.Migrations)
+
 	fmt.Println()
 	log.Println("Migrations finished")
 
+	provider, err := payment.New(config.GetConfig().Payment, config.GetConfig().DevMode)
+	if err != nil {
+		log.Println("Error when configuring payment provider:", err)
+		return
+	}
+
+	orderApp.SetProvider(provider)
+
+	if err = orderApp.SetPlatformFee(config.GetConfig().Payment.PlatformFee); err != nil {
+		log.Println("Error when configuring platform fee:", err)
+		return
+	}
+
+	files, err := storage.New(config.GetConfig().Storage)
+	if err != nil {
+		log.Println("Error when configuring file storage:", err)
+		return
+	}
+
+	if err = attachmentApp.Configure(files, config.GetConfig().Storage); err != nil {
+		log.Println("Error when configuring attachments:", err)
+		return
+	}
+
+	// refund students when enrollments or schedules are canceled
+	enrollmentApp.OnCancel(orderApp.RefundEnrollment)
+	classApp.OnScheduleCancel(orderApp.RefundSchedules)
+
+	// tell students of the waitlist when they get a seat
+	enrollmentApp.OnPromote(notificationApp.SeatOpened)
+
+	// keep classes full while every seat is taken
+	enrollmentApp.OnSeatsChange(classApp.SyncSeats)
+
+	// tell students when their schedules are canceled or moved
+	classApp.OnScheduleCancel(notificationApp.SchedulesCanceled)
+	classApp.OnReschedule(notificationApp.Rescheduled)
+
+	go classApp.RunMaterializer(time.Hour)
+	go notificationApp.RunPublisher()
+	go reminderApp.RunScheduler(time.Minute)
+	go orderApp.RunRefunder(time.Minute)
+
 	r := gin.New()
 
 	r.Use(gin.Logger())
@@ -52,6 +189,27 @@ func main() {
 	v1 := r.Group("v1")
 
 	userRoutes.Router(v1.Group("/users"))
+	classRoutes.Router(v1.Group("/classes"))
+	enrollmentRoutes.Router(v1)
+	couponRoutes.Router(v1)
+	orderRoutes.Router(v1)
+	if token := config.GetConfig().AdminToken; token != "" {
+		orderRoutes.AdminRouter(v1, token)
+		reviewRoutes.AdminRouter(v1, token)
+		categoryRoutes.AdminRouter(v1, token)
+		couponRoutes.AdminRouter(v1, token)
+	}
+	if config.GetConfig().DevMode {
+		orderRoutes.DevRouter(v1)
+	}
+	attendanceRoutes.Router(v1)
+	reviewRoutes.Router(v1)
+	searchRoutes.Router(v1)
+	notificationRoutes.Router(v1)
+	reminderRoutes.Router(v1)
+	bookingRoutes.Router(v1)
+	attachmentRoutes.Router(v1)
+	categoryRoutes.Router(v1)
 
 	r.Run()
 }
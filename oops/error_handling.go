@@ -26,10 +26,12 @@ import (
 	"go-api/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx"
+	"github.com/lib/pq"
 	grpcCodes "google.golang.org/grpc/codes"
 	grpcStatus "google.golang.org/grpc/status"
-	"gopkg.in/go-playground/validator.v9"
+	"gorm.io/gorm"
 
 	"github.com/pkg/errors"
 )
@@ -43,15 +45,18 @@ const (
 	grpcCode        = 6000
 	timeParseError  = 7000
 	httpRequestCode = 8000
+	conflictCode    = 9000
+	moneyCode       = 10000
 )
 
 // Error fit a error type for handling
 type Error struct {
-	Msg        string   `json:"msg"`
-	Code       int      `json:"code"`
-	Trace      []string `json:"-"`
-	Err        error    `json:"-"`
-	StatusCode int      `json:"-"`
+	Msg        string      `json:"msg"`
+	Code       int         `json:"code"`
+	Details    interface{} `json:"details,omitempty"`
+	Trace      []string    `json:"-"`
+	Err        error       `json:"-"`
+	StatusCode int         `json:"-"`
 }
 
 // Error enable Error type to implements error type
@@ -75,6 +80,7 @@ type wrappedError interface {
 // fromErr wraps errors to provide user readable messages
 func fromErr(rawError error) error {
 	msg, code, responseStatus := "Erro desconhecido", 0, 400
+	var details interface{}
 	switch err := rawError.(type) {
 	// input errors
 	case *json.UnmarshalTypeError:
@@ -92,26 +98,38 @@ func fromErr(rawError error) error {
 
 	// data errors
 	case pgx.PgError:
-		msg, code = handlePgxError(&err)
+		msg, code = handlePgxError(err.Code)
 		rawError = errors.Errorf("%s: %s", err.Error(), err.Hint)
 
+	case *pq.Error:
+		msg, code = handlePgxError(string(err.Code))
+		if err.Code == "23P01" {
+			responseStatus = http.StatusConflict
+		}
+
 	case *url.Error:
 		msg, code = fmt.Sprintf("Falha no acesso à serviço. Operação: %v", err.Op), internalCode+3
 
 	case *time.ParseError:
 		msg, code = fmt.Sprintf("Impossível converter %v", err.Value), timeParseError+1
 
+	case *utils.NonexistentTimeError:
+		msg, code = fmt.Sprintf("Horário %s não existe no fuso %s por causa do horário de verão", err.Value, err.Zone), timeParseError+2
+
 	case *Error:
 		// this will create a deep copy of the Error struct
-		rawError, msg, code, responseStatus = err, err.Msg, err.Code, err.StatusCode
+		rawError, msg, code, responseStatus, details = err, err.Msg, err.Code, err.StatusCode, err.Details
 
 	case *utils.HTTPError:
 		msg, code = handleHTTPRequestError(err, httpRequestCode)
 
+	case *utils.CurrencyMismatchError:
+		msg, code = fmt.Sprintf("Valores em moedas diferentes não podem ser combinados: %s e %s", err.Left, err.Right), moneyCode+1
+
 	case error:
 		// Default errors
 		switch err {
-		case sql.ErrNoRows:
+		case sql.ErrNoRows, gorm.ErrRecordNotFound:
 			msg, code = "Referência inválida", defaultCode+1
 			responseStatus = http.StatusNotFound
 
@@ -135,6 +153,7 @@ func fromErr(rawError error) error {
 		Msg:        msg,
 		Err:        rawError,
 		Code:       code,
+		Details:    details,
 		StatusCode: responseStatus,
 	}
 }
@@ -167,6 +186,18 @@ func NewErr(message string) error {
 	})
 }
 
+// NewConflict creates an annotated error instance for operations
+// that collide with existing data, exposing the colliding records
+func NewConflict(message string, details interface{}) error {
+	return Err(&Error{
+		Msg:        message,
+		Err:        errors.Errorf("Conflict: '%s'. See the error details for the colliding records.", message),
+		Code:       conflictCode,
+		Details:    details,
+		StatusCode: http.StatusConflict,
+	})
+}
+
 // Handling handles an error by setting a message and a response status code
 func Handling(err error, c *gin.Context) {
 	var e *Error
@@ -254,8 +285,8 @@ func handleHTTPRequestError(err *utils.HTTPError, baseCode int) (string, int) {
 	return fmt.Sprintf("Não foi possível %s. O %s %s, %s.", methodDescription, serviceDescription, statusDescription, userInstruction), baseCode
 }
 
-func handlePgxError(err *pgx.PgError) (string, int) {
-	switch err.Code {
+func handlePgxError(errCode string) (string, int) {
+	switch errCode {
 	case "23505":
 		return "Registro duplicado", pgxCode + 1
 	case "23502":
@@ -270,11 +301,13 @@ func handlePgxError(err *pgx.PgError) (string, int) {
 		return "Dado excede capacidade do registro no banco de dados", pgxCode + 6
 	case "42702":
 		return "Referência ambigua: erro de sintax", pgxCode + 7
+	case "23P01":
+		return "Registro conflita com outro já existente", pgxCode + 8
 	}
 
 	// FIXME: This log should stay here to make easy handle unknown pgx errors.
 	// It should be removed when we have an acceptable number of errors been handled
-	log.Println(err.Code)
+	log.Println(errCode)
 	return "Erro de dados desconhecido", pgxCode
 }
 
@@ -302,6 +335,14 @@ func parseValidationError(err validator.ValidationErrors) (msg string, code int)
 		msg, code = "Campo "+err[0].Field()+" é obrigatório se não for enviado o campo "+err[0].Param(), validationCode+7
 	case "email":
 		msg, code = "Campo "+err[0].Field()+" não contém email válido "+err[0].Param(), validationCode+8
+	case "oneof":
+		msg, code = "Campo "+err[0].Field()+" deve ser um dos valores: "+err[0].Param(), validationCode+13
+	case "alphanum":
+		msg, code = "Campo "+err[0].Field()+" deve conter apenas letras e números", validationCode+14
+	case "iso4217":
+		msg, code = "Campo "+err[0].Field()+" deve ser um código de moeda ISO 4217 válido", validationCode+12
+	case "timezone":
+		msg, code = "Campo "+err[0].Field()+" deve ser um fuso horário IANA válido, como America/Sao_Paulo", validationCode+15
 	case "len":
 		msg, code = "Campo "+err[0].Field()+" deve possuir tamanho igual a "+err[0].Param(), validationCode+9
 	case "min":
@@ -346,6 +387,18 @@ func PassRequired(err error) error {
 	return err
 }
 
+// IsPgCode reports whether the error was caused by a database
+// error with the given SQLSTATE code
+func IsPgCode(err error, code string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && string(pqErr.Code) == code
+}
+
+// IsNotFound reports whether the error was caused by a missing record
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows)
+}
+
 func getErrorLocation(skip int) string {
 	_, file, line, _ := runtime.Caller(skip + 1)
 	return file + ":" + strconv.Itoa(line)
@@ -21,6 +21,24 @@ var (
 		Err:        errors.New("Valor especificado em filtro de rota tem tipo inválido"),
 	}
 
+	// ErrInvalidToken indicates that a secret token
+	// is missing or doesn't match the expected one
+	ErrInvalidToken = Error{
+		Msg:        "Token de acesso inválido",
+		Code:       defaultCode,
+		StatusCode: 403,
+		Err:        errors.New("Token de acesso inválido"),
+	}
+
+	// ErrInvalidSignature indicates that the signature of
+	// a received payload doesn't match its content
+	ErrInvalidSignature = Error{
+		Msg:        "Assinatura inválida",
+		Code:       defaultCode,
+		StatusCode: 401,
+		Err:        errors.New("Assinatura inválida"),
+	}
+
 	// ErrMemcachedConn indicates that was not possible
 	// connect to memcached
 	ErrMemcachedConn = Error{
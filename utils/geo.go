@@ -0,0 +1,19 @@
+package utils
+
+import "math"
+
+// EarthRadiusKm is the mean radius of the Earth used on distances
+const EarthRadiusKm = 6371.0
+
+// Distance computes the great-circle distance in kilometers between two
+// points given in degrees, using the haversine formula
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat, dLng := rad(lat2-lat1), rad(lng2-lng1)
+
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Pow(math.Sin(dLng/2), 2)
+
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(math.Min(1, h)))
+}
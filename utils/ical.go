@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateTimeUTC = "20060102T150405Z"
+	icalLineLimit   = 75
+)
+
+// ICalEvent is a VEVENT rendered into an iCalendar feed. Sequence must
+// grow on every change so calendar applications replace their copy
+type ICalEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time
+	LastModified time.Time
+	Sequence     int64
+	Canceled     bool
+}
+
+// ICalendar renders the events as an RFC 5545 VCALENDAR. Dates are written
+// in UTC so calendar applications convert them to their own time zone
+func ICalendar(name string, events []ICalEvent) []byte {
+	buf := &bytes.Buffer{}
+	stamp := time.Now()
+
+	writeICalLine(buf, "BEGIN:VCALENDAR")
+	writeICalLine(buf, "VERSION:2.0")
+	writeICalLine(buf, "PRODID:-//go-api//classes//PT")
+	writeICalLine(buf, "CALSCALE:GREGORIAN")
+	writeICalLine(buf, "METHOD:PUBLISH")
+	writeICalLine(buf, "X-WR-CALNAME:"+EscapeICalText(name))
+
+	for _, event := range events {
+		status := "CONFIRMED"
+		if event.Canceled {
+			status = "CANCELLED"
+		}
+
+		writeICalLine(buf, "BEGIN:VEVENT")
+		writeICalLine(buf, "UID:"+event.UID)
+		writeICalLine(buf, "DTSTAMP:"+stamp.UTC().Format(icalDateTimeUTC))
+		writeICalLine(buf, "DTSTART:"+event.Start.UTC().Format(icalDateTimeUTC))
+		writeICalLine(buf, "DTEND:"+event.End.UTC().Format(icalDateTimeUTC))
+		writeICalLine(buf, "SEQUENCE:"+strconv.FormatInt(event.Sequence, 10))
+		if !event.LastModified.IsZero() {
+			writeICalLine(buf, "LAST-MODIFIED:"+event.LastModified.UTC().Format(icalDateTimeUTC))
+		}
+		writeICalLine(buf, "SUMMARY:"+EscapeICalText(event.Summary))
+		if event.Description != "" {
+			writeICalLine(buf, "DESCRIPTION:"+EscapeICalText(event.Description))
+		}
+		writeICalLine(buf, "STATUS:"+status)
+		writeICalLine(buf, "END:VEVENT")
+	}
+
+	writeICalLine(buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// EscapeICalText escapes a TEXT property value
+func EscapeICalText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// writeICalLine writes a content line folding it at 75 octets
+// without splitting multi-byte characters
+func writeICalLine(buf *bytes.Buffer, line string) {
+	limit := icalLineLimit
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+
+		// continuation lines start with a space, which counts to the limit
+		limit = icalLineLimit - 1
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,255 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateTimeLocal = "20060102T150405"
+	icalDate          = "20060102"
+)
+
+var icalDurationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ICalVEvent is a VEVENT read from an iCalendar file
+type ICalVEvent struct {
+	UID      string
+	Summary  string
+	Start    time.Time
+	End      time.Time
+	AllDay   bool
+	RRule    string
+	ExDates  []time.Time
+	Canceled bool
+	// RecurrenceID is set on events that override a single
+	// occurrence of the recurring event with the same UID
+	RecurrenceID *time.Time
+}
+
+type icalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// ParseICalendar reads the VEVENTs of an RFC 5545 file. Floating dates, and
+// dates whose TZID is not a known IANA time zone, are read in the given location
+func ParseICalendar(r io.Reader, loc *time.Location) ([]ICalVEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []ICalVEvent
+		current  *ICalVEvent
+		duration *time.Duration
+		// depth of the components nested inside the current VEVENT, like VALARM
+		nested int
+	)
+
+	for n, line := range lines {
+		prop, err := parseICalProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", n+1, err)
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.ToUpper(prop.Value) == "VEVENT" && current == nil:
+			current, duration, nested = &ICalVEvent{}, nil, 0
+			continue
+		case prop.Name == "BEGIN" && current != nil:
+			nested++
+			continue
+		case prop.Name == "END" && current != nil && nested > 0:
+			nested--
+			continue
+		case prop.Name == "END" && strings.ToUpper(prop.Value) == "VEVENT" && current != nil:
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("Line %d: event without DTSTART", n+1)
+			}
+			if current.End.IsZero() {
+				switch {
+				case duration != nil:
+					current.End = current.Start.Add(*duration)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				default:
+					current.End = current.Start
+				}
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		}
+
+		if current == nil || nested > 0 {
+			continue
+		}
+
+		switch prop.Name {
+		case "UID":
+			current.UID = prop.Value
+		case "SUMMARY":
+			current.Summary = unescapeICalText(prop.Value)
+		case "STATUS":
+			current.Canceled = strings.ToUpper(prop.Value) == "CANCELLED"
+		case "RRULE":
+			current.RRule = prop.Value
+		case "DTSTART":
+			current.Start, current.AllDay, err = parseICalTime(prop, loc)
+		case "DTEND":
+			current.End, _, err = parseICalTime(prop, loc)
+		case "DURATION":
+			var d time.Duration
+			if d, err = parseICalDuration(prop.Value); err == nil {
+				duration = &d
+			}
+		case "RECURRENCE-ID":
+			var t time.Time
+			if t, _, err = parseICalTime(prop, loc); err == nil {
+				current.RecurrenceID = &t
+			}
+		case "EXDATE":
+			for _, value := range strings.Split(prop.Value, ",") {
+				var t time.Time
+				if t, _, err = parseICalTime(icalProperty{Name: prop.Name, Params: prop.Params, Value: value}, loc); err != nil {
+					break
+				}
+				current.ExDates = append(current.ExDates, t)
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %v", n+1, err)
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("Unterminated VEVENT")
+	}
+
+	return events, nil
+}
+
+// unfoldICalLines joins the continuation lines, which start with a space or tab
+func unfoldICalLines(r io.Reader) (lines []string, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseICalProperty splits a content line into name, parameters and value
+func parseICalProperty(line string) (prop icalProperty, err error) {
+	quoted, sep := false, -1
+
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			sep = i
+			break
+		}
+	}
+
+	if sep == -1 {
+		return prop, fmt.Errorf("Invalid content line `%s`", line)
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	prop.Name = strings.ToUpper(parts[0])
+	prop.Value = line[sep+1:]
+	prop.Params = map[string]string{}
+
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			prop.Params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return prop, nil
+}
+
+// parseICalTime parses DATE and DATE-TIME values, reporting whether the value is a date
+func parseICalTime(prop icalProperty, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.Value)
+
+	if tzid, ok := prop.Params["TZID"]; ok {
+		if zone, err := time.LoadLocation(tzid); err == nil {
+			loc = zone
+		}
+	}
+
+	switch {
+	case prop.Params["VALUE"] == "DATE" || len(value) == len(icalDate):
+		t, err := time.Parse(icalDate, value)
+		return ShiftWallClock(t, loc), true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(icalDateTimeUTC, value)
+		return t, false, err
+	default:
+		t, err := time.Parse(icalDateTimeLocal, value)
+		return ShiftWallClock(t, loc), false, err
+	}
+}
+
+// parseICalDuration parses a DURATION value such as PT1H30M or P1D
+func parseICalDuration(value string) (time.Duration, error) {
+	match := icalDurationRegex.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("Invalid duration `%s`", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+	}
+
+	if match[1] == "-" {
+		total = -total
+	}
+
+	return total, nil
+}
+
+func unescapeICalText(value string) string {
+	return strings.NewReplacer(
+		`\n`, "\n",
+		`\N`, "\n",
+		`\,`, ",",
+		`\;`, ";",
+		`\\`, `\`,
+	).Replace(value)
+}
@@ -0,0 +1,319 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency assumed for amounts stored
+// before currencies were tracked
+const DefaultCurrency = "BRL"
+
+// DefaultLocale is the locale used to format values when
+// the client doesn't ask for a specific one
+const DefaultLocale = "pt-BR"
+
+// Money is an amount in the minor unit of an ISO 4217 currency,
+// e.g. cents for BRL. Operations never mix different currencies
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// CurrencyMismatchError is returned by operations between amounts
+// of different currencies
+type CurrencyMismatchError struct {
+	Left  string
+	Right string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("currency mismatch: %s and %s", e.Left, e.Right)
+}
+
+// currencyExponents lists the active ISO 4217 currencies with the
+// number of decimals of their minor unit. Precious metals and other
+// codes without a minor unit are left out, as nothing is priced in them
+var currencyExponents = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "ANG": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2,
+	"BAM": 2, "BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2,
+	"BRL": 2, "BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2,
+	"CHW": 2, "CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2,
+	"DJF": 0, "DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2,
+	"GBP": 2, "GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2,
+	"HTG": 2, "HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3,
+	"JPY": 0, "KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2,
+	"LAK": 2, "LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2,
+	"MMK": 2, "MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2,
+	"MZN": 2, "NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2,
+	"PGK": 2, "PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0,
+	"SAR": 2, "SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2,
+	"SSP": 2, "STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2,
+	"TRY": 2, "TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2,
+	"UYW": 4, "UZS": 2, "VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XCG": 2,
+	"XOF": 0, "XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2,
+}
+
+// currencySymbols lists the symbols used when displaying amounts,
+// the currency code is used for the ones not listed here
+var currencySymbols = map[string]string{
+	"BRL": "R$",
+	"USD": "US$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"ARS": "ARS$",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// localeFormat describes how a locale groups digits and
+// where the currency symbol goes
+type localeFormat struct {
+	group   string
+	decimal string
+	suffix  bool
+	spaced  bool
+}
+
+var localeFormats = map[string]localeFormat{
+	"pt": {group: ".", decimal: ",", spaced: true},
+	"en": {group: ",", decimal: "."},
+	"es": {group: ".", decimal: ",", suffix: true, spaced: true},
+	"de": {group: ".", decimal: ",", suffix: true, spaced: true},
+	"it": {group: ".", decimal: ",", suffix: true, spaced: true},
+	"fr": {group: " ", decimal: ",", suffix: true, spaced: true},
+}
+
+// NewMoney builds a money value checking the currency code
+func NewMoney(amount int64, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !IsCurrencyCode(currency) {
+		return Money{}, fmt.Errorf("invalid currency code %q", currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// IsCurrencyCode reports whether the value is an active ISO 4217 code
+func IsCurrencyCode(code string) bool {
+	_, ok := currencyExponents[code]
+	return ok
+}
+
+// CurrencyExponent returns the number of decimals of the currency minor unit
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+func (m Money) check(other Money) error {
+	if m.Currency != other.Currency {
+		return &CurrencyMismatchError{Left: m.Currency, Right: other.Currency}
+	}
+	return nil
+}
+
+// Add sums two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.check(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub subtracts an amount of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.check(other); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Mul multiplies the amount by a quantity
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// Percent returns the given percentage of the amount, rounding
+// half away from zero to the currency minor unit
+func (m Money) Percent(percent int64) Money {
+	amount := m.Amount * percent
+	if amount < 0 {
+		amount -= 50
+	} else {
+		amount += 50
+	}
+	return Money{Amount: amount / 100, Currency: m.Currency}
+}
+
+// Min returns the lowest of two amounts of the same currency
+func (m Money) Min(other Money) (Money, error) {
+	cmp, err := m.Cmp(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp > 0 {
+		return other, nil
+	}
+	return m, nil
+}
+
+// Cmp compares two amounts of the same currency, returning
+// -1, 0 or 1 when m is lower, equal or greater than other
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.check(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// String formats the amount with its currency code, e.g. "15.00 BRL"
+func (m Money) String() string {
+	return m.decimal(".", "") + " " + m.Currency
+}
+
+// Decimal formats the amount in major units without the currency, e.g. "1500.00"
+func (m Money) Decimal() string {
+	return m.decimal(".", "")
+}
+
+// Format formats the amount for display on the given locale, e.g.
+// "R$ 1.500,00" for pt-BR. Unknown locales fall back to the default one
+func (m Money) Format(locale string) string {
+	lang, region := splitLocale(locale)
+
+	format, ok := localeFormats[lang]
+	if !ok {
+		lang, region = splitLocale(DefaultLocale)
+		format = localeFormats[lang]
+	}
+
+	symbol, ok := currencySymbols[m.Currency]
+	if !ok {
+		symbol = m.Currency
+	}
+	if m.Currency == "USD" && lang == "en" && (region == "" || region == "US") {
+		symbol = "$"
+	}
+
+	number := m.decimal(format.decimal, format.group)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+
+	space := ""
+	if format.spaced {
+		space = " "
+	}
+
+	if format.suffix {
+		return sign + number + space + symbol
+	}
+	return sign + symbol + space + number
+}
+
+// decimal writes the amount in major units with the given separators
+func (m Money) decimal(decimal, group string) string {
+	exp := CurrencyExponent(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(absAmount(amount), 10)
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+
+	major, minor := digits[:len(digits)-exp], digits[len(digits)-exp:]
+
+	if group != "" {
+		var b strings.Builder
+		for i, r := range major {
+			if i > 0 && (len(major)-i)%3 == 0 {
+				b.WriteString(group)
+			}
+			b.WriteRune(r)
+		}
+		major = b.String()
+	}
+
+	if exp == 0 {
+		return sign + major
+	}
+	return sign + major + decimal + minor
+}
+
+func absAmount(amount int64) uint64 {
+	if amount < 0 {
+		return uint64(-(amount + 1)) + 1
+	}
+	return uint64(amount)
+}
+
+// splitLocale splits a BCP 47 tag like "pt-BR" into
+// its lowercase language and uppercase region
+func splitLocale(locale string) (lang, region string) {
+	parts := strings.FieldsFunc(locale, func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) > 0 {
+		lang = strings.ToLower(parts[0])
+	}
+	if len(parts) > 1 {
+		region = strings.ToUpper(parts[1])
+	}
+	return lang, region
+}
+
+// ParseAcceptLanguage picks the preferred locale of an Accept-Language
+// header among the ones the API can format, or the default locale
+func ParseAcceptLanguage(header string) string {
+	best, bestQ := DefaultLocale, -1.0
+
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+		tag := strings.TrimSpace(parts[0])
+
+		lang, _ := splitLocale(tag)
+		if _, ok := localeFormats[lang]; !ok {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerPrefix is the scheme of the Authorization header carrying user tokens
+const bearerPrefix = "Bearer "
+
+// ParseIDParam reads a numeric identifier from the route parameters
+func ParseIDParam(c *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// ParseLocale reads the locale preferred by the client from the Accept-Language header
+func ParseLocale(c *gin.Context) string {
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// ParseBearerToken reads the secret token of the user from the Authorization
+// header, so it never shows up in URLs and in the access log
+func ParseBearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	return ""
+}
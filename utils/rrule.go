@@ -0,0 +1,512 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRRulePeriods bounds the number of periods walked when expanding a
+	// rule, protecting against rules that never produce an occurrence
+	maxRRulePeriods = 50000
+	rruleUntilUTC   = "20060102T150405Z"
+	rruleUntilLocal = "20060102T150405"
+	rruleUntilDate  = "20060102"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RRuleWeekday is a BYDAY entry, optionally prefixed by
+// an ordinal such as 2MO (second monday) or -1FR (last friday)
+type RRuleWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RRule is an iCalendar recurrence rule (RFC 5545, section 3.3.10).
+// Only the DAILY, WEEKLY, MONTHLY and YEARLY frequencies are supported,
+// along with the INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH parts.
+// Weeks always start on monday
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// ParseRRule parses a recurrence rule such as FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10.
+// Floating and date only UNTIL values are interpreted in the given location,
+// the later being inclusive for the whole day
+func ParseRRule(value string, loc *time.Location) (*RRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	rule := &RRule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid rule part `%s`", part)
+		}
+
+		key, val := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		switch key {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = val
+			default:
+				return nil, fmt.Errorf("Frequency `%s` not supported", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Invalid interval `%s`", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Invalid count `%s`", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				wd, err := parseRRuleWeekday(day)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("Invalid month day `%s`", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(month)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("Invalid month `%s`", month)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			if val != "MO" {
+				return nil, errors.New("Only weeks starting on monday are supported")
+			}
+		default:
+			return nil, fmt.Errorf("Rule part `%s` not supported", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("Rule frequency is required")
+	}
+
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("Rule cannot define both COUNT and UNTIL")
+	}
+
+	if rule.Freq == "DAILY" || rule.Freq == "WEEKLY" {
+		for _, wd := range rule.ByDay {
+			if wd.N != 0 {
+				return nil, fmt.Errorf("Ordinal weekdays are not allowed with %s frequency", rule.Freq)
+			}
+		}
+	}
+
+	if rule.Freq == "WEEKLY" && len(rule.ByMonthDay) > 0 {
+		return nil, errors.New("BYMONTHDAY is not allowed with WEEKLY frequency")
+	}
+
+	return rule, nil
+}
+
+func parseRRuleUntil(value string, loc *time.Location) (*time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+
+	switch len(value) {
+	case len(rruleUntilUTC):
+		t, err = time.Parse(rruleUntilUTC, value)
+	case len(rruleUntilLocal):
+		t, err = time.ParseInLocation(rruleUntilLocal, value, loc)
+	case len(rruleUntilDate):
+		t, err = time.ParseInLocation(rruleUntilDate, value, loc)
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	default:
+		err = fmt.Errorf("Invalid until `%s`", value)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func parseRRuleWeekday(value string) (wd RRuleWeekday, err error) {
+	if len(value) < 2 {
+		return wd, fmt.Errorf("Invalid weekday `%s`", value)
+	}
+
+	day, ok := rruleWeekdays[value[len(value)-2:]]
+	if !ok {
+		return wd, fmt.Errorf("Invalid weekday `%s`", value)
+	}
+
+	wd.Weekday = day
+
+	if prefix := value[:len(value)-2]; prefix != "" {
+		if wd.N, err = strconv.Atoi(prefix); err != nil || wd.N == 0 || wd.N < -53 || wd.N > 53 {
+			return wd, fmt.Errorf("Invalid weekday `%s`", value)
+		}
+	}
+
+	return wd, nil
+}
+
+// String formats the rule back into its iCalendar representation
+func (r *RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleUntilUTC))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// String formats the weekday as a BYDAY entry
+func (wd RRuleWeekday) String() string {
+	for code, day := range rruleWeekdays {
+		if day == wd.Weekday {
+			if wd.N != 0 {
+				return strconv.Itoa(wd.N) + code
+			}
+			return code
+		}
+	}
+	return ""
+}
+
+// Between expands the rule from dtstart, returning the occurrences starting
+// inside [from, to). COUNT is always accounted from dtstart, so occurrences
+// before from still consume it. Every occurrence keeps the time of day and
+// location of dtstart
+func (r *RRule) Between(dtstart, from, to time.Time) []time.Time {
+	var (
+		out   []time.Time
+		count int
+	)
+
+	for period := 0; period < maxRRulePeriods; period++ {
+		candidates := r.candidates(dtstart, period)
+
+		if len(candidates) == 0 && r.periodStart(dtstart, period).After(to) {
+			break
+		}
+
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+
+			if r.Until != nil && t.After(*r.Until) {
+				return out
+			}
+
+			if !t.Before(to) {
+				return out
+			}
+
+			count++
+
+			if !t.Before(from) {
+				out = append(out, t)
+			}
+
+			if r.Count > 0 && count >= r.Count {
+				return out
+			}
+		}
+	}
+
+	return out
+}
+
+// CountBefore returns how many occurrences start before the given instant
+func (r *RRule) CountBefore(dtstart, instant time.Time) int {
+	return len(r.Between(dtstart, dtstart, instant))
+}
+
+// periodStart returns the first day of the nth period after dtstart
+func (r *RRule) periodStart(dtstart time.Time, n int) time.Time {
+	y, m, d := dtstart.Date()
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+
+	switch r.Freq {
+	case "DAILY":
+		return time.Date(y, m, d+n*r.Interval, h, mi, s, 0, loc)
+	case "WEEKLY":
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset+7*n*r.Interval, h, mi, s, 0, loc)
+	case "MONTHLY":
+		return time.Date(y, m+time.Month(n*r.Interval), 1, h, mi, s, 0, loc)
+	default:
+		return time.Date(y+n*r.Interval, time.January, 1, h, mi, s, 0, loc)
+	}
+}
+
+// candidates returns the sorted occurrences of the nth period
+func (r *RRule) candidates(dtstart time.Time, n int) (out []time.Time) {
+	start := r.periodStart(dtstart, n)
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+
+	switch r.Freq {
+	case "DAILY":
+		if r.matchesDay(start) {
+			out = append(out, start)
+		}
+	case "WEEKLY":
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []RRuleWeekday{{Weekday: dtstart.Weekday()}}
+		}
+		for _, wd := range days {
+			offset := (int(wd.Weekday) + 6) % 7
+			t := time.Date(start.Year(), start.Month(), start.Day()+offset, h, mi, s, 0, loc)
+			if r.matchesMonth(t) {
+				out = append(out, t)
+			}
+		}
+	case "MONTHLY":
+		if r.matchesMonth(start) {
+			out = r.monthCandidates(dtstart, start.Year(), start.Month())
+		}
+	default:
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				out = append(out, r.monthCandidates(dtstart, start.Year(), month)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			// without BYMONTH the month days repeat on every month of the year
+			for month := time.January; month <= time.December; month++ {
+				out = append(out, r.monthCandidates(dtstart, start.Year(), month)...)
+			}
+		case len(r.ByDay) > 0:
+			out = r.yearCandidates(dtstart, start.Year())
+		default:
+			out = r.monthCandidates(dtstart, start.Year(), dtstart.Month())
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Before(out[j])
+	})
+
+	return dedupTimes(out)
+}
+
+// monthCandidates returns the occurrences inside a given month
+func (r *RRule) monthCandidates(dtstart time.Time, year int, month time.Month) (out []time.Time) {
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+	date := func(day int) time.Time {
+		return time.Date(year, month, day, h, mi, s, 0, loc)
+	}
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			if day < 1 || day > last {
+				continue
+			}
+			if t := date(day); r.matchesWeekday(t) {
+				out = append(out, t)
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			var days []int
+			for day := 1; day <= last; day++ {
+				if date(day).Weekday() == wd.Weekday {
+					days = append(days, day)
+				}
+			}
+
+			for _, day := range nthDays(days, wd.N) {
+				out = append(out, date(day))
+			}
+		}
+	default:
+		if day := dtstart.Day(); day <= last {
+			out = append(out, date(day))
+		}
+	}
+
+	return out
+}
+
+// yearCandidates returns the occurrences of BYDAY inside a whole year,
+// where ordinals count the weekdays of the year instead of the month
+func (r *RRule) yearCandidates(dtstart time.Time, year int) (out []time.Time) {
+	h, mi, s := dtstart.Clock()
+	loc := dtstart.Location()
+	last := time.Date(year, time.December, 31, 0, 0, 0, 0, loc).YearDay()
+
+	date := func(day int) time.Time {
+		return time.Date(year, time.January, day, h, mi, s, 0, loc)
+	}
+
+	for _, wd := range r.ByDay {
+		var days []int
+		for day := 1; day <= last; day++ {
+			if date(day).Weekday() == wd.Weekday {
+				days = append(days, day)
+			}
+		}
+
+		for _, day := range nthDays(days, wd.N) {
+			out = append(out, date(day))
+		}
+	}
+
+	return out
+}
+
+// nthDays picks the nth of the days, counting from the end when n is
+// negative, or all of them when n is zero
+func nthDays(days []int, n int) []int {
+	switch {
+	case n == 0:
+		return days
+	case n > 0 && n <= len(days):
+		return days[n-1 : n]
+	case n < 0 && -n <= len(days):
+		return days[len(days)+n : len(days)+n+1]
+	}
+	return nil
+}
+
+func (r *RRule) matchesDay(t time.Time) bool {
+	if !r.matchesMonth(t) || !r.matchesWeekday(t) {
+		return false
+	}
+
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+
+	last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, day := range r.ByMonthDay {
+		if day == t.Day() || last+day+1 == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonth(t time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if month == t.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupTimes(in []time.Time) []time.Time {
+	out := in[:0]
+	for i, t := range in {
+		if i == 0 || !t.Equal(in[i-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRRuleBetween(t *testing.T) {
+	monday := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	far := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		from    time.Time
+		want    []string
+	}{
+		{"daily", "FREQ=DAILY;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-01-02", "2024-01-03"}},
+		{"daily interval", "FREQ=DAILY;INTERVAL=2;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-01-03", "2024-01-05"}},
+		{"daily byday", "FREQ=DAILY;BYDAY=SA,SU;COUNT=3", monday, monday,
+			[]string{"2024-01-06", "2024-01-07", "2024-01-13"}},
+		{"daily bymonth", "FREQ=DAILY;BYMONTH=2;COUNT=2", monday, monday,
+			[]string{"2024-02-01", "2024-02-02"}},
+		{"daily bymonthday", "FREQ=DAILY;BYMONTHDAY=1,-1;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-01-31", "2024-02-01"}},
+		{"daily until date", "FREQ=DAILY;UNTIL=20240103", monday, monday,
+			[]string{"2024-01-01", "2024-01-02", "2024-01-03"}},
+		{"daily count before from", "FREQ=DAILY;COUNT=5", monday,
+			time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC),
+			[]string{"2024-01-03", "2024-01-04", "2024-01-05"}},
+		{"weekly", "FREQ=WEEKLY;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-01-08", "2024-01-15"}},
+		{"weekly byday", "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", monday, monday,
+			[]string{"2024-01-01", "2024-01-03", "2024-01-08", "2024-01-10"}},
+		{"weekly interval", "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR;COUNT=3", monday, monday,
+			[]string{"2024-01-05", "2024-01-19", "2024-02-02"}},
+		{"weekly bymonth", "FREQ=WEEKLY;BYMONTH=2;BYDAY=TH;COUNT=2", monday, monday,
+			[]string{"2024-02-01", "2024-02-08"}},
+		{"monthly", "FREQ=MONTHLY;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-02-01", "2024-03-01"}},
+		{"monthly skips short months", "FREQ=MONTHLY;COUNT=4",
+			time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC), monday,
+			[]string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"}},
+		{"monthly last day", "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3", monday, monday,
+			[]string{"2024-01-31", "2024-02-29", "2024-03-31"}},
+		{"monthly ordinal weekday", "FREQ=MONTHLY;BYDAY=2MO;COUNT=3", monday, monday,
+			[]string{"2024-01-08", "2024-02-12", "2024-03-11"}},
+		{"monthly last weekday", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", monday, monday,
+			[]string{"2024-01-26", "2024-02-23", "2024-03-29"}},
+		{"monthly missing ordinal", "FREQ=MONTHLY;BYDAY=5MO;COUNT=2", monday, monday,
+			[]string{"2024-01-29", "2024-04-29"}},
+		{"monthly bymonthday and byday", "FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR;COUNT=2", monday, monday,
+			[]string{"2024-09-13", "2024-12-13"}},
+		{"monthly bymonth", "FREQ=MONTHLY;BYMONTH=1,7;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-07-01", "2025-01-01"}},
+		{"yearly", "FREQ=YEARLY;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2025-01-01", "2026-01-01"}},
+		{"yearly leap day", "FREQ=YEARLY;COUNT=2",
+			time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), monday,
+			[]string{"2024-02-29", "2028-02-29"}},
+		{"yearly bymonth", "FREQ=YEARLY;BYMONTH=3,6;COUNT=3", monday, monday,
+			[]string{"2024-03-01", "2024-06-01", "2025-03-01"}},
+		{"yearly bymonthday", "FREQ=YEARLY;BYMONTHDAY=15;COUNT=3", monday, monday,
+			[]string{"2024-01-15", "2024-02-15", "2024-03-15"}},
+		{"yearly bymonthday and bymonth", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1;COUNT=2", monday, monday,
+			[]string{"2024-02-29", "2025-02-28"}},
+		{"yearly bymonthday and byday", "FREQ=YEARLY;BYMONTHDAY=1;BYDAY=MO;COUNT=3", monday, monday,
+			[]string{"2024-01-01", "2024-04-01", "2024-07-01"}},
+		{"yearly byday", "FREQ=YEARLY;BYDAY=TU;COUNT=3", monday, monday,
+			[]string{"2024-01-02", "2024-01-09", "2024-01-16"}},
+		{"yearly ordinal weekday", "FREQ=YEARLY;BYDAY=20MO;COUNT=2", monday, monday,
+			[]string{"2024-05-13", "2025-05-19"}},
+		{"yearly last weekday", "FREQ=YEARLY;BYDAY=-1SU;COUNT=2", monday, monday,
+			[]string{"2024-12-29", "2025-12-28"}},
+		{"yearly byday and bymonth", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2", monday, monday,
+			[]string{"2024-11-28", "2025-11-27"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) returned %v", tt.rule, err)
+			}
+
+			var got []string
+			for _, occurrence := range rule.Between(tt.dtstart, tt.from, far) {
+				if h, m, _ := occurrence.Clock(); h != 9 || m != 0 {
+					t.Errorf("occurrence %v doesn't keep the time of dtstart", occurrence)
+				}
+				got = append(got, occurrence.Format("2006-01-02"))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleBetweenKeepsWallClockAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	dtstart := time.Date(2024, time.March, 4, 9, 0, 0, 0, loc)
+
+	rule, err := ParseRRule("FREQ=WEEKLY;COUNT=2", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rule.Between(dtstart, dtstart, dtstart.AddDate(0, 1, 0))
+	if len(got) != 2 {
+		t.Fatalf("Between() returned %d occurrences, want 2", len(got))
+	}
+
+	if h, _, _ := got[1].Clock(); h != 9 || got[1].Day() != 11 {
+		t.Errorf("second occurrence = %v, want March 11 at 09:00", got[1])
+	}
+
+	if got[1].Sub(got[0]) != 7*24*time.Hour-time.Hour {
+		t.Errorf("occurrences are %v apart, want one hour less than a week", got[1].Sub(got[0]))
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	rules := []string{
+		"",
+		"COUNT=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=DAILY;WKST=SU",
+		"FREQ=DAILY;BYSETPOS=1",
+	}
+
+	for _, rule := range rules {
+		if _, err := ParseRRule(rule, time.UTC); err == nil {
+			t.Errorf("ParseRRule(%q) accepted an invalid rule", rule)
+		}
+	}
+}
+
+func TestRRuleString(t *testing.T) {
+	rules := []string{
+		"FREQ=DAILY",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+		"FREQ=MONTHLY;COUNT=5;BYDAY=-1FR",
+		"FREQ=YEARLY;UNTIL=20251231T120000Z;BYMONTHDAY=1,15;BYMONTH=3",
+	}
+
+	for _, value := range rules {
+		rule, err := ParseRRule(value, time.UTC)
+		if err != nil {
+			t.Fatalf("ParseRRule(%q) returned %v", value, err)
+		}
+		if got := rule.String(); got != value {
+			t.Errorf("String() = %q, want %q", got, value)
+		}
+	}
+}
@@ -119,37 +119,75 @@ func convert(value interface{}, from, to string) (output interface{}, err error)
 	return nil, errors.New("Feature not supported")
 }
 
-// ParseDateTime attempts to parse exotic string formats into time objects
+// DefaultLocation returns the location assumed for dates without timezone
+func DefaultLocation() (*time.Location, error) {
+	return LoadTimeZone(DefaultTimeZone)
+}
+
+// ParseDateTime attempts to parse exotic string formats into time objects,
+// assuming the default location for dates without timezone
 func ParseDateTime(value string) (*time.Time, error) {
-	// assume Brazil's eastern timezone when converting raw dates without timezone
-	fortaleza, err := time.LoadLocation("America/Fortaleza")
+	loc, err := DefaultLocation()
 	if err != nil {
 		return nil, err
 	}
 
+	return ParseDateTimeIn(value, loc)
+}
+
+// ParseDateTimeIn attempts to parse exotic string formats into time objects,
+// reading the dates without timezone as wall clock times of the location
+func ParseDateTimeIn(value string, loc *time.Location) (*time.Time, error) {
 	// https://golang.org/pkg/time/#pkg-constants
 	// Mon Jan 2 15:04:05 MST 2006
 	layout := `02/01/2006 15:04:05`
+	zoned, dateOnly := false, false
 
 	switch {
+	case regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|(-|\+)\d{2}:\d{2})$`).Match([]byte(value)):
+		// ISO 8601 with timezone, the location is given by the string itself
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return &t, err
+	case regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?$`).Match([]byte(value)):
+		// ISO 8601 without timezone
+		layout = "2006-01-02T15:04:05"
+	case regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}$`).Match([]byte(value)):
+		// ISO 8601 without seconds nor timezone
+		layout = "2006-01-02T15:04"
+	case regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`).Match([]byte(value)):
+		// ISO 8601 date only
+		layout, dateOnly = "2006-01-02", true
 	case regexp.MustCompile(`^(\d{0,2})\/(\d{0,2})\/(\d{0,4})\s(\d{0,2}):(\d{0,2}):(\d{0,2})\.?\d{0,} (-|\+)(\d{2}):(\d{2})$`).Match([]byte(value)):
 		// the string contains a timezone indicator with hour and minutes separator
-		layout += " -07:00"
+		layout, zoned = layout+" -07:00", true
 	case regexp.MustCompile(`^(\d{0,2})\/(\d{0,2})\/(\d{0,4})\s(\d{0,2}):(\d{0,2}):(\d{0,2})\.?\d{0,} (-|\+)(\d{2})(\d{2})$`).Match([]byte(value)):
 		// the string contains a timezone indicator
-		layout += " -0700"
+		layout, zoned = layout+" -0700", true
 	case regexp.MustCompile(`^(\d{0,2})\/(\d{0,2})\/(\d{0,4})\s(\d{0,2}):(\d{0,2}):(\d{0,2})\.?\d{0,} (-|\+)(\d{2})$`).Match([]byte(value)):
 		// the string contains a half-assed timezone indicator
-		layout += " -07"
+		layout, zoned = layout+" -07", true
 	case regexp.MustCompile(`^(\d{0,2})\/(\d{0,2})\/(\d{0,4})\s(\d{0,2}):(\d{0,2}):(\d{0,2})\.?\d{0,}$`).Match([]byte(value)):
 		// the string matches the whole format no changes are necessary
 	case regexp.MustCompile(`^(\d{0,2})\/(\d{0,2})\/(\d{0,4})$`).Match([]byte(value)):
 		// the string doesn't contain time information so we append to it
-		value += " 00:00:00"
+		value, dateOnly = value+" 00:00:00", true
 	default:
 		return nil, fmt.Errorf("No match for date format `%s`", value)
 	}
 
-	t, err := time.ParseInLocation(layout, value, fortaleza)
-	return &t, err
+	t, err := time.Parse(layout, value)
+	if err != nil || zoned {
+		return &t, err
+	}
+
+	// a day whose midnight was skipped starts right after the transition,
+	// which is midnight read with the offset in use before it
+	if dateOnly {
+		local := ShiftWallClock(t, loc)
+		return &local, nil
+	}
+
+	// without timezone the parsed value is only a wall clock time
+	local, err := WallClock(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	return &local, err
 }
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTimeZone is the IANA time zone assumed for classes and dates
+// without one, which is how dates were read before zones were tracked
+const DefaultTimeZone = "America/Fortaleza"
+
+// NonexistentTimeError is returned for wall clock times skipped
+// by a daylight saving time transition of the zone
+type NonexistentTimeError struct {
+	Value string
+	Zone  string
+}
+
+func (e *NonexistentTimeError) Error() string {
+	return fmt.Sprintf("%s does not exist in %s due to a daylight saving time transition", e.Value, e.Zone)
+}
+
+// LoadTimeZone loads an IANA time zone, the default one when the name is empty
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+
+	// the machine zone would make the results depend on the server
+	if name == "Local" {
+		return nil, errors.New("unknown time zone Local")
+	}
+
+	return time.LoadLocation(name)
+}
+
+// IsTimeZone reports whether the value is a known IANA time zone
+func IsTimeZone(name string) bool {
+	if name == "" {
+		return false
+	}
+	_, err := LoadTimeZone(name)
+	return err == nil
+}
+
+// WallClock returns the instant a wall clock time happens in a zone. Times
+// skipped when clocks move forward are rejected and times repeated when
+// clocks move backward resolve to their first occurrence
+func WallClock(year int, month time.Month, day, hour, min, sec, nsec int, loc *time.Location) (time.Time, error) {
+	t := time.Date(year, month, day, hour, min, sec, nsec, loc)
+
+	if t.Year() != year || t.Month() != month || t.Day() != day || t.Hour() != hour || t.Minute() != min {
+		wall := time.Date(year, month, day, hour, min, sec, nsec, time.UTC)
+		return time.Time{}, &NonexistentTimeError{Value: wall.Format("2006-01-02T15:04:05"), Zone: loc.String()}
+	}
+
+	// when the offset a day before is greater, the same wall clock
+	// time may have happened before the clocks were set back
+	_, offset := t.Zone()
+	if _, before := t.Add(-24 * time.Hour).Zone(); before > offset {
+		earlier := t.Add(-time.Duration(before-offset) * time.Second)
+		if earlier.Hour() == hour && earlier.Minute() == min && earlier.Day() == day {
+			return earlier, nil
+		}
+	}
+
+	return t, nil
+}
+
+// ShiftWallClock returns the instant the wall clock time of t happens in a
+// zone like WallClock, except that times skipped when clocks move forward
+// are read with the offset in use before the transition, as RFC 5545 does
+func ShiftWallClock(t time.Time, loc *time.Location) time.Time {
+	local, err := WallClock(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	if err == nil {
+		return local
+	}
+
+	_, before := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(-24 * time.Hour).Zone()
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+
+	return wall.Add(-time.Duration(before) * time.Second).In(loc)
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// RandomToken generates a random hex encoded token with the given number of bytes
+func RandomToken(size int) (string, error) {
+	raw := make([]byte, size)
+
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+// SameToken compares two tokens in constant time
+func SameToken(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// SignPayload computes the hex encoded HMAC-SHA256 of a payload
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RandomDigits generates a random numeric code with the given number of digits
+func RandomDigits(size int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(size)), nil)
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", size, n), nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// validations are the custom tags available on the binding tags of the input models
+var validations = map[string]validator.Func{
+	"iso4217": func(fl validator.FieldLevel) bool {
+		return IsCurrencyCode(fl.Field().String())
+	},
+	"timezone": func(fl validator.FieldLevel) bool {
+		return IsTimeZone(fl.Field().String())
+	},
+}
+
+// RegisterValidations adds the custom validation tags used
+// on the binding tags of the input models
+func RegisterValidations() error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected validator engine")
+	}
+
+	for tag, fn := range validations {
+		if err := engine.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}